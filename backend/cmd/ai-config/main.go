@@ -130,6 +130,8 @@ func cmdShow() {
 	fmt.Printf("Cache Enabled:        %v\n", aiCfg.CacheEnabled)
 	fmt.Printf("Cache TTL:            %dm\n", aiCfg.CacheTTLMinutes)
 	fmt.Printf("Max Requests/Min:     %d\n", aiCfg.MaxRequestsPerMinute)
+	fmt.Printf("Player Requests/Min:  %d\n", aiCfg.PlayerRequestsPerMinute)
+	fmt.Printf("Max Queue:            %ds\n", aiCfg.MaxQueueSeconds)
 	fmt.Printf("Fallback Enabled:     %v\n", aiCfg.FallbackEnabled)
 
 	if aiCfg.APIKey != "" {
@@ -139,6 +141,13 @@ func cmdShow() {
 		fmt.Printf("API Key:              (not set)\n")
 	}
 
+	fmt.Println("\nProvider chain (in order, then fallback):")
+	for i, p := range aiCfg.Chain() {
+		fmt.Printf("  %d. %-10s %-9s %s @ %s (timeout %v, %d/min, key: %v, ready: %v)\n",
+			i+1, p.Name, p.Type, p.Model, p.URL, p.GetTimeout(aiCfg.GetTimeout()),
+			p.GetRequestsPerMinute(aiCfg.MaxRequestsPerMinute), p.APIKey != "", p.IsReady())
+	}
+
 	fmt.Printf("\nReady: %v\n", aiCfg.IsReady())
 }
 
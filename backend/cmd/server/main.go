@@ -15,15 +15,23 @@ import (
 
 	"github.com/alonsoalpizar/calleviva/backend/internal/ai/handlers"
 	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/bankruptcy"
 	"github.com/alonsoalpizar/calleviva/backend/internal/config"
 	"github.com/alonsoalpizar/calleviva/backend/internal/creator"
 	"github.com/alonsoalpizar/calleviva/backend/internal/database"
 	"github.com/alonsoalpizar/calleviva/backend/internal/games"
 	"github.com/alonsoalpizar/calleviva/backend/internal/lab"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ledger"
+	"github.com/alonsoalpizar/calleviva/backend/internal/location"
 	"github.com/alonsoalpizar/calleviva/backend/internal/market"
+	"github.com/alonsoalpizar/calleviva/backend/internal/menu"
 	"github.com/alonsoalpizar/calleviva/backend/internal/parameters"
 	"github.com/alonsoalpizar/calleviva/backend/internal/players"
 	"github.com/alonsoalpizar/calleviva/backend/internal/scenarios"
+	"github.com/alonsoalpizar/calleviva/backend/internal/simulation"
+	"github.com/alonsoalpizar/calleviva/backend/internal/trucks"
+	"github.com/alonsoalpizar/calleviva/backend/internal/upgrades"
+	"github.com/alonsoalpizar/calleviva/backend/internal/worlds"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -64,13 +72,21 @@ func main() {
 	// CORS
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{cfg.FrontendURL, "https://calleviva.club"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Request-ID"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Request-ID", "If-None-Match"},
+		ExposedHeaders:   []string{"Link", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
+	// IA: el laboratorio la usa y el admin ve sus métricas
+	labHandler := lab.NewHandler(database.GetPool())
+	if err := labHandler.InitAI(context.Background()); err != nil {
+		log.Printf("Warning: Lab AI init failed: %v", err)
+	}
+	aiHandler := handlers.NewAIAdminHandler()
+	aiHandler.SetService(labHandler.AIService())
+
 	// Rutas
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -109,18 +125,35 @@ func main() {
 				marketHandler.SetupRoutes(r)
 
 				// Laboratorio de Sabores
-				labHandler := lab.NewHandler(database.GetPool())
-				if err := labHandler.InitAI(context.Background()); err != nil {
-					log.Printf("Warning: Lab AI init failed: %v", err)
-				}
 				labHandler.SetupRoutes(r)
 
-				// Gameplay (futuro)
-				r.Get("/day", handleNotImplemented)
-				r.Post("/location/set", handleNotImplemented)
-				r.Post("/menu/configure", handleNotImplemented)
-				r.Post("/day/start", handleNotImplemented)
-				r.Get("/day/results", handleNotImplemented)
+				// Simulación del día
+				simulationHandler := simulation.NewHandler(database.GetPool())
+				simulationHandler.SetupRoutes(r)
+
+				// Ubicación del día
+				locationHandler := location.NewHandler(database.GetPool())
+				locationHandler.SetupRoutes(r)
+
+				// Menú del truck
+				menuHandler := menu.NewHandler(database.GetPool())
+				menuHandler.SetupRoutes(r)
+
+				// Quiebra: préstamo, reiniciar día o nueva partida
+				bankruptcyHandler := bankruptcy.NewHandler(database.GetPool())
+				bankruptcyHandler.SetupRoutes(r)
+
+				// Tienda de mejoras del truck
+				upgradesHandler := upgrades.NewHandler(database.GetPool())
+				upgradesHandler.SetupRoutes(r)
+
+				// Libro de movimientos de dinero
+				ledgerHandler := ledger.NewHandler(database.GetPool())
+				ledgerHandler.SetupRoutes(r)
+
+				// Vehículo: nombre y escalera carrito → puesto → food truck → restaurante
+				trucksHandler := trucks.NewHandler(database.GetPool())
+				trucksHandler.SetupRoutes(r)
 			})
 		})
 
@@ -131,12 +164,9 @@ func main() {
 			r.Get("/{id}", parameters.HandleGet)
 		})
 
-		// Worlds (público - datos estáticos)
-		r.Route("/worlds", func(r chi.Router) {
-			r.Get("/{worldType}/products", handleNotImplemented)
-			r.Get("/{worldType}/locations", handleNotImplemented)
-			r.Get("/{worldType}/events", handleNotImplemented)
-		})
+		// Worlds (público - catálogos por país, con ETag)
+		worldsHandler := worlds.NewHandler(database.GetPool())
+		worldsHandler.SetupRoutes(r)
 
 		// Scenarios (público - escenarios 3D)
 		r.Route("/scenarios", func(r chi.Router) {
@@ -177,12 +207,12 @@ func main() {
 			})
 
 			// AI Configuration
-			aiHandler := handlers.NewAIAdminHandler()
 			r.Route("/ai", func(r chi.Router) {
 				r.Get("/config", aiHandler.HandleGetConfig)
 				r.Patch("/config", aiHandler.HandleUpdateConfig)
 				r.Post("/apikey", aiHandler.HandleSetAPIKey)
 				r.Post("/test", aiHandler.HandleTest)
+				r.Get("/cache", aiHandler.HandleCacheStats)
 			})
 
 			// Creator Admin Routes
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Key identifies a cached response: the prompt's hash within its template
+type Key struct {
+	PromptType string // template name, or "raw" for ad-hoc prompts
+	Hash       string
+}
+
+// Entry is a cached response
+type Entry struct {
+	Value   string
+	Tokens  int // what generating it cost; every hit saves that much
+	Expires time.Time
+}
+
+// Cache stores generated responses
+type Cache interface {
+	// Get returns the live entry for key
+	Get(ctx context.Context, key Key) (Entry, bool)
+
+	// Set stores value for ttl
+	Set(ctx context.Context, key Key, value string, tokens int, ttl time.Duration) error
+
+	// Clean removes expired entries
+	Clean(ctx context.Context) error
+}
+
+// GenerateKey creates a cache key hash from prompt components
+func GenerateKey(systemPrompt, userPrompt string) string {
+	hash := sha256.Sum256([]byte(systemPrompt + "||" + userPrompt))
+	return hex.EncodeToString(hash[:])
+}
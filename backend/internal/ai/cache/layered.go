@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HitCounter is a cache that keeps hit counts of its own
+type HitCounter interface {
+	AddHits(ctx context.Context, hits map[Key]int) error
+}
+
+// Layered reads through a memory cache (L1) to a durable one (L2). L2 hits
+// warm L1; L1 hits are counted and handed to L2 on Clean.
+type Layered struct {
+	l1      *MemoryCache
+	l2      Cache
+	counter HitCounter // l2, if it counts hits
+
+	pending map[Key]int // L1 hits L2 doesn't know about yet
+	mu      sync.Mutex
+}
+
+// NewLayered creates a read-through cache over l2
+func NewLayered(l2 Cache) *Layered {
+	c := &Layered{
+		l1:      NewMemoryCache(),
+		l2:      l2,
+		pending: make(map[Key]int),
+	}
+	c.counter, _ = l2.(HitCounter)
+	return c
+}
+
+// Get looks in L1, then L2
+func (c *Layered) Get(ctx context.Context, key Key) (Entry, bool) {
+	if e, ok := c.l1.Get(ctx, key); ok {
+		if c.counter != nil {
+			c.mu.Lock()
+			c.pending[key]++
+			c.mu.Unlock()
+		}
+		return e, true
+	}
+
+	e, ok := c.l2.Get(ctx, key)
+	if ok {
+		c.l1.put(key, e)
+	}
+	return e, ok
+}
+
+// Set writes both layers
+func (c *Layered) Set(ctx context.Context, key Key, value string, tokens int, ttl time.Duration) error {
+	c.l1.Set(ctx, key, value, tokens, ttl)
+	return c.l2.Set(ctx, key, value, tokens, ttl)
+}
+
+// Clean removes expired entries from both layers and hands the L1 hits to L2
+func (c *Layered) Clean(ctx context.Context) error {
+	c.l1.Clean(ctx)
+
+	if c.counter != nil {
+		c.mu.Lock()
+		hits := c.pending
+		c.pending = make(map[Key]int)
+		c.mu.Unlock()
+
+		if err := c.counter.AddHits(ctx, hits); err != nil {
+			// Keep them for the next round
+			c.mu.Lock()
+			for k, n := range hits {
+				c.pending[k] += n
+			}
+			c.mu.Unlock()
+			return err
+		}
+	}
+
+	return c.l2.Clean(ctx)
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// countingCache is an L2 that counts the hits handed to it
+type countingCache struct {
+	*MemoryCache
+	gets int
+	hits map[Key]int
+}
+
+func newCountingCache() *countingCache {
+	return &countingCache{MemoryCache: NewMemoryCache(), hits: make(map[Key]int)}
+}
+
+func (c *countingCache) Get(ctx context.Context, key Key) (Entry, bool) {
+	c.gets++
+	return c.MemoryCache.Get(ctx, key)
+}
+
+func (c *countingCache) AddHits(ctx context.Context, hits map[Key]int) error {
+	for k, n := range hits {
+		c.hits[k] += n
+	}
+	return nil
+}
+
+func TestMemoryCacheExpires(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	key := Key{PromptType: "raw", Hash: GenerateKey("", "hola")}
+
+	c.Set(ctx, key, "¡Pura vida!", 12, time.Minute)
+	if e, ok := c.Get(ctx, key); !ok || e.Value != "¡Pura vida!" || e.Tokens != 12 {
+		t.Fatalf("Get = %+v, %v", e, ok)
+	}
+
+	c.Set(ctx, key, "viejo", 12, -time.Second)
+	if _, ok := c.Get(ctx, key); ok {
+		t.Error("expired entry was served")
+	}
+	c.Clean(ctx)
+	if len(c.items) != 0 {
+		t.Errorf("Clean left %d items", len(c.items))
+	}
+}
+
+func TestLayeredReadsThrough(t *testing.T) {
+	ctx := context.Background()
+	l2 := newCountingCache()
+	key := Key{PromptType: "dish_generation", Hash: "abc"}
+
+	// Written by a previous process: only L2 has it
+	l2.Set(ctx, key, "El Arriero", 300, time.Hour)
+
+	c := NewLayered(l2)
+	for i := 0; i < 3; i++ {
+		if e, ok := c.Get(ctx, key); !ok || e.Value != "El Arriero" {
+			t.Fatalf("Get %d = %+v, %v", i, e, ok)
+		}
+	}
+	if l2.gets != 1 {
+		t.Errorf("L2 read %d times, want 1 (then L1 is warm)", l2.gets)
+	}
+
+	if err := c.Clean(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if l2.hits[key] != 2 {
+		t.Errorf("L2 was handed %d L1 hits, want 2", l2.hits[key])
+	}
+	if err := c.Clean(ctx); err != nil || l2.hits[key] != 2 {
+		t.Errorf("hits handed twice: %d", l2.hits[key])
+	}
+}
+
+func TestLayeredSetWritesBoth(t *testing.T) {
+	ctx := context.Background()
+	l2 := newCountingCache()
+	c := NewLayered(l2)
+	key := Key{PromptType: "raw", Hash: "x"}
+
+	c.Set(ctx, key, "Tuanis", 5, time.Hour)
+	if _, ok := l2.MemoryCache.Get(ctx, key); !ok {
+		t.Error("L2 missing the entry")
+	}
+	if _, ok := c.Get(ctx, key); !ok || l2.gets != 0 {
+		t.Errorf("expected an L1 hit, L2 read %d times", l2.gets)
+	}
+}
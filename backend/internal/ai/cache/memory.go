@@ -1,74 +1,65 @@
 package cache
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
 	"sync"
 	"time"
 )
 
-// Item represents a cached response
-type Item struct {
-	Value      string
-	Expiration time.Time
-}
-
 // MemoryCache implements a simple in-memory cache
 type MemoryCache struct {
-	items map[string]Item
+	items map[Key]Entry
 	mu    sync.RWMutex
 }
 
 // NewMemoryCache creates a new in-memory cache
 func NewMemoryCache() *MemoryCache {
 	return &MemoryCache{
-		items: make(map[string]Item),
+		items: make(map[Key]Entry),
 	}
 }
 
-// GenerateKey creates a cache key from prompt components
-func (c *MemoryCache) GenerateKey(systemPrompt, userPrompt string) string {
-	hash := sha256.Sum256([]byte(systemPrompt + "||" + userPrompt))
-	return hex.EncodeToString(hash[:])
-}
-
 // Get retrieves a value from cache
-func (c *MemoryCache) Get(key string) (string, bool) {
+func (c *MemoryCache) Get(ctx context.Context, key Key) (Entry, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	item, found := c.items[key]
 	if !found {
-		return "", false
+		return Entry{}, false
 	}
 
-	if time.Now().After(item.Expiration) {
-		return "", false
+	if time.Now().After(item.Expires) {
+		return Entry{}, false
 	}
 
-	return item.Value, true
+	return item, true
 }
 
 // Set stores a value in cache
-func (c *MemoryCache) Set(key string, value string, ttl time.Duration) {
+func (c *MemoryCache) Set(ctx context.Context, key Key, value string, tokens int, ttl time.Duration) error {
+	c.put(key, Entry{Value: value, Tokens: tokens, Expires: time.Now().Add(ttl)})
+	return nil
+}
+
+// put stores an entry as is, keeping its expiration
+func (c *MemoryCache) put(key Key, entry Entry) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.items[key] = Item{
-		Value:      value,
-		Expiration: time.Now().Add(ttl),
-	}
+	c.items[key] = entry
 }
 
 // Clean removes expired items
-func (c *MemoryCache) Clean() {
+func (c *MemoryCache) Clean(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	now := time.Now()
 	for k, v := range c.items {
-		if now.After(v.Expiration) {
+		if now.After(v.Expires) {
 			delete(c.items, k)
 		}
 	}
+	return nil
 }
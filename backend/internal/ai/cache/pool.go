@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PoolStore keeps pool lines durably. Each line is its own entry, tagged with
+// its bucket.
+type PoolStore interface {
+	HitCounter
+	Lines(ctx context.Context, bucket Key) ([]Entry, error)
+	AddLine(ctx context.Context, bucket Key, value string, tokens int, ttl time.Duration) error
+}
+
+// Pool keeps several responses per context bucket, so a request that falls in
+// a full bucket gets a random one instead of a new generation (GDD 5.4). For
+// pools Key.Hash is the bucket name, e.g. "churchill_happy_sunny_noon".
+type Pool struct {
+	store PoolStore // may be nil: memory only
+
+	buckets map[Key][]Entry
+	loaded  map[Key]bool // buckets already read from the store
+	pending map[Key]int  // hits per line the store doesn't know about yet
+	mu      sync.Mutex
+}
+
+// NewPool creates a pool cache over store
+func NewPool(store PoolStore) *Pool {
+	return &Pool{
+		store:   store,
+		buckets: make(map[Key][]Entry),
+		loaded:  make(map[Key]bool),
+		pending: make(map[Key]int),
+	}
+}
+
+// LineKey identifies one line of a bucket in the store
+func LineKey(bucket Key, value string) Key {
+	return Key{PromptType: bucket.PromptType, Hash: GenerateKey(bucket.Hash, value)}
+}
+
+// Pick returns a random live line of the bucket when it has at least min, and
+// how many live lines it has
+func (p *Pool) Pick(ctx context.Context, bucket Key, min int) (Entry, int, bool) {
+	p.load(ctx, bucket)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lines := p.live(bucket)
+	if len(lines) == 0 || len(lines) < min {
+		return Entry{}, len(lines), false
+	}
+	e := lines[rand.Intn(len(lines))]
+	if p.store != nil {
+		p.pending[LineKey(bucket, e.Value)]++
+	}
+	return e, len(lines), true
+}
+
+// Size returns how many live lines the bucket has
+func (p *Pool) Size(ctx context.Context, bucket Key) int {
+	p.load(ctx, bucket)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.live(bucket))
+}
+
+// Add puts a line in the bucket, unless it already has it
+func (p *Pool) Add(ctx context.Context, bucket Key, value string, tokens int, ttl time.Duration) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	p.load(ctx, bucket)
+
+	p.mu.Lock()
+	if has(p.live(bucket), value) {
+		p.mu.Unlock()
+		return nil
+	}
+	p.buckets[bucket] = append(p.buckets[bucket], Entry{Value: value, Tokens: tokens, Expires: time.Now().Add(ttl)})
+	p.mu.Unlock()
+
+	if p.store == nil {
+		return nil
+	}
+	return p.store.AddLine(ctx, bucket, value, tokens, ttl)
+}
+
+// Clean drops expired lines and hands the hits to the store. The store's own
+// expired rows are left to whoever cleans it.
+func (p *Pool) Clean(ctx context.Context) error {
+	p.mu.Lock()
+	for bucket := range p.buckets {
+		if lines := p.live(bucket); len(lines) == 0 {
+			delete(p.buckets, bucket)
+			delete(p.loaded, bucket)
+		}
+	}
+	hits := p.pending
+	p.pending = make(map[Key]int)
+	p.mu.Unlock()
+
+	if p.store == nil {
+		return nil
+	}
+	if err := p.store.AddHits(ctx, hits); err != nil {
+		// Keep them for the next round
+		p.mu.Lock()
+		for k, n := range hits {
+			p.pending[k] += n
+		}
+		p.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// load reads the bucket from the store the first time it's asked for
+func (p *Pool) load(ctx context.Context, bucket Key) {
+	if p.store == nil {
+		return
+	}
+	p.mu.Lock()
+	done := p.loaded[bucket]
+	p.mu.Unlock()
+	if done {
+		return
+	}
+
+	lines, err := p.store.Lines(ctx, bucket)
+	if err != nil {
+		log.Printf("AI pool read failed for %s: %v", bucket.Hash, err)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.loaded[bucket] {
+		return
+	}
+	for _, e := range lines {
+		if !has(p.buckets[bucket], e.Value) {
+			p.buckets[bucket] = append(p.buckets[bucket], e)
+		}
+	}
+	p.loaded[bucket] = true
+}
+
+// live drops the bucket's expired lines and returns the rest. Callers hold mu.
+func (p *Pool) live(bucket Key) []Entry {
+	lines := p.buckets[bucket]
+	now := time.Now()
+	kept := lines[:0]
+	for _, e := range lines {
+		if now.Before(e.Expires) {
+			kept = append(kept, e)
+		}
+	}
+	p.buckets[bucket] = kept
+	return kept
+}
+
+// has tells whether lines already say value
+func has(lines []Entry, value string) bool {
+	for _, e := range lines {
+		if strings.EqualFold(e.Value, value) {
+			return true
+		}
+	}
+	return false
+}
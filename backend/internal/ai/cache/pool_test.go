@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// memoryStore is a PoolStore kept in memory
+type memoryStore struct {
+	lines map[Key][]Entry
+	hits  map[Key]int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{lines: make(map[Key][]Entry), hits: make(map[Key]int)}
+}
+
+func (s *memoryStore) Lines(ctx context.Context, bucket Key) ([]Entry, error) {
+	return s.lines[bucket], nil
+}
+
+func (s *memoryStore) AddLine(ctx context.Context, bucket Key, value string, tokens int, ttl time.Duration) error {
+	s.lines[bucket] = append(s.lines[bucket], Entry{Value: value, Tokens: tokens, Expires: time.Now().Add(ttl)})
+	return nil
+}
+
+func (s *memoryStore) AddHits(ctx context.Context, hits map[Key]int) error {
+	for k, n := range hits {
+		s.hits[k] += n
+	}
+	return nil
+}
+
+func TestPoolServesOnlyFullBuckets(t *testing.T) {
+	ctx := context.Background()
+	p := NewPool(nil)
+	bucket := Key{PromptType: "customer_line", Hash: "churchill_happy_sunny_noon"}
+
+	p.Add(ctx, bucket, "¡Pura vida!", 10, time.Hour)
+	p.Add(ctx, bucket, "Tuanis, mae", 10, time.Hour)
+	if _, size, ok := p.Pick(ctx, bucket, 3); ok || size != 2 {
+		t.Fatalf("Pick with 2 lines = %v (size %d), want a miss", ok, size)
+	}
+
+	p.Add(ctx, bucket, "  ¡pura VIDA!  ", 10, time.Hour) // same line
+	p.Add(ctx, bucket, "Diay, qué rico", 10, time.Hour)
+	e, size, ok := p.Pick(ctx, bucket, 3)
+	if !ok || size != 3 {
+		t.Fatalf("Pick = %+v, size %d, %v; want a hit of 3", e, size, ok)
+	}
+}
+
+func TestPoolDropsExpiredLines(t *testing.T) {
+	ctx := context.Background()
+	p := NewPool(nil)
+	bucket := Key{PromptType: "customer_line", Hash: "b"}
+
+	p.Add(ctx, bucket, "vieja", 10, -time.Second)
+	p.Add(ctx, bucket, "nueva", 10, time.Hour)
+	if size := p.Size(ctx, bucket); size != 1 {
+		t.Errorf("Size = %d, want 1", size)
+	}
+}
+
+func TestPoolLoadsFromStoreAndCountsHits(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	bucket := Key{PromptType: "customer_line", Hash: "granizado_neutral_rainy_morning"}
+	for _, line := range []string{"Está bien", "Ok", "Ni modo"} {
+		store.AddLine(ctx, bucket, line, 8, time.Hour)
+	}
+
+	p := NewPool(store)
+	e, _, ok := p.Pick(ctx, bucket, 3)
+	if !ok {
+		t.Fatal("expected the stored lines to be served")
+	}
+
+	p.Add(ctx, bucket, "Está bien", 8, time.Hour)
+	if len(store.lines[bucket]) != 3 {
+		t.Errorf("store has %d lines, want 3 (repeated line added)", len(store.lines[bucket]))
+	}
+
+	if err := p.Clean(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if store.hits[LineKey(bucket, e.Value)] != 1 {
+		t.Errorf("store hits = %v, want 1 for %q", store.hits, e.Value)
+	}
+}
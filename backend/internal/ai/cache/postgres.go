@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// retention is how long expired rows stay in ai_cache, so their hits still
+// count in the savings
+const retention = 7 * 24 * time.Hour
+
+// PostgresCache stores responses in the ai_cache table, so they survive restarts.
+// hits counts the times a response was served from cache.
+type PostgresCache struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresCache creates a cache over ai_cache
+func NewPostgresCache(db *pgxpool.Pool) *PostgresCache {
+	return &PostgresCache{db: db}
+}
+
+// Get returns the live entry for key and counts the hit
+func (c *PostgresCache) Get(ctx context.Context, key Key) (Entry, bool) {
+	var e Entry
+	err := c.db.QueryRow(ctx, `
+		UPDATE ai_cache SET hits = hits + 1
+		WHERE context_hash = $1 AND prompt_type = $2 AND expires_at > NOW()
+		RETURNING response, COALESCE(tokens_used, 0), expires_at
+	`, key.Hash, key.PromptType).Scan(&e.Value, &e.Tokens, &e.Expires)
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			log.Printf("AI cache read failed: %v", err)
+		}
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Set stores value for ttl. A replaced entry keeps its hits.
+func (c *PostgresCache) Set(ctx context.Context, key Key, value string, tokens int, ttl time.Duration) error {
+	_, err := c.db.Exec(ctx, `
+		INSERT INTO ai_cache (context_hash, prompt_type, response, tokens_used, hits, expires_at)
+		VALUES ($1, $2, $3, $4, 0, NOW() + make_interval(secs => $5))
+		ON CONFLICT (context_hash, prompt_type) DO UPDATE SET
+			response = EXCLUDED.response,
+			tokens_used = EXCLUDED.tokens_used,
+			expires_at = EXCLUDED.expires_at
+	`, key.Hash, key.PromptType, value, tokens, ttl.Seconds())
+	return err
+}
+
+// AddHits counts hits served by a faster layer in front of this one
+func (c *PostgresCache) AddHits(ctx context.Context, hits map[Key]int) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	batch := &pgx.Batch{}
+	for key, n := range hits {
+		batch.Queue(`
+			UPDATE ai_cache SET hits = hits + $3
+			WHERE context_hash = $1 AND prompt_type = $2
+		`, key.Hash, key.PromptType, n)
+	}
+	return c.db.SendBatch(ctx, batch).Close()
+}
+
+// Lines returns the live lines of a pool bucket
+func (c *PostgresCache) Lines(ctx context.Context, bucket Key) ([]Entry, error) {
+	rows, err := c.db.Query(ctx, `
+		SELECT response, COALESCE(tokens_used, 0), expires_at
+		FROM ai_cache
+		WHERE prompt_type = $1 AND context->>'bucket' = $2 AND expires_at > NOW()
+	`, bucket.PromptType, bucket.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[Entry])
+}
+
+// AddLine stores a line of a pool bucket as its own entry
+func (c *PostgresCache) AddLine(ctx context.Context, bucket Key, value string, tokens int, ttl time.Duration) error {
+	line := LineKey(bucket, value)
+	_, err := c.db.Exec(ctx, `
+		INSERT INTO ai_cache (context_hash, prompt_type, context, response, tokens_used, hits, expires_at)
+		VALUES ($1, $2, jsonb_build_object('bucket', $3::text), $4, $5, 0, NOW() + make_interval(secs => $6))
+		ON CONFLICT (context_hash, prompt_type) DO UPDATE SET expires_at = EXCLUDED.expires_at
+	`, line.Hash, line.PromptType, bucket.Hash, value, tokens, ttl.Seconds())
+	return err
+}
+
+// Clean removes entries expired longer than the retention
+func (c *PostgresCache) Clean(ctx context.Context) error {
+	_, err := c.db.Exec(ctx, `
+		DELETE FROM ai_cache WHERE expires_at < NOW() - make_interval(secs => $1)
+	`, retention.Seconds())
+	return err
+}
+
+// TypeStats is what the cache saved for one prompt type
+type TypeStats struct {
+	PromptType  string `json:"prompt_type"`
+	Entries     int    `json:"entries"`
+	Live        int    `json:"live"` // not expired yet
+	Hits        int64  `json:"hits"`
+	TokensSaved int64  `json:"tokens_saved"`
+}
+
+// Stats returns the hits and saved tokens per prompt type, biggest savings first
+func (c *PostgresCache) Stats(ctx context.Context) ([]TypeStats, error) {
+	rows, err := c.db.Query(ctx, `
+		SELECT prompt_type, COUNT(*)::int, (COUNT(*) FILTER (WHERE expires_at > NOW()))::int,
+		       COALESCE(SUM(hits), 0)::bigint, COALESCE(SUM(hits::bigint * COALESCE(tokens_used, 0)), 0)::bigint
+		FROM ai_cache
+		GROUP BY prompt_type
+		ORDER BY 5 DESC, 1
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[TypeStats])
+}
@@ -36,14 +36,23 @@ type AIConfig struct {
 	TimeoutSeconds int     `json:"timeout_seconds"`
 
 	// Cache settings
-	CacheEnabled   bool `json:"cache_enabled"`
+	CacheEnabled    bool `json:"cache_enabled"`
 	CacheTTLMinutes int  `json:"cache_ttl_minutes"`
 
 	// Rate limiting
-	MaxRequestsPerMinute int `json:"max_requests_per_minute"`
+	MaxRequestsPerMinute    int `json:"max_requests_per_minute"`              // per provider, 0 = unlimited
+	PlayerRequestsPerMinute int `json:"player_requests_per_minute,omitempty"` // per player, 0 = unlimited
+	MaxQueueSeconds         int `json:"max_queue_seconds"`                    // wait for a free slot before degrading
 
 	// Fallback behavior
 	FallbackEnabled bool `json:"fallback_enabled"`
+
+	// Failover chain, tried in order before the fallback. Empty means the
+	// single provider above.
+	Providers []ProviderConfig `json:"providers,omitempty"`
+
+	// Template profiles from the ai_templates parameters, by template name
+	Templates map[string]TemplateProfile `json:"-"`
 }
 
 // DefaultConfig returns sensible defaults
@@ -59,6 +68,7 @@ func DefaultConfig() *AIConfig {
 		CacheEnabled:         true,
 		CacheTTLMinutes:      15,
 		MaxRequestsPerMinute: 60,
+		MaxQueueSeconds:      5,
 		FallbackEnabled:      true,
 	}
 }
@@ -74,6 +84,7 @@ func (c *AIConfig) GetProviderType() ProviderType {
 // LoadFromDB loads AI configuration from the parameters table
 func LoadFromDB(ctx context.Context, pool *pgxpool.Pool) (*AIConfig, error) {
 	config := DefaultConfig()
+	config.Templates = loadTemplates(ctx, pool)
 
 	// Query the ai_provider parameter
 	var configJSON []byte
@@ -110,6 +121,7 @@ func LoadFromDB(ctx context.Context, pool *pgxpool.Pool) (*AIConfig, error) {
 			config.APIKey = decrypted
 		}
 	}
+	config.decryptProviderKeys()
 
 	return config, nil
 }
@@ -127,20 +139,30 @@ func SaveToDB(ctx context.Context, pool *pgxpool.Pool, config *AIConfig) error {
 		apiKeyEncrypted = encrypted
 	}
 
+	providers, err := config.encryptProviderKeys()
+	if err != nil {
+		return err
+	}
+
 	// Build config JSON (without raw API key, with encrypted version)
 	configMap := map[string]interface{}{
-		"enabled":                 config.Enabled,
-		"provider_type":           config.ProviderType,
-		"provider_url":            config.ProviderURL,
-		"model":                   config.Model,
-		"max_tokens":              config.MaxTokens,
-		"temperature":             config.Temperature,
-		"timeout_seconds":         config.TimeoutSeconds,
-		"cache_enabled":           config.CacheEnabled,
-		"cache_ttl_minutes":       config.CacheTTLMinutes,
-		"max_requests_per_minute": config.MaxRequestsPerMinute,
-		"fallback_enabled":        config.FallbackEnabled,
-		"api_key_encrypted":       apiKeyEncrypted,
+		"enabled":                    config.Enabled,
+		"provider_type":              config.ProviderType,
+		"provider_url":               config.ProviderURL,
+		"model":                      config.Model,
+		"max_tokens":                 config.MaxTokens,
+		"temperature":                config.Temperature,
+		"timeout_seconds":            config.TimeoutSeconds,
+		"cache_enabled":              config.CacheEnabled,
+		"cache_ttl_minutes":          config.CacheTTLMinutes,
+		"max_requests_per_minute":    config.MaxRequestsPerMinute,
+		"player_requests_per_minute": config.PlayerRequestsPerMinute,
+		"max_queue_seconds":          config.MaxQueueSeconds,
+		"fallback_enabled":           config.FallbackEnabled,
+		"api_key_encrypted":          apiKeyEncrypted,
+	}
+	if len(providers) > 0 {
+		configMap["providers"] = providers
 	}
 
 	configJSON, err := json.Marshal(configMap)
@@ -170,12 +192,25 @@ func (c *AIConfig) GetCacheTTL() time.Duration {
 	return time.Duration(c.CacheTTLMinutes) * time.Minute
 }
 
+// GetQueueWait returns how long a request may wait for a rate limit slot
+func (c *AIConfig) GetQueueWait() time.Duration {
+	return time.Duration(c.MaxQueueSeconds) * time.Second
+}
+
 // GetTimeout returns timeout as time.Duration
 func (c *AIConfig) GetTimeout() time.Duration {
 	return time.Duration(c.TimeoutSeconds) * time.Second
 }
 
-// IsReady returns true if AI is properly configured and enabled
+// IsReady returns true if AI is enabled and some provider of the chain can be called
 func (c *AIConfig) IsReady() bool {
-	return c.Enabled && c.APIKey != "" && c.ProviderURL != ""
+	if !c.Enabled {
+		return false
+	}
+	for _, p := range c.Chain() {
+		if p.IsReady() {
+			return true
+		}
+	}
+	return false
 }
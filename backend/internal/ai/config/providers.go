@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/ai/crypto"
+)
+
+// ProviderTypeOllama is a local OpenAI-compatible server that needs no API key
+const ProviderTypeOllama ProviderType = "ollama"
+
+// ProviderConfig is one step of the failover chain
+type ProviderConfig struct {
+	Name           string       `json:"name"` // label for metrics and logs: "anthropic", "groq", "ollama"
+	Type           ProviderType `json:"type"`
+	URL            string       `json:"url"`
+	Model          string       `json:"model"`
+	TimeoutSeconds int          `json:"timeout_seconds,omitempty"` // 0 uses AIConfig.TimeoutSeconds
+	Enabled        bool         `json:"enabled"`
+
+	// Rate limit, 0 uses AIConfig.MaxRequestsPerMinute
+	MaxRequestsPerMinute int `json:"max_requests_per_minute,omitempty"`
+
+	// API Key (stored encrypted in DB)
+	APIKeyEncrypted string `json:"api_key_encrypted,omitempty"`
+	APIKey          string `json:"-"` // Decrypted at runtime
+}
+
+// Chain returns the providers to try, in order. Configs saved before the chain
+// existed get their single top-level provider as the only step.
+func (c *AIConfig) Chain() []ProviderConfig {
+	if len(c.Providers) > 0 {
+		return c.Providers
+	}
+	return []ProviderConfig{{
+		Name:           string(c.GetProviderType()),
+		Type:           c.GetProviderType(),
+		URL:            c.ProviderURL,
+		Model:          c.Model,
+		TimeoutSeconds: c.TimeoutSeconds,
+		Enabled:        true,
+		APIKey:         c.APIKey,
+	}}
+}
+
+// ForProvider returns a copy of the config that talks to p, keeping the
+// generation defaults
+func (c *AIConfig) ForProvider(p ProviderConfig) *AIConfig {
+	cfg := *c
+	cfg.Providers = nil
+	cfg.Enabled = c.Enabled && p.Enabled
+	cfg.ProviderType = p.Type
+	cfg.ProviderURL = p.URL
+	cfg.Model = p.Model
+	cfg.APIKey = p.APIKey
+	cfg.APIKeyEncrypted = p.APIKeyEncrypted
+	if p.TimeoutSeconds > 0 {
+		cfg.TimeoutSeconds = p.TimeoutSeconds
+	}
+	return &cfg
+}
+
+// NeedsAPIKey is false for local servers
+func (p ProviderConfig) NeedsAPIKey() bool {
+	return p.Type != ProviderTypeOllama
+}
+
+// IsReady returns true if the provider can be called
+func (p ProviderConfig) IsReady() bool {
+	return p.Enabled && p.URL != "" && (p.APIKey != "" || !p.NeedsAPIKey())
+}
+
+// GetTimeout returns the provider's timeout, or def if it has none
+func (p ProviderConfig) GetTimeout(def time.Duration) time.Duration {
+	if p.TimeoutSeconds > 0 {
+		return time.Duration(p.TimeoutSeconds) * time.Second
+	}
+	return def
+}
+
+// GetRequestsPerMinute returns the provider's rate limit, or def if it has none
+func (p ProviderConfig) GetRequestsPerMinute(def int) int {
+	if p.MaxRequestsPerMinute > 0 {
+		return p.MaxRequestsPerMinute
+	}
+	return def
+}
+
+// ValidateProviders checks the chain has unique names and known types
+func ValidateProviders(list []ProviderConfig) error {
+	seen := make(map[string]bool, len(list))
+	for i, p := range list {
+		if p.Name == "" {
+			return fmt.Errorf("provider %d: name is required", i+1)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("provider %q is listed twice", p.Name)
+		}
+		seen[p.Name] = true
+
+		switch p.Type {
+		case ProviderTypeAnthropic, ProviderTypeOpenAI, ProviderTypeOllama:
+		default:
+			return fmt.Errorf("provider %q: unknown type %q", p.Name, p.Type)
+		}
+		if p.URL == "" || p.Model == "" {
+			return fmt.Errorf("provider %q: url and model are required", p.Name)
+		}
+		if p.TimeoutSeconds < 0 || p.MaxRequestsPerMinute < 0 {
+			return fmt.Errorf("provider %q: timeout and rate limit can't be negative", p.Name)
+		}
+	}
+	return nil
+}
+
+// decryptProviderKeys fills APIKey for every step that has a stored key. A key
+// that can't be decrypted leaves that step unavailable.
+func (c *AIConfig) decryptProviderKeys() {
+	for i := range c.Providers {
+		p := &c.Providers[i]
+		if p.APIKeyEncrypted == "" {
+			continue
+		}
+		decrypted, err := crypto.Decrypt(p.APIKeyEncrypted)
+		if err != nil {
+			p.APIKey = ""
+			continue
+		}
+		p.APIKey = decrypted
+	}
+}
+
+// encryptProviderKeys returns the chain as stored: new keys encrypted, the
+// already stored ones kept
+func (c *AIConfig) encryptProviderKeys() ([]ProviderConfig, error) {
+	stored := make([]ProviderConfig, len(c.Providers))
+	for i, p := range c.Providers {
+		if p.APIKey != "" {
+			encrypted, err := crypto.Encrypt(p.APIKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt API key of %s: %w", p.Name, err)
+			}
+			p.APIKeyEncrypted = encrypted
+		}
+		p.APIKey = ""
+		stored[i] = p
+	}
+	return stored, nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import "testing"
+
+func TestChainFallsBackToTopLevelProvider(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.APIKey = "sk-ant"
+
+	chain := cfg.Chain()
+	if len(chain) != 1 {
+		t.Fatalf("chain has %d steps, want 1", len(chain))
+	}
+	if p := chain[0]; p.Type != ProviderTypeAnthropic || p.Model != cfg.Model || p.APIKey != "sk-ant" {
+		t.Errorf("legacy step = %+v", p)
+	}
+}
+
+func TestForProviderKeepsGenerationDefaults(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Enabled = true
+	p := ProviderConfig{Name: "groq", Type: ProviderTypeOpenAI, URL: "u", Model: "m", APIKey: "k", Enabled: true, TimeoutSeconds: 5}
+
+	got := cfg.ForProvider(p)
+	if got.Model != "m" || got.APIKey != "k" || got.TimeoutSeconds != 5 || got.MaxTokens != cfg.MaxTokens {
+		t.Errorf("ForProvider = %+v", got)
+	}
+	if !got.Enabled {
+		t.Error("enabled provider of an enabled config should stay enabled")
+	}
+}
+
+func TestProviderIsReady(t *testing.T) {
+	tests := []struct {
+		name string
+		p    ProviderConfig
+		want bool
+	}{
+		{"keyed", ProviderConfig{Type: ProviderTypeOpenAI, URL: "u", APIKey: "k", Enabled: true}, true},
+		{"missing key", ProviderConfig{Type: ProviderTypeAnthropic, URL: "u", Enabled: true}, false},
+		{"ollama without key", ProviderConfig{Type: ProviderTypeOllama, URL: "u", Enabled: true}, true},
+		{"disabled", ProviderConfig{Type: ProviderTypeOllama, URL: "u"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.p.IsReady(); got != tt.want {
+			t.Errorf("%s: IsReady = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateProviders(t *testing.T) {
+	ok := ProviderConfig{Name: "groq", Type: ProviderTypeOpenAI, URL: "u", Model: "m"}
+	if err := ValidateProviders([]ProviderConfig{ok}); err != nil {
+		t.Errorf("valid chain: %v", err)
+	}
+
+	bad := map[string][]ProviderConfig{
+		"duplicate":    {ok, ok},
+		"no name":      {{Type: ProviderTypeOpenAI, URL: "u", Model: "m"}},
+		"unknown type": {{Name: "x", Type: "gemini", URL: "u", Model: "m"}},
+		"no model":     {{Name: "x", Type: ProviderTypeOllama, URL: "u"}},
+	}
+	for name, chain := range bad {
+		if err := ValidateProviders(chain); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CachePolicy says how a template's responses are cached
+type CachePolicy string
+
+const (
+	CacheDefault CachePolicy = ""     // follows AIConfig.CacheEnabled
+	CacheOff     CachePolicy = "off"  // always asks a provider
+	CachePool    CachePolicy = "pool" // several lines per context bucket, served at random
+)
+
+// DefaultPoolSize is how many lines a bucket is topped up to
+const DefaultPoolSize = 10
+
+// TemplateProfile binds a prompt template to its generation settings. Zero
+// fields take the global config.
+type TemplateProfile struct {
+	SystemPrompt    string      `json:"system_prompt,omitempty"`
+	Provider        string      `json:"provider,omitempty"` // chain step tried first
+	Model           string      `json:"model,omitempty"`    // model for that step (the first one if no provider is named)
+	MaxTokens       int         `json:"max_tokens,omitempty"`
+	Temperature     float64     `json:"temperature,omitempty"`
+	Cache           CachePolicy `json:"cache,omitempty"`
+	CacheTTLMinutes int         `json:"cache_ttl_minutes,omitempty"`
+	PoolSize        int         `json:"pool_size,omitempty"` // lines per bucket in pool mode
+}
+
+// Merge returns p with the fields set in o on top
+func (p TemplateProfile) Merge(o TemplateProfile) TemplateProfile {
+	if o.SystemPrompt != "" {
+		p.SystemPrompt = o.SystemPrompt
+	}
+	if o.Provider != "" {
+		p.Provider = o.Provider
+	}
+	if o.Model != "" {
+		p.Model = o.Model
+	}
+	if o.MaxTokens > 0 {
+		p.MaxTokens = o.MaxTokens
+	}
+	if o.Temperature > 0 {
+		p.Temperature = o.Temperature
+	}
+	if o.Cache != CacheDefault {
+		p.Cache = o.Cache
+	}
+	if o.CacheTTLMinutes > 0 {
+		p.CacheTTLMinutes = o.CacheTTLMinutes
+	}
+	if o.PoolSize > 0 {
+		p.PoolSize = o.PoolSize
+	}
+	return p
+}
+
+// GetPoolSize returns how many lines a bucket should hold
+func (p TemplateProfile) GetPoolSize() int {
+	if p.PoolSize > 0 {
+		return p.PoolSize
+	}
+	return DefaultPoolSize
+}
+
+// CacheEnabledFor tells whether responses of a template with profile p are cached
+func (c *AIConfig) CacheEnabledFor(p TemplateProfile) bool {
+	return c.CacheEnabled && p.Cache != CacheOff
+}
+
+// CacheTTLFor returns how long responses of a template with profile p are kept
+func (c *AIConfig) CacheTTLFor(p TemplateProfile) time.Duration {
+	if p.CacheTTLMinutes > 0 {
+		return time.Duration(p.CacheTTLMinutes) * time.Minute
+	}
+	return c.GetCacheTTL()
+}
+
+// loadTemplates reads the ai_templates parameters: code is the template name,
+// config its profile
+func loadTemplates(ctx context.Context, pool *pgxpool.Pool) map[string]TemplateProfile {
+	templates := make(map[string]TemplateProfile)
+
+	rows, err := pool.Query(ctx, `
+		SELECT code, COALESCE(config, '{}')
+		FROM parameters
+		WHERE category = 'ai_templates' AND is_active = true
+	`)
+	if err != nil {
+		log.Printf("Warning: failed to load AI template profiles: %v", err)
+		return templates
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code string
+		var configJSON []byte
+		if err := rows.Scan(&code, &configJSON); err != nil {
+			log.Printf("Warning: failed to read AI template profile: %v", err)
+			return templates
+		}
+		var p TemplateProfile
+		if err := json.Unmarshal(configJSON, &p); err != nil {
+			log.Printf("Skipping AI template profile %s: invalid config: %v", code, err)
+			continue
+		}
+		templates[code] = p
+	}
+	return templates
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/alonsoalpizar/calleviva/backend/internal/ai/cache"
 	aiconfig "github.com/alonsoalpizar/calleviva/backend/internal/ai/config"
 	"github.com/alonsoalpizar/calleviva/backend/internal/ai/orchestrator"
 	"github.com/alonsoalpizar/calleviva/backend/internal/ai/providers"
@@ -29,34 +30,65 @@ func (h *AIAdminHandler) SetService(service *orchestrator.Service) {
 
 // ConfigResponse is the response for GET /admin/ai/config
 type ConfigResponse struct {
+	Enabled                 bool               `json:"enabled"`
+	ProviderType            string             `json:"provider_type"` // "openai" or "anthropic"
+	ProviderURL             string             `json:"provider_url"`
+	Model                   string             `json:"model"`
+	MaxTokens               int                `json:"max_tokens"`
+	Temperature             float64            `json:"temperature"`
+	TimeoutSeconds          int                `json:"timeout_seconds"`
+	CacheEnabled            bool               `json:"cache_enabled"`
+	CacheTTLMinutes         int                `json:"cache_ttl_minutes"`
+	MaxRequestsPerMinute    int                `json:"max_requests_per_minute"`
+	PlayerRequestsPerMinute int                `json:"player_requests_per_minute"` // 0 = unlimited
+	MaxQueueSeconds         int                `json:"max_queue_seconds"`
+	FallbackEnabled         bool               `json:"fallback_enabled"`
+	HasAPIKey               bool               `json:"has_api_key"`
+	IsReady                 bool               `json:"is_ready"`
+	Providers               []ProviderResponse `json:"providers"` // failover chain, in order
+}
+
+// ProviderResponse is a step of the failover chain (without its key)
+type ProviderResponse struct {
+	Name                 string `json:"name"`
+	Type                 string `json:"type"`
+	URL                  string `json:"url"`
+	Model                string `json:"model"`
+	TimeoutSeconds       int    `json:"timeout_seconds"`
+	MaxRequestsPerMinute int    `json:"max_requests_per_minute"`
 	Enabled              bool   `json:"enabled"`
-	ProviderType         string `json:"provider_type"` // "openai" or "anthropic"
-	ProviderURL          string `json:"provider_url"`
+	HasAPIKey            bool   `json:"has_api_key"`
+	IsReady              bool   `json:"is_ready"`
+}
+
+// ProviderRequest is a step of the failover chain in PATCH /admin/ai/config
+type ProviderRequest struct {
+	Name                 string `json:"name"`
+	Type                 string `json:"type"` // "anthropic", "openai" or "ollama"
+	URL                  string `json:"url"`
 	Model                string `json:"model"`
-	MaxTokens            int    `json:"max_tokens"`
-	Temperature          float64 `json:"temperature"`
-	TimeoutSeconds       int     `json:"timeout_seconds"`
-	CacheEnabled         bool    `json:"cache_enabled"`
-	CacheTTLMinutes      int     `json:"cache_ttl_minutes"`
-	MaxRequestsPerMinute int     `json:"max_requests_per_minute"`
-	FallbackEnabled      bool    `json:"fallback_enabled"`
-	HasAPIKey            bool    `json:"has_api_key"`
-	IsReady              bool    `json:"is_ready"`
+	TimeoutSeconds       int    `json:"timeout_seconds,omitempty"`
+	MaxRequestsPerMinute int    `json:"max_requests_per_minute,omitempty"` // 0 uses the global limit
+	Enabled              *bool  `json:"enabled,omitempty"`                 // defaults to true
+	APIKey               string `json:"api_key,omitempty"`                 // empty keeps the stored key
 }
 
 // UpdateConfigRequest is the request for PATCH /admin/ai/config
 type UpdateConfigRequest struct {
-	Enabled              *bool    `json:"enabled,omitempty"`
-	ProviderType         *string  `json:"provider_type,omitempty"` // "openai" or "anthropic"
-	ProviderURL          *string  `json:"provider_url,omitempty"`
-	Model                *string  `json:"model,omitempty"`
-	MaxTokens            *int     `json:"max_tokens,omitempty"`
-	Temperature          *float64 `json:"temperature,omitempty"`
-	TimeoutSeconds       *int     `json:"timeout_seconds,omitempty"`
-	CacheEnabled         *bool    `json:"cache_enabled,omitempty"`
-	CacheTTLMinutes      *int     `json:"cache_ttl_minutes,omitempty"`
-	MaxRequestsPerMinute *int     `json:"max_requests_per_minute,omitempty"`
-	FallbackEnabled      *bool    `json:"fallback_enabled,omitempty"`
+	Enabled                 *bool              `json:"enabled,omitempty"`
+	ProviderType            *string            `json:"provider_type,omitempty"` // "openai" or "anthropic"
+	ProviderURL             *string            `json:"provider_url,omitempty"`
+	Model                   *string            `json:"model,omitempty"`
+	MaxTokens               *int               `json:"max_tokens,omitempty"`
+	Temperature             *float64           `json:"temperature,omitempty"`
+	TimeoutSeconds          *int               `json:"timeout_seconds,omitempty"`
+	CacheEnabled            *bool              `json:"cache_enabled,omitempty"`
+	CacheTTLMinutes         *int               `json:"cache_ttl_minutes,omitempty"`
+	MaxRequestsPerMinute    *int               `json:"max_requests_per_minute,omitempty"`
+	PlayerRequestsPerMinute *int               `json:"player_requests_per_minute,omitempty"`
+	MaxQueueSeconds         *int               `json:"max_queue_seconds,omitempty"`
+	FallbackEnabled         *bool              `json:"fallback_enabled,omitempty"`
+	Providers               *[]ProviderRequest `json:"providers,omitempty"` // replaces the whole chain
 }
 
 // SetAPIKeyRequest is the request for POST /admin/ai/apikey
@@ -69,9 +101,19 @@ type TestResponse struct {
 	Success    bool   `json:"success"`
 	Message    string `json:"message"`
 	Model      string `json:"model,omitempty"`
+	Provider   string `json:"provider,omitempty"`
 	ResponseMS int64  `json:"response_ms,omitempty"`
 }
 
+// CacheStatsResponse is the response for GET /admin/ai/cache
+type CacheStatsResponse struct {
+	Entries     int                    `json:"entries"`
+	Hits        int64                  `json:"hits"`
+	TokensSaved int64                  `json:"tokens_saved"`
+	ByType      []cache.TypeStats      `json:"by_type"`
+	Live        map[string]interface{} `json:"live,omitempty"` // this process' metrics, since it started
+}
+
 // HandleGetConfig returns current AI configuration (without sensitive data)
 func (h *AIAdminHandler) HandleGetConfig(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
@@ -83,23 +125,7 @@ func (h *AIAdminHandler) HandleGetConfig(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	resp := ConfigResponse{
-		Enabled:              cfg.Enabled,
-		ProviderType:         string(cfg.GetProviderType()),
-		ProviderURL:          cfg.ProviderURL,
-		Model:                cfg.Model,
-		MaxTokens:            cfg.MaxTokens,
-		Temperature:          cfg.Temperature,
-		TimeoutSeconds:       cfg.TimeoutSeconds,
-		CacheEnabled:         cfg.CacheEnabled,
-		CacheTTLMinutes:      cfg.CacheTTLMinutes,
-		MaxRequestsPerMinute: cfg.MaxRequestsPerMinute,
-		FallbackEnabled:      cfg.FallbackEnabled,
-		HasAPIKey:            cfg.APIKey != "",
-		IsReady:              cfg.IsReady(),
-	}
-
-	respondJSON(w, http.StatusOK, resp)
+	respondJSON(w, http.StatusOK, newConfigResponse(cfg))
 }
 
 // HandleUpdateConfig updates AI configuration
@@ -151,9 +177,27 @@ func (h *AIAdminHandler) HandleUpdateConfig(w http.ResponseWriter, r *http.Reque
 	if req.MaxRequestsPerMinute != nil {
 		cfg.MaxRequestsPerMinute = *req.MaxRequestsPerMinute
 	}
+	if req.PlayerRequestsPerMinute != nil {
+		cfg.PlayerRequestsPerMinute = *req.PlayerRequestsPerMinute
+	}
+	if req.MaxQueueSeconds != nil {
+		cfg.MaxQueueSeconds = *req.MaxQueueSeconds
+	}
 	if req.FallbackEnabled != nil {
 		cfg.FallbackEnabled = *req.FallbackEnabled
 	}
+	if cfg.MaxRequestsPerMinute < 0 || cfg.PlayerRequestsPerMinute < 0 || cfg.MaxQueueSeconds < 0 {
+		respondError(w, http.StatusBadRequest, "Rate limits and queue time can't be negative")
+		return
+	}
+	if req.Providers != nil {
+		chain := mergeProviders(cfg.Providers, *req.Providers)
+		if err := aiconfig.ValidateProviders(chain); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid providers: "+err.Error())
+			return
+		}
+		cfg.Providers = chain
+	}
 
 	// Save
 	if err := aiconfig.SaveToDB(ctx, database.Pool, cfg); err != nil {
@@ -167,23 +211,7 @@ func (h *AIAdminHandler) HandleUpdateConfig(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Return updated config
-	resp := ConfigResponse{
-		Enabled:              cfg.Enabled,
-		ProviderType:         string(cfg.GetProviderType()),
-		ProviderURL:          cfg.ProviderURL,
-		Model:                cfg.Model,
-		MaxTokens:            cfg.MaxTokens,
-		Temperature:          cfg.Temperature,
-		TimeoutSeconds:       cfg.TimeoutSeconds,
-		CacheEnabled:         cfg.CacheEnabled,
-		CacheTTLMinutes:      cfg.CacheTTLMinutes,
-		MaxRequestsPerMinute: cfg.MaxRequestsPerMinute,
-		FallbackEnabled:      cfg.FallbackEnabled,
-		HasAPIKey:            cfg.APIKey != "",
-		IsReady:              cfg.IsReady(),
-	}
-
-	respondJSON(w, http.StatusOK, resp)
+	respondJSON(w, http.StatusOK, newConfigResponse(cfg))
 }
 
 // HandleSetAPIKey sets the API key (encrypted)
@@ -274,10 +302,103 @@ func (h *AIAdminHandler) HandleTest(w http.ResponseWriter, r *http.Request) {
 		Success:    true,
 		Message:    "Connection successful! Response: " + resp.Text,
 		Model:      resp.ModelName,
+		Provider:   resp.ProviderName,
 		ResponseMS: duration.Milliseconds(),
 	})
 }
 
+// HandleCacheStats returns how much the AI response cache saved
+func (h *AIAdminHandler) HandleCacheStats(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	byType, err := cache.NewPostgresCache(database.Pool).Stats(ctx)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load AI cache stats")
+		return
+	}
+
+	resp := CacheStatsResponse{ByType: byType}
+	if resp.ByType == nil {
+		resp.ByType = []cache.TypeStats{}
+	}
+	for _, t := range byType {
+		resp.Entries += t.Entries
+		resp.Hits += t.Hits
+		resp.TokensSaved += t.TokensSaved
+	}
+	if h.service != nil {
+		resp.Live = h.service.GetMetrics()
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// newConfigResponse builds the config response (without sensitive data)
+func newConfigResponse(cfg *aiconfig.AIConfig) ConfigResponse {
+	resp := ConfigResponse{
+		Enabled:                 cfg.Enabled,
+		ProviderType:            string(cfg.GetProviderType()),
+		ProviderURL:             cfg.ProviderURL,
+		Model:                   cfg.Model,
+		MaxTokens:               cfg.MaxTokens,
+		Temperature:             cfg.Temperature,
+		TimeoutSeconds:          cfg.TimeoutSeconds,
+		CacheEnabled:            cfg.CacheEnabled,
+		CacheTTLMinutes:         cfg.CacheTTLMinutes,
+		MaxRequestsPerMinute:    cfg.MaxRequestsPerMinute,
+		PlayerRequestsPerMinute: cfg.PlayerRequestsPerMinute,
+		MaxQueueSeconds:         cfg.MaxQueueSeconds,
+		FallbackEnabled:         cfg.FallbackEnabled,
+		HasAPIKey:               cfg.APIKey != "",
+		IsReady:                 cfg.IsReady(),
+		Providers:               []ProviderResponse{},
+	}
+	for _, p := range cfg.Chain() {
+		resp.Providers = append(resp.Providers, ProviderResponse{
+			Name:                 p.Name,
+			Type:                 string(p.Type),
+			URL:                  p.URL,
+			Model:                p.Model,
+			TimeoutSeconds:       int(p.GetTimeout(cfg.GetTimeout()).Seconds()),
+			MaxRequestsPerMinute: p.GetRequestsPerMinute(cfg.MaxRequestsPerMinute),
+			Enabled:              p.Enabled,
+			HasAPIKey:            p.APIKey != "",
+			IsReady:              p.IsReady(),
+		})
+	}
+	return resp
+}
+
+// mergeProviders builds the new chain from the request, keeping the stored key
+// of each provider the request doesn't send one for
+func mergeProviders(current []aiconfig.ProviderConfig, reqs []ProviderRequest) []aiconfig.ProviderConfig {
+	stored := make(map[string]aiconfig.ProviderConfig, len(current))
+	for _, p := range current {
+		stored[p.Name] = p
+	}
+
+	chain := make([]aiconfig.ProviderConfig, 0, len(reqs))
+	for _, r := range reqs {
+		p := aiconfig.ProviderConfig{
+			Name:                 r.Name,
+			Type:                 aiconfig.ProviderType(r.Type),
+			URL:                  r.URL,
+			Model:                r.Model,
+			TimeoutSeconds:       r.TimeoutSeconds,
+			MaxRequestsPerMinute: r.MaxRequestsPerMinute,
+			Enabled:              r.Enabled == nil || *r.Enabled,
+			APIKey:               r.APIKey,
+		}
+		if p.APIKey == "" {
+			p.APIKey = stored[r.Name].APIKey
+			p.APIKeyEncrypted = stored[r.Name].APIKeyEncrypted
+		}
+		chain = append(chain, p)
+	}
+	return chain
+}
+
 // Helper functions
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
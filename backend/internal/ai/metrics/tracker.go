@@ -9,8 +9,13 @@ import (
 type Tracker struct {
 	totalRequests   int64
 	totalTokens     int64
+	failovers       int64 // requests not served by the first provider of the chain
+	cacheMisses     int64
+	tokensSaved     int64 // tokens the cache hits would have cost
 	requestsByModel map[string]int64
 	errorsByModel   map[string]int64
+	cacheHitsByType map[string]int64
+	limitedBy       map[string]int64 // requests turned away by a rate limit, by provider or "player"
 	mu              sync.RWMutex
 }
 
@@ -19,6 +24,8 @@ func NewTracker() *Tracker {
 	return &Tracker{
 		requestsByModel: make(map[string]int64),
 		errorsByModel:   make(map[string]int64),
+		cacheHitsByType: make(map[string]int64),
+		limitedBy:       make(map[string]int64),
 	}
 }
 
@@ -39,6 +46,32 @@ func (t *Tracker) RecordError(model string) {
 	t.errorsByModel[model]++
 }
 
+// RecordFailover records a request served further down the chain
+func (t *Tracker) RecordFailover() {
+	atomic.AddInt64(&t.failovers, 1)
+}
+
+// RecordCacheHit records a response served from cache, which saved tokens
+func (t *Tracker) RecordCacheHit(promptType string, tokens int) {
+	atomic.AddInt64(&t.tokensSaved, int64(tokens))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cacheHitsByType[promptType]++
+}
+
+// RecordCacheMiss records a cacheable request that had to be generated
+func (t *Tracker) RecordCacheMiss() {
+	atomic.AddInt64(&t.cacheMisses, 1)
+}
+
+// RecordRateLimited records a request a rate limit turned away
+func (t *Tracker) RecordRateLimited(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limitedBy[name]++
+}
+
 // GetStats returns current stats
 func (t *Tracker) GetStats() map[string]interface{} {
 	t.mu.RLock()
@@ -54,10 +87,28 @@ func (t *Tracker) GetStats() map[string]interface{} {
 		errsCopy[k] = v
 	}
 
+	hitsCopy := make(map[string]int64)
+	var hits int64
+	for k, v := range t.cacheHitsByType {
+		hitsCopy[k] = v
+		hits += v
+	}
+
+	limitedCopy := make(map[string]int64)
+	for k, v := range t.limitedBy {
+		limitedCopy[k] = v
+	}
+
 	return map[string]interface{}{
-		"total_requests":    atomic.LoadInt64(&t.totalRequests),
-		"total_tokens":      atomic.LoadInt64(&t.totalTokens),
-		"requests_by_model": reqsCopy,
-		"errors_by_model":   errsCopy,
+		"total_requests":     atomic.LoadInt64(&t.totalRequests),
+		"total_tokens":       atomic.LoadInt64(&t.totalTokens),
+		"failovers":          atomic.LoadInt64(&t.failovers),
+		"requests_by_model":  reqsCopy,
+		"errors_by_model":    errsCopy,
+		"cache_hits":         hits,
+		"cache_misses":       atomic.LoadInt64(&t.cacheMisses),
+		"cache_hits_by_type": hitsCopy,
+		"tokens_saved":       atomic.LoadInt64(&t.tokensSaved),
+		"rate_limited":       limitedCopy,
 	}
 }
@@ -0,0 +1,18 @@
+package orchestrator
+
+import "context"
+
+// playerKey is the context key of the player a request is for
+type playerKey struct{}
+
+// WithPlayer tags ctx with the player a request is for, so it counts against
+// that player's rate limit
+func WithPlayer(ctx context.Context, playerID string) context.Context {
+	return context.WithValue(ctx, playerKey{}, playerID)
+}
+
+// playerFrom returns the player ctx was tagged with, or ""
+func playerFrom(ctx context.Context) string {
+	player, _ := ctx.Value(playerKey{}).(string)
+	return player
+}
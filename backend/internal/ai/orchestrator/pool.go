@@ -0,0 +1,142 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/ai/cache"
+	aiconfig "github.com/alonsoalpizar/calleviva/backend/internal/ai/config"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ai/providers"
+)
+
+const (
+	// minPool is how many lines a bucket needs before it's served from cache
+	minPool = 3
+
+	// topUpTimeout bounds a background refill of one bucket
+	topUpTimeout = 2 * time.Minute
+)
+
+// Bucket names a context bucket from its parts: Bucket("Churchill", "happy",
+// "sunny", "noon") is "churchill_happy_sunny_noon"
+func Bucket(parts ...string) string {
+	clean := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		clean = append(clean, strings.Join(strings.Fields(p), "-"))
+	}
+	return strings.Join(clean, "_")
+}
+
+// GenerateLine returns a line for a context bucket (GDD 5.4). Once the bucket
+// has a few lines one of them is served at random; until then the line is
+// generated and kept. Thin buckets are refilled in the background.
+func (s *Service) GenerateLine(ctx context.Context, templateName, bucket string, data interface{}, config providers.Config) (*providers.GenerateResponse, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+
+	cfg, chain := s.snapshot()
+	if !cfg.Enabled {
+		return s.GenerateText(ctx, templateName, data, config)
+	}
+
+	req, profile, err := s.buildRequest(cfg, templateName, data, config)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.CacheEnabledFor(profile) {
+		return s.generate(ctx, cfg, route(chain, profile), req)
+	}
+
+	return s.pooled(ctx, cfg, chain, profile, cache.Key{PromptType: s.promptType(templateName), Hash: bucket}, req)
+}
+
+// pooled serves a random line of the bucket when it has enough, or generates
+// one and adds it
+func (s *Service) pooled(ctx context.Context, cfg *aiconfig.AIConfig, chain []link, profile aiconfig.TemplateProfile, bucket cache.Key, req providers.GenerateRequest) (*providers.GenerateResponse, error) {
+	if entry, size, ok := s.pools.Pick(ctx, bucket, minPool); ok {
+		s.metrics.RecordCacheHit(bucket.PromptType, entry.Tokens)
+		if size < profile.GetPoolSize() {
+			s.topUp(cfg, chain, profile, bucket, req)
+		}
+		return &providers.GenerateResponse{
+			Text:      entry.Value,
+			Cached:    true,
+			ModelName: "cache",
+		}, nil
+	}
+	s.metrics.RecordCacheMiss()
+
+	resp, err := s.generate(ctx, cfg, route(chain, profile), req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Canned fallback lines never go in a pool
+	if resp.ProviderName != s.fallbackProvider.Name() {
+		if err := s.pools.Add(ctx, bucket, resp.Text, resp.Usage.TotalTokens, cfg.CacheTTLFor(profile)); err != nil {
+			log.Printf("AI pool write failed for %s: %v", bucket.Hash, err)
+		}
+	}
+	s.topUp(cfg, chain, profile, bucket, req)
+
+	return resp, nil
+}
+
+// topUp fills the bucket up to the profile's pool size in the background. Only
+// one refill per bucket runs at a time, and it never uses the fallback.
+func (s *Service) topUp(cfg *aiconfig.AIConfig, chain []link, profile aiconfig.TemplateProfile, bucket cache.Key, req providers.GenerateRequest) {
+	if len(chain) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	if s.refilling[bucket] {
+		s.mu.Unlock()
+		return
+	}
+	s.refilling[bucket] = true
+	s.mu.Unlock()
+
+	// Refills only use free slots: they never queue or reach the fallback
+	noFallback := *cfg
+	noFallback.FallbackEnabled = false
+	noFallback.MaxQueueSeconds = 0
+	chain = route(chain, profile)
+	target := profile.GetPoolSize()
+
+	s.refills.Add(1)
+	go func() {
+		defer s.refills.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.refilling, bucket)
+			s.mu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), topUpTimeout)
+		defer cancel()
+
+		// Repeated lines don't count, so give up after target attempts
+		for attempt := 0; attempt < target; attempt++ {
+			if s.pools.Size(ctx, bucket) >= target {
+				return
+			}
+			resp, err := s.generate(ctx, &noFallback, chain, req)
+			if err != nil {
+				log.Printf("AI pool top-up of %s stopped: %v", bucket.Hash, err)
+				return
+			}
+			if err := s.pools.Add(ctx, bucket, resp.Text, resp.Usage.TotalTokens, cfg.CacheTTLFor(profile)); err != nil {
+				log.Printf("AI pool write failed for %s: %v", bucket.Hash, err)
+			}
+		}
+	}()
+}
@@ -3,25 +3,44 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"log"
+	"sync"
 	"time"
 
-	aiconfig "github.com/alonsoalpizar/calleviva/backend/internal/ai/config"
 	"github.com/alonsoalpizar/calleviva/backend/internal/ai/cache"
+	aiconfig "github.com/alonsoalpizar/calleviva/backend/internal/ai/config"
 	"github.com/alonsoalpizar/calleviva/backend/internal/ai/metrics"
 	"github.com/alonsoalpizar/calleviva/backend/internal/ai/prompts"
 	"github.com/alonsoalpizar/calleviva/backend/internal/ai/providers"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ai/ratelimit"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Service is the main entry point for AI operations
 type Service struct {
 	config           *aiconfig.AIConfig
-	primaryProvider  providers.Provider
+	chain            []link // configured providers, in failover order
 	fallbackProvider providers.Provider
-	cache            *cache.MemoryCache
+	cache            cache.Cache
+	pools            *cache.Pool // context buckets for pool-mode templates
 	metrics          *metrics.Tracker
+	limits           *ratelimit.Limiter // per provider
+	players          *ratelimit.Limiter // per player
 	prompts          *prompts.PromptBuilder
+	profiles         map[string]aiconfig.TemplateProfile // registered with the templates
 	pool             *pgxpool.Pool
+	refilling        map[cache.Key]bool // buckets being topped up
+	refills          sync.WaitGroup
+	mu               sync.RWMutex // guards config, chain, profiles and refilling
+}
+
+// link is one step of the failover chain
+type link struct {
+	name     string
+	provider providers.Provider
+	config   *aiconfig.AIConfig // the provider's own config
+	timeout  time.Duration
+	rpm      int // requests per minute, 0 = unlimited
 }
 
 // NewService creates a new AI orchestrator from database config
@@ -32,18 +51,21 @@ func NewService(ctx context.Context, pool *pgxpool.Pool) (*Service, error) {
 		return nil, fmt.Errorf("failed to load AI config: %w", err)
 	}
 
+	store := cache.NewPostgresCache(pool)
 	s := &Service{
-		config:  cfg,
-		cache:   cache.NewMemoryCache(),
-		metrics: metrics.NewTracker(),
-		prompts: prompts.NewBuilder(),
-		pool:    pool,
-	}
-
-	// Setup providers based on config
-	if cfg.IsReady() {
-		s.primaryProvider = createProvider(cfg)
+		config:    cfg,
+		chain:     buildChain(cfg),
+		cache:     cache.NewLayered(store),
+		pools:     cache.NewPool(store),
+		metrics:   metrics.NewTracker(),
+		limits:    ratelimit.NewLimiter(),
+		players:   ratelimit.NewLimiter(),
+		prompts:   prompts.NewBuilder(),
+		profiles:  make(map[string]aiconfig.TemplateProfile),
+		refilling: make(map[cache.Key]bool),
+		pool:      pool,
 	}
+	s.registerBuiltins()
 
 	// Always have fallback
 	s.fallbackProvider = providers.NewFallbackProvider()
@@ -54,10 +76,32 @@ func NewService(ctx context.Context, pool *pgxpool.Pool) (*Service, error) {
 	return s, nil
 }
 
+// buildChain creates a provider for every ready step of the config's chain
+func buildChain(cfg *aiconfig.AIConfig) []link {
+	if !cfg.Enabled {
+		return nil
+	}
+	var chain []link
+	for _, p := range cfg.Chain() {
+		if !p.IsReady() {
+			continue
+		}
+		providerCfg := cfg.ForProvider(p)
+		chain = append(chain, link{
+			name:     p.Name,
+			provider: createProvider(providerCfg),
+			config:   providerCfg,
+			timeout:  p.GetTimeout(cfg.GetTimeout()),
+			rpm:      p.GetRequestsPerMinute(cfg.MaxRequestsPerMinute),
+		})
+	}
+	return chain
+}
+
 // createProvider creates the appropriate provider based on config type
 func createProvider(cfg *aiconfig.AIConfig) providers.Provider {
 	switch cfg.GetProviderType() {
-	case aiconfig.ProviderTypeOpenAI:
+	case aiconfig.ProviderTypeOpenAI, aiconfig.ProviderTypeOllama:
 		return providers.NewOpenAIProvider(cfg)
 	case aiconfig.ProviderTypeAnthropic:
 		return providers.NewClaudeProvider(cfg)
@@ -70,17 +114,19 @@ func createProvider(cfg *aiconfig.AIConfig) providers.Provider {
 // NewServiceWithConfig creates a service with explicit config (for testing)
 func NewServiceWithConfig(cfg *aiconfig.AIConfig) *Service {
 	s := &Service{
-		config:  cfg,
-		cache:   cache.NewMemoryCache(),
-		metrics: metrics.NewTracker(),
-		prompts: prompts.NewBuilder(),
-	}
-
-	if cfg.IsReady() {
-		s.primaryProvider = createProvider(cfg)
+		config:           cfg,
+		chain:            buildChain(cfg),
+		fallbackProvider: providers.NewFallbackProvider(),
+		cache:            cache.NewMemoryCache(),
+		pools:            cache.NewPool(nil),
+		metrics:          metrics.NewTracker(),
+		limits:           ratelimit.NewLimiter(),
+		players:          ratelimit.NewLimiter(),
+		prompts:          prompts.NewBuilder(),
+		profiles:         make(map[string]aiconfig.TemplateProfile),
+		refilling:        make(map[cache.Key]bool),
 	}
-	s.fallbackProvider = providers.NewFallbackProvider()
-
+	s.registerBuiltins()
 	return s
 }
 
@@ -95,23 +141,38 @@ func (s *Service) ReloadConfig(ctx context.Context) error {
 		return err
 	}
 
-	s.config = cfg
+	// Recreate the chain with the new config
+	chain := buildChain(cfg)
 
-	// Recreate primary provider with new config
-	if cfg.IsReady() {
-		s.primaryProvider = createProvider(cfg)
-	} else {
-		s.primaryProvider = nil
-	}
+	s.mu.Lock()
+	s.config = cfg
+	s.chain = chain
+	s.mu.Unlock()
 
 	return nil
 }
 
+// snapshot returns the config and chain in use, safe against a concurrent reload
+func (s *Service) snapshot() (*aiconfig.AIConfig, []link) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config, s.chain
+}
+
 // startCacheCleanup runs periodic cache cleanup
 func (s *Service) startCacheCleanup() {
 	ticker := time.NewTicker(10 * time.Minute)
 	for range ticker.C {
-		s.cache.Clean()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := s.cache.Clean(ctx); err != nil {
+			log.Printf("AI cache cleanup failed: %v", err)
+		}
+		if err := s.pools.Clean(ctx); err != nil {
+			log.Printf("AI pool cleanup failed: %v", err)
+		}
+		s.limits.Prune()
+		s.players.Prune()
+		cancel()
 	}
 }
 
@@ -120,11 +181,77 @@ func (s *Service) RegisterTemplate(name, template string) error {
 	return s.prompts.RegisterTemplate(name, template)
 }
 
+// registerBuiltins registers the templates of the game itself. Their profiles
+// can be tuned with ai_templates parameters like any other.
+func (s *Service) registerBuiltins() {
+	if err := s.RegisterTemplate("customer_line", prompts.CustomerLine); err != nil {
+		log.Printf("Warning: Failed to register template: %v", err)
+	}
+	s.SetProfile("customer_line", aiconfig.TemplateProfile{
+		SystemPrompt:    prompts.CustomerLineSystem,
+		MaxTokens:       40,
+		Temperature:     0.9,
+		Cache:           aiconfig.CachePool,
+		CacheTTLMinutes: 7 * 24 * 60,
+	})
+}
+
+// SetProfile sets the default generation settings of a template. An
+// ai_templates parameter with the same code overrides them.
+func (s *Service) SetProfile(name string, profile aiconfig.TemplateProfile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles[name] = profile
+}
+
+// profile returns the settings of a template: its registered profile with the
+// database one on top
+func (s *Service) profile(cfg *aiconfig.AIConfig, name string) aiconfig.TemplateProfile {
+	s.mu.RLock()
+	p := s.profiles[name]
+	s.mu.RUnlock()
+	return p.Merge(cfg.Templates[name])
+}
+
+// route moves the profile's provider to the front of the chain, on the
+// profile's model. The rest of the chain stays behind it as failover.
+func route(chain []link, p aiconfig.TemplateProfile) []link {
+	if len(chain) == 0 || (p.Provider == "" && p.Model == "") {
+		return chain
+	}
+	target := p.Provider
+	if target == "" {
+		target = chain[0].name
+	}
+
+	routed := make([]link, 0, len(chain))
+	for _, l := range chain {
+		if l.name != target {
+			continue
+		}
+		if p.Model != "" && l.config != nil && p.Model != l.config.Model {
+			cfg := *l.config
+			cfg.Model = p.Model
+			l.config = &cfg
+			l.provider = createProvider(&cfg)
+		}
+		routed = append(routed, l)
+	}
+	for _, l := range chain {
+		if l.name != target {
+			routed = append(routed, l)
+		}
+	}
+	return routed
+}
+
 // GenerateText is the high-level method to generate text
 func (s *Service) GenerateText(ctx context.Context, templateName string, data interface{}, config providers.Config) (*providers.GenerateResponse, error) {
+	cfg, chain := s.snapshot()
+
 	// Check if AI is enabled
-	if !s.config.Enabled {
-		if s.config.FallbackEnabled {
+	if !cfg.Enabled {
+		if cfg.FallbackEnabled {
 			return s.fallbackProvider.Generate(ctx, providers.GenerateRequest{
 				UserPrompt: templateName,
 				Config:     config,
@@ -133,73 +260,155 @@ func (s *Service) GenerateText(ctx context.Context, templateName string, data in
 		return nil, fmt.Errorf("AI is disabled")
 	}
 
+	req, profile, err := s.buildRequest(cfg, templateName, data, config)
+	if err != nil {
+		return nil, err
+	}
+
+	useCache := cfg.CacheEnabledFor(profile)
+	cacheKey := cache.Key{PromptType: s.promptType(templateName), Hash: cache.GenerateKey(req.SystemPrompt, req.UserPrompt)}
+
+	// Check Cache. Pool templates asked for without a bucket (GenerateLine)
+	// are cached per prompt like any other: refilling a pool for every
+	// distinct prompt would cost more calls than it saves.
+	if useCache {
+		if entry, found := s.cache.Get(ctx, cacheKey); found {
+			s.metrics.RecordCacheHit(cacheKey.PromptType, entry.Tokens)
+			return &providers.GenerateResponse{
+				Text:      entry.Value,
+				Cached:    true,
+				ModelName: "cache",
+			}, nil
+		}
+		s.metrics.RecordCacheMiss()
+	}
+
+	resp, err := s.generate(ctx, cfg, route(chain, profile), req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update Cache (canned fallback lines aren't worth keeping)
+	if useCache && !resp.Cached && resp.ProviderName != s.fallbackProvider.Name() {
+		if err := s.cache.Set(ctx, cacheKey, resp.Text, resp.Usage.TotalTokens, cfg.CacheTTLFor(profile)); err != nil {
+			log.Printf("AI cache write failed: %v", err)
+		}
+	}
+
+	return resp, nil
+}
+
+// promptType is the cache's name for a prompt: its template, or "raw"
+func (s *Service) promptType(templateName string) string {
+	if s.prompts.Has(templateName) {
+		return templateName
+	}
+	return "raw"
+}
+
+// buildRequest renders the template and applies its profile
+func (s *Service) buildRequest(cfg *aiconfig.AIConfig, templateName string, data interface{}, config providers.Config) (providers.GenerateRequest, aiconfig.TemplateProfile, error) {
 	// Build Prompt
 	userPrompt, err := s.prompts.Build(templateName, data)
 	if err != nil || userPrompt == "" {
 		// Try treating as raw string
 		userPrompt, err = s.prompts.SimpleBuild(templateName, data)
 		if err != nil {
-			return nil, fmt.Errorf("prompt build failed: %w", err)
+			return providers.GenerateRequest{}, aiconfig.TemplateProfile{}, fmt.Errorf("prompt build failed: %w", err)
 		}
 	}
 
-	systemPrompt := "" // Could be configured per template
+	profile := s.profile(cfg, templateName)
 
-	// Check Cache
-	cacheKey := s.cache.GenerateKey(systemPrompt, userPrompt)
-	if s.config.CacheEnabled {
-		if val, found := s.cache.Get(cacheKey); found {
-			return &providers.GenerateResponse{
-				Text:      val,
-				Cached:    true,
-				ModelName: "cache",
-			}, nil
-		}
+	// The caller's settings win over the template's
+	if config.MaxTokens == 0 {
+		config.MaxTokens = profile.MaxTokens
+	}
+	if config.Temperature == 0 {
+		config.Temperature = profile.Temperature
 	}
 
-	req := providers.GenerateRequest{
-		SystemPrompt: systemPrompt,
+	return providers.GenerateRequest{
+		SystemPrompt: profile.SystemPrompt,
 		UserPrompt:   userPrompt,
 		Config:       config,
-	}
+	}, profile, nil
+}
 
-	// Apply timeout from config
-	if s.config.TimeoutSeconds > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, s.config.GetTimeout())
-		defer cancel()
+// generate tries each provider of the chain in order, each with its own
+// timeout and rate limit, and ends in the canned fallback. A provider without
+// a free slot is skipped after queueing up to MaxQueueSeconds; a player over
+// their limit goes straight to the fallback. The response says who served it.
+func (s *Service) generate(ctx context.Context, cfg *aiconfig.AIConfig, chain []link, req providers.GenerateRequest) (*providers.GenerateResponse, error) {
+	var lastErr error
+	if player := playerFrom(ctx); player != "" {
+		if err := s.players.Wait(ctx, player, cfg.PlayerRequestsPerMinute, cfg.GetQueueWait()); err != nil {
+			s.metrics.RecordRateLimited("player")
+			lastErr = fmt.Errorf("player %s: %w", player, err)
+			chain = nil
+		}
 	}
 
-	// Try Primary Provider
-	var resp *providers.GenerateResponse
-	var tokenErr error
+	for i, l := range chain {
+		if ctx.Err() != nil {
+			lastErr = ctx.Err()
+			break
+		}
+		if !l.provider.IsAvailable(ctx) {
+			continue
+		}
+		if err := s.limits.Wait(ctx, l.name, l.rpm, cfg.GetQueueWait()); err != nil {
+			s.metrics.RecordRateLimited(l.name)
+			lastErr = fmt.Errorf("%s: %w", l.name, err)
+			continue
+		}
 
-	if s.primaryProvider != nil && s.primaryProvider.IsAvailable(ctx) {
-		resp, tokenErr = s.primaryProvider.Generate(ctx, req)
-		if tokenErr == nil {
-			s.metrics.RecordRequest(s.primaryProvider.Name(), resp.Usage.TotalTokens)
-		} else {
-			s.metrics.RecordError(s.primaryProvider.Name())
+		resp, err := s.try(ctx, l, req)
+		if err != nil {
+			log.Printf("AI provider %s failed: %v", l.name, err)
+			lastErr = err
+			continue
+		}
+		if i > 0 {
+			s.metrics.RecordFailover()
 		}
+		return resp, nil
 	}
 
-	// Fallback if needed
-	if (resp == nil || tokenErr != nil) && s.config.FallbackEnabled {
-		resp, err = s.fallbackProvider.Generate(ctx, req)
-		if err != nil {
-			s.metrics.RecordError(s.fallbackProvider.Name())
-			return nil, fmt.Errorf("all providers failed: %w", err)
+	if !cfg.FallbackEnabled {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no provider available")
 		}
-		s.metrics.RecordRequest(s.fallbackProvider.Name(), resp.Usage.TotalTokens)
-	} else if resp == nil {
-		return nil, fmt.Errorf("primary provider failed and fallback disabled: %w", tokenErr)
+		return nil, fmt.Errorf("all providers failed and fallback disabled: %w", lastErr)
 	}
 
-	// Update Cache
-	if s.config.CacheEnabled && resp != nil && !resp.Cached {
-		s.cache.Set(cacheKey, resp.Text, s.config.GetCacheTTL())
+	resp, err := s.fallbackProvider.Generate(ctx, req)
+	if err != nil {
+		s.metrics.RecordError(s.fallbackProvider.Name())
+		return nil, fmt.Errorf("all providers failed: %w", err)
+	}
+	if len(chain) > 0 || lastErr != nil {
+		s.metrics.RecordFailover()
+	}
+	s.metrics.RecordRequest(s.fallbackProvider.Name(), resp.Usage.TotalTokens)
+	return resp, nil
+}
+
+// try calls one provider within its timeout
+func (s *Service) try(ctx context.Context, l link, req providers.GenerateRequest) (*providers.GenerateResponse, error) {
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
 	}
 
+	resp, err := l.provider.Generate(ctx, req)
+	if err != nil {
+		s.metrics.RecordError(l.name)
+		return nil, err
+	}
+	resp.ProviderName = l.name
+	s.metrics.RecordRequest(l.name, resp.Usage.TotalTokens)
 	return resp, nil
 }
 
@@ -210,16 +419,25 @@ func (s *Service) GetMetrics() map[string]interface{} {
 
 // GetConfig returns current configuration (without sensitive data)
 func (s *Service) GetConfig() map[string]interface{} {
+	cfg, chain := s.snapshot()
+
+	names := make([]string, len(chain))
+	for i, l := range chain {
+		names[i] = l.name
+	}
+
 	return map[string]interface{}{
-		"enabled":       s.config.Enabled,
-		"model":         s.config.Model,
-		"max_tokens":    s.config.MaxTokens,
-		"cache_enabled": s.config.CacheEnabled,
-		"is_ready":      s.config.IsReady(),
+		"enabled":       cfg.Enabled,
+		"model":         cfg.Model,
+		"max_tokens":    cfg.MaxTokens,
+		"cache_enabled": cfg.CacheEnabled,
+		"providers":     names,
+		"is_ready":      cfg.IsReady(),
 	}
 }
 
 // IsReady returns true if AI is properly configured
 func (s *Service) IsReady() bool {
-	return s.config.IsReady()
+	cfg, _ := s.snapshot()
+	return cfg.IsReady()
 }
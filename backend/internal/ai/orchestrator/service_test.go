@@ -0,0 +1,387 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/ai/cache"
+	aiconfig "github.com/alonsoalpizar/calleviva/backend/internal/ai/config"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ai/providers"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ai/ratelimit"
+)
+
+// stubProvider answers with text, or fails with err
+type stubProvider struct {
+	text  string
+	err   error
+	calls int
+	last  providers.GenerateRequest
+}
+
+func (p *stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) IsAvailable(ctx context.Context) bool { return true }
+
+func (p *stubProvider) Generate(ctx context.Context, req providers.GenerateRequest) (*providers.GenerateResponse, error) {
+	p.calls++
+	p.last = req
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &providers.GenerateResponse{Text: p.text, Usage: providers.UsageStats{TotalTokens: 7}}, nil
+}
+
+func testService(fallback bool, chain ...link) *Service {
+	cfg := aiconfig.DefaultConfig()
+	cfg.Enabled = true
+	cfg.CacheEnabled = false
+	cfg.FallbackEnabled = fallback
+
+	s := NewServiceWithConfig(cfg)
+	s.chain = chain
+	return s
+}
+
+func TestGenerateTextFailsOver(t *testing.T) {
+	anthropic := &stubProvider{err: errors.New("529 overloaded")}
+	groq := &stubProvider{text: "¡Pura vida!"}
+	ollama := &stubProvider{text: "local"}
+	s := testService(true,
+		link{name: "anthropic", provider: anthropic},
+		link{name: "groq", provider: groq},
+		link{name: "ollama", provider: ollama},
+	)
+
+	resp, err := s.GenerateText(context.Background(), "Hola", nil, providers.Config{})
+	if err != nil {
+		t.Fatalf("GenerateText: %v", err)
+	}
+	if resp.Text != "¡Pura vida!" || resp.ProviderName != "groq" {
+		t.Errorf("served by %s with %q, want groq", resp.ProviderName, resp.Text)
+	}
+	if anthropic.calls != 1 || ollama.calls != 0 {
+		t.Errorf("calls: anthropic %d, ollama %d; want 1, 0", anthropic.calls, ollama.calls)
+	}
+
+	stats := s.GetMetrics()
+	if stats["failovers"].(int64) != 1 {
+		t.Errorf("failovers = %v, want 1", stats["failovers"])
+	}
+	if stats["errors_by_model"].(map[string]int64)["anthropic"] != 1 {
+		t.Errorf("anthropic errors = %v, want 1", stats["errors_by_model"])
+	}
+}
+
+func TestGenerateTextEndsInFallback(t *testing.T) {
+	s := testService(true, link{name: "anthropic", provider: &stubProvider{err: errors.New("down")}})
+
+	resp, err := s.GenerateText(context.Background(), "Hola", nil, providers.Config{})
+	if err != nil {
+		t.Fatalf("GenerateText: %v", err)
+	}
+	if resp.ProviderName != "fallback" {
+		t.Errorf("served by %s, want fallback", resp.ProviderName)
+	}
+}
+
+func TestGenerateTextWithoutFallback(t *testing.T) {
+	s := testService(false, link{name: "anthropic", provider: &stubProvider{err: errors.New("down")}})
+
+	if _, err := s.GenerateText(context.Background(), "Hola", nil, providers.Config{}); err == nil {
+		t.Error("expected an error when every provider fails and fallback is off")
+	}
+}
+
+func TestBuildChainSkipsUnreadyProviders(t *testing.T) {
+	cfg := aiconfig.DefaultConfig()
+	cfg.Enabled = true
+	cfg.Providers = []aiconfig.ProviderConfig{
+		{Name: "anthropic", Type: aiconfig.ProviderTypeAnthropic, URL: "https://api.anthropic.com/v1/messages", Model: "claude", Enabled: true},
+		{Name: "groq", Type: aiconfig.ProviderTypeOpenAI, URL: "https://api.groq.com/openai/v1/chat/completions", Model: "llama", Enabled: true, APIKey: "gsk", TimeoutSeconds: 5},
+		{Name: "ollama", Type: aiconfig.ProviderTypeOllama, URL: "http://localhost:11434/v1/chat/completions", Model: "llama3", Enabled: true},
+	}
+
+	chain := buildChain(cfg)
+	if len(chain) != 2 || chain[0].name != "groq" || chain[1].name != "ollama" {
+		t.Fatalf("chain = %+v, want groq then ollama (anthropic has no key)", chain)
+	}
+	if chain[0].timeout.Seconds() != 5 || chain[1].timeout != cfg.GetTimeout() {
+		t.Errorf("timeouts = %v, %v; want 5s and the default", chain[0].timeout, chain[1].timeout)
+	}
+}
+
+func TestGenerateTextUsesTemplateProfile(t *testing.T) {
+	main := &stubProvider{text: "main"}
+	fast := &stubProvider{text: "fast"}
+	s := testService(true,
+		link{name: "anthropic", provider: main},
+		link{name: "groq", provider: fast},
+	)
+	if err := s.RegisterTemplate("customer_line", "Producto: {{.Product}}"); err != nil {
+		t.Fatal(err)
+	}
+	s.SetProfile("customer_line", aiconfig.TemplateProfile{
+		SystemPrompt: "Sos un cliente tico",
+		Provider:     "groq",
+		MaxTokens:    30,
+		Temperature:  0.8,
+	})
+
+	resp, err := s.GenerateText(context.Background(), "customer_line", map[string]string{"Product": "churchill"}, providers.Config{MaxTokens: 20})
+	if err != nil {
+		t.Fatalf("GenerateText: %v", err)
+	}
+	if resp.ProviderName != "groq" || main.calls != 0 {
+		t.Errorf("served by %s (anthropic calls %d), want groq first", resp.ProviderName, main.calls)
+	}
+	if fast.last.SystemPrompt != "Sos un cliente tico" || fast.last.UserPrompt != "Producto: churchill" {
+		t.Errorf("request = %+v", fast.last)
+	}
+	// The caller's max tokens win, the profile fills the temperature
+	if fast.last.Config.MaxTokens != 20 || fast.last.Config.Temperature != 0.8 {
+		t.Errorf("config = %+v, want 20 tokens at 0.8", fast.last.Config)
+	}
+}
+
+func TestProfileFromDatabaseOverridesRegistered(t *testing.T) {
+	s := testService(true)
+	s.config.Templates = map[string]aiconfig.TemplateProfile{
+		"dish_generation": {Model: "creative", Cache: aiconfig.CacheOff},
+	}
+	s.SetProfile("dish_generation", aiconfig.TemplateProfile{MaxTokens: 800, Temperature: 0.9})
+
+	p := s.profile(s.config, "dish_generation")
+	if p.MaxTokens != 800 || p.Model != "creative" || s.config.CacheEnabledFor(p) {
+		t.Errorf("profile = %+v", p)
+	}
+}
+
+func TestRouteSwapsModel(t *testing.T) {
+	cfg := aiconfig.DefaultConfig()
+	cfg.Enabled = true
+	cfg.Providers = []aiconfig.ProviderConfig{
+		{Name: "anthropic", Type: aiconfig.ProviderTypeAnthropic, URL: "u", Model: "sonnet", APIKey: "k", Enabled: true},
+		{Name: "ollama", Type: aiconfig.ProviderTypeOllama, URL: "u", Model: "llama3", Enabled: true},
+	}
+	chain := buildChain(cfg)
+
+	routed := route(chain, aiconfig.TemplateProfile{Model: "haiku"})
+	if len(routed) != 2 || routed[0].name != "anthropic" || routed[0].config.Model != "haiku" {
+		t.Fatalf("routed = %+v", routed)
+	}
+	if chain[0].config.Model != "sonnet" {
+		t.Error("route must not change the shared chain")
+	}
+
+	routed = route(chain, aiconfig.TemplateProfile{Provider: "ollama"})
+	if routed[0].name != "ollama" || routed[1].name != "anthropic" {
+		t.Errorf("order = %s, %s; want ollama first", routed[0].name, routed[1].name)
+	}
+}
+
+func TestGenerateTextCachesAndCountsSavings(t *testing.T) {
+	p := &stubProvider{text: "¡Tuanis!"}
+	s := testService(true, link{name: "anthropic", provider: p})
+	s.config.CacheEnabled = true
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.GenerateText(ctx, "Hola", nil, providers.Config{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if p.calls != 1 {
+		t.Errorf("provider called %d times, want 1", p.calls)
+	}
+
+	stats := s.GetMetrics()
+	if stats["cache_hits"].(int64) != 2 || stats["tokens_saved"].(int64) != 14 {
+		t.Errorf("cache hits %v, tokens saved %v; want 2 and 14", stats["cache_hits"], stats["tokens_saved"])
+	}
+	if stats["cache_hits_by_type"].(map[string]int64)["raw"] != 2 {
+		t.Errorf("hits by type = %v", stats["cache_hits_by_type"])
+	}
+}
+
+func TestGenerateTextDoesNotCacheFallback(t *testing.T) {
+	p := &stubProvider{err: errors.New("down")}
+	s := testService(true, link{name: "anthropic", provider: p})
+	s.config.CacheEnabled = true
+	ctx := context.Background()
+
+	s.GenerateText(ctx, "Hola", nil, providers.Config{})
+	s.GenerateText(ctx, "Hola", nil, providers.Config{})
+	if p.calls != 2 {
+		t.Errorf("provider called %d times, want 2 (fallback lines aren't cached)", p.calls)
+	}
+}
+
+// seqProvider answers a different line every call
+type seqProvider struct {
+	calls int
+}
+
+func (p *seqProvider) Name() string { return "seq" }
+
+func (p *seqProvider) IsAvailable(ctx context.Context) bool { return true }
+
+func (p *seqProvider) Generate(ctx context.Context, req providers.GenerateRequest) (*providers.GenerateResponse, error) {
+	p.calls++
+	return &providers.GenerateResponse{Text: fmt.Sprintf("línea %d", p.calls), Usage: providers.UsageStats{TotalTokens: 9}}, nil
+}
+
+func TestGenerateLineFillsAndServesThePool(t *testing.T) {
+	p := &seqProvider{}
+	s := testService(true, link{name: "groq", provider: p})
+	s.config.CacheEnabled = true
+	s.config.Templates = map[string]aiconfig.TemplateProfile{"customer_line": {PoolSize: 5}}
+	ctx := context.Background()
+	bucket := Bucket("Churchill", "happy", "sunny", "noon")
+	data := map[string]interface{}{"Producto": "Churchill", "Precio": 1500, "Satisfaccion": 9, "Clima": "soleado", "Hora": "mediodía"}
+
+	resp, err := s.GenerateLine(ctx, "customer_line", bucket, data, providers.Config{})
+	if err != nil {
+		t.Fatalf("GenerateLine: %v", err)
+	}
+	if resp.Cached || resp.Text != "línea 1" {
+		t.Fatalf("first line should be generated, got cached=%v", resp.Cached)
+	}
+	s.refills.Wait()
+	if p.calls != 5 {
+		t.Errorf("provider called %d times, want 5 (pool topped up to its size)", p.calls)
+	}
+
+	for i := 0; i < 20; i++ {
+		resp, err := s.GenerateLine(ctx, "customer_line", bucket, data, providers.Config{})
+		if err != nil || !resp.Cached {
+			t.Fatalf("line %d: cached=%v err=%v", i, resp != nil && resp.Cached, err)
+		}
+	}
+	s.refills.Wait()
+	if p.calls != 5 {
+		t.Errorf("full pool still called the provider: %d calls", p.calls)
+	}
+	if hits := s.GetMetrics()["cache_hits_by_type"].(map[string]int64)["customer_line"]; hits != 20 {
+		t.Errorf("customer_line hits = %d, want 20", hits)
+	}
+}
+
+func TestGenerateTextCachesPoolTemplatesWithoutRefills(t *testing.T) {
+	p := &seqProvider{}
+	s := testService(true, link{name: "groq", provider: p})
+	s.config.CacheEnabled = true
+	ctx := context.Background()
+	data := map[string]interface{}{"Producto": "Churchill", "Precio": 1500, "Satisfaccion": 9, "Clima": "soleado", "Hora": "mediodía"}
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.GenerateText(ctx, "customer_line", data, providers.Config{}); err != nil {
+			t.Fatalf("GenerateText: %v", err)
+		}
+	}
+	s.refills.Wait()
+	if p.calls != 1 {
+		t.Errorf("provider called %d times, want 1 (cached, no pool refills)", p.calls)
+	}
+}
+
+func TestGenerateLineKeepsFallbackOutOfThePool(t *testing.T) {
+	s := testService(true, link{name: "groq", provider: &stubProvider{err: errors.New("down")}})
+	s.config.CacheEnabled = true
+	ctx := context.Background()
+
+	resp, err := s.GenerateLine(ctx, "customer_line", "churchill_sad_rainy_night", nil, providers.Config{})
+	if err != nil {
+		t.Fatalf("GenerateLine: %v", err)
+	}
+	s.refills.Wait()
+	if resp.ProviderName != "fallback" {
+		t.Errorf("served by %s, want fallback", resp.ProviderName)
+	}
+	if n := s.pools.Size(ctx, cache.Key{PromptType: "customer_line", Hash: "churchill_sad_rainy_night"}); n != 0 {
+		t.Errorf("pool has %d lines, want 0", n)
+	}
+}
+
+func TestBucket(t *testing.T) {
+	if got := Bucket(" Churchill ", "HAPPY", "", "sunny", "late night"); got != "churchill_happy_sunny_late-night" {
+		t.Errorf("Bucket = %q", got)
+	}
+}
+
+func TestGenerateTextSkipsRateLimitedProvider(t *testing.T) {
+	anthropic := &stubProvider{text: "anthropic"}
+	groq := &stubProvider{text: "groq"}
+	s := testService(true,
+		link{name: "anthropic", provider: anthropic, rpm: 1},
+		link{name: "groq", provider: groq},
+	)
+	s.config.MaxQueueSeconds = 0
+	ctx := context.Background()
+
+	for _, want := range []string{"anthropic", "groq"} {
+		resp, err := s.GenerateText(ctx, "Hola", nil, providers.Config{})
+		if err != nil {
+			t.Fatalf("GenerateText: %v", err)
+		}
+		if resp.ProviderName != want {
+			t.Errorf("served by %s, want %s", resp.ProviderName, want)
+		}
+	}
+	if anthropic.calls != 1 {
+		t.Errorf("anthropic called %d times over its limit, want 1", anthropic.calls)
+	}
+	if n := s.GetMetrics()["rate_limited"].(map[string]int64)["anthropic"]; n != 1 {
+		t.Errorf("anthropic rate_limited = %d, want 1", n)
+	}
+}
+
+func TestGenerateTextDegradesToFallbackWhenLimited(t *testing.T) {
+	p := &stubProvider{text: "groq"}
+	s := testService(true, link{name: "groq", provider: p, rpm: 1})
+	s.config.MaxQueueSeconds = 0
+	ctx := context.Background()
+
+	s.GenerateText(ctx, "Hola", nil, providers.Config{})
+	resp, err := s.GenerateText(ctx, "Hola", nil, providers.Config{})
+	if err != nil {
+		t.Fatalf("GenerateText: %v", err)
+	}
+	if resp.ProviderName != "fallback" || p.calls != 1 {
+		t.Errorf("served by %s after %d calls, want fallback after 1", resp.ProviderName, p.calls)
+	}
+
+	s.config.FallbackEnabled = false
+	if _, err := s.GenerateText(ctx, "Hola", nil, providers.Config{}); !errors.Is(err, ratelimit.ErrLimited) {
+		t.Errorf("without fallback: %v, want ErrLimited", err)
+	}
+}
+
+func TestGenerateTextLimitsEachPlayer(t *testing.T) {
+	p := &stubProvider{text: "groq"}
+	s := testService(true, link{name: "groq", provider: p})
+	s.config.PlayerRequestsPerMinute = 2
+	s.config.MaxQueueSeconds = 0
+	ctx := WithPlayer(context.Background(), "player-1")
+
+	var served []string
+	for i := 0; i < 3; i++ {
+		resp, err := s.GenerateText(ctx, "Hola", nil, providers.Config{})
+		if err != nil {
+			t.Fatalf("GenerateText: %v", err)
+		}
+		served = append(served, resp.ProviderName)
+	}
+	if served[0] != "groq" || served[1] != "groq" || served[2] != "fallback" {
+		t.Errorf("served by %v, want groq, groq, fallback", served)
+	}
+
+	resp, err := s.GenerateText(WithPlayer(context.Background(), "player-2"), "Hola", nil, providers.Config{})
+	if err != nil || resp.ProviderName != "groq" {
+		t.Errorf("another player: %v, want groq", err)
+	}
+	if n := s.GetMetrics()["rate_limited"].(map[string]int64)["player"]; n != 1 {
+		t.Errorf("player rate_limited = %d, want 1", n)
+	}
+}
@@ -27,6 +27,12 @@ func (b *PromptBuilder) RegisterTemplate(name, tmplString string) error {
 	return nil
 }
 
+// Has tells whether a template is registered under name
+func (b *PromptBuilder) Has(name string) bool {
+	_, ok := b.templates[name]
+	return ok
+}
+
 // Build constructs a prompt using a registered template and data
 func (b *PromptBuilder) Build(templateName string, data interface{}) (string, error) {
 	tmpl, ok := b.templates[templateName]
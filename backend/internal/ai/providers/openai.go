@@ -37,7 +37,11 @@ func (p *OpenAIProvider) Name() string {
 }
 
 func (p *OpenAIProvider) IsAvailable(ctx context.Context) bool {
-	return p.config != nil && p.config.APIKey != "" && p.config.Enabled
+	if p.config == nil || !p.config.Enabled {
+		return false
+	}
+	// Ollama runs locally without a key
+	return p.config.APIKey != "" || p.config.GetProviderType() == aiconfig.ProviderTypeOllama
 }
 
 // Generate implements the generation logic for OpenAI-compatible APIs
@@ -103,7 +107,9 @@ func (p *OpenAIProvider) Generate(ctx context.Context, req GenerateRequest) (*Ge
 	}
 
 	// Standard Bearer auth for OpenAI-compatible APIs
-	httpRequest.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	if p.config.APIKey != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
 	httpRequest.Header.Set("Content-Type", "application/json")
 
 	resp, err := p.client.Do(httpRequest)
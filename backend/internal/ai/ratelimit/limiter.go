@@ -0,0 +1,173 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLimited means no request slot frees up before the caller's deadline
+var ErrLimited = errors.New("rate limit reached")
+
+// Bucket is a token bucket: perMinute tokens a minute, holding at most a
+// minute's worth
+type Bucket struct {
+	perMinute int
+	tokens    float64
+	last      time.Time
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+// NewBucket creates a full bucket
+func NewBucket(perMinute int) *Bucket {
+	return newBucket(perMinute, time.Now)
+}
+
+func newBucket(perMinute int, now func() time.Time) *Bucket {
+	return &Bucket{perMinute: perMinute, tokens: float64(perMinute), last: now(), now: now}
+}
+
+// Reserve takes a token, now or from the future. It returns how long the
+// caller has to wait for it, or false when that is longer than maxWait (and
+// then nothing is taken).
+func (b *Bucket) Reserve(maxWait time.Duration) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+
+	wait := time.Duration((1 - b.tokens) * float64(time.Minute) / float64(b.perMinute))
+	if wait > maxWait {
+		return wait, false
+	}
+	b.tokens--
+	return wait, true
+}
+
+// Wait takes a token, queueing up to maxWait (or the context's deadline, if
+// sooner) for one
+func (b *Bucket) Wait(ctx context.Context, maxWait time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < maxWait {
+		maxWait = time.Until(deadline)
+	}
+
+	wait, ok := b.Reserve(maxWait)
+	if !ok {
+		return ErrLimited
+	}
+	if wait == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.release()
+		return ctx.Err()
+	}
+}
+
+// SetRate changes the bucket's rate, keeping its tokens
+func (b *Bucket) SetRate(perMinute int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	b.perMinute = perMinute
+	if b.tokens > float64(perMinute) {
+		b.tokens = float64(perMinute)
+	}
+}
+
+// full tells whether the bucket has refilled completely
+func (b *Bucket) full() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	return b.tokens >= float64(b.perMinute)
+}
+
+// release gives back a reserved token that wasn't used
+func (b *Bucket) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens++
+}
+
+// refill adds the tokens earned since the last call. Callers hold mu.
+func (b *Bucket) refill() {
+	now := b.now()
+	elapsed := now.Sub(b.last)
+	b.last = now
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens += elapsed.Minutes() * float64(b.perMinute)
+	if b.tokens > float64(b.perMinute) {
+		b.tokens = float64(b.perMinute)
+	}
+}
+
+// Limiter keeps a bucket per key (a provider, a player)
+type Limiter struct {
+	buckets map[string]*Bucket
+	mu      sync.Mutex
+	now     func() time.Time
+}
+
+// NewLimiter creates an empty limiter
+func NewLimiter() *Limiter {
+	return &Limiter{buckets: make(map[string]*Bucket), now: time.Now}
+}
+
+// Wait takes a slot of key's budget of perMinute requests, queueing up to
+// maxWait for one. perMinute <= 0 means no limit.
+func (l *Limiter) Wait(ctx context.Context, key string, perMinute int, maxWait time.Duration) error {
+	if perMinute <= 0 {
+		return nil
+	}
+	return l.bucket(key, perMinute).Wait(ctx, maxWait)
+}
+
+// Prune forgets the buckets that have refilled, so idle keys don't pile up
+func (l *Limiter) Prune() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, b := range l.buckets {
+		if b.full() {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// bucket returns key's bucket at the current rate
+func (l *Limiter) bucket(key string, perMinute int) *Bucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = newBucket(perMinute, l.now)
+		l.buckets[key] = b
+		return b
+	}
+	b.mu.Lock()
+	changed := b.perMinute != perMinute
+	b.mu.Unlock()
+	if changed {
+		b.SetRate(perMinute)
+	}
+	return b
+}
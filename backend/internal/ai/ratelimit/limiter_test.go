@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// clock is a time source the test moves by hand
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func TestBucketSpendsThenRefills(t *testing.T) {
+	c := &clock{t: time.Now()}
+	b := newBucket(60, c.now)
+
+	for i := 0; i < 60; i++ {
+		if wait, ok := b.Reserve(0); !ok || wait != 0 {
+			t.Fatalf("request %d: wait %v, ok %v; want a free slot", i+1, wait, ok)
+		}
+	}
+	if wait, ok := b.Reserve(0); ok {
+		t.Fatalf("request 61 got a slot, want none (wait %v)", wait)
+	}
+
+	// 60/min is a token a second
+	if wait, ok := b.Reserve(2 * time.Second); !ok || wait != time.Second {
+		t.Errorf("queued request: wait %v, ok %v; want 1s", wait, ok)
+	}
+
+	// The queued request borrowed a second's token
+	c.t = c.t.Add(time.Minute)
+	if b.full() {
+		t.Error("bucket is full before paying back the queued request")
+	}
+	c.t = c.t.Add(time.Second)
+	if !b.full() {
+		t.Error("bucket isn't full after a minute and a second")
+	}
+}
+
+func TestLimiterKeepsABucketPerKey(t *testing.T) {
+	ctx := context.Background()
+	l := NewLimiter()
+
+	if err := l.Wait(ctx, "anthropic", 1, 0); err != nil {
+		t.Fatalf("first anthropic request: %v", err)
+	}
+	if err := l.Wait(ctx, "anthropic", 1, 0); !errors.Is(err, ErrLimited) {
+		t.Errorf("second anthropic request: %v, want ErrLimited", err)
+	}
+	if err := l.Wait(ctx, "groq", 1, 0); err != nil {
+		t.Errorf("groq request: %v, want its own budget", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(ctx, "ollama", 0, 0); err != nil {
+			t.Fatalf("unlimited request: %v", err)
+		}
+	}
+}
+
+func TestLimiterQueuesUpToTheDeadline(t *testing.T) {
+	l := NewLimiter()
+	ctx := context.Background()
+
+	// 1200/min frees a slot every 50ms
+	for i := 0; i < 1200; i++ {
+		l.Wait(ctx, "groq", 1200, 0)
+	}
+	start := time.Now()
+	if err := l.Wait(ctx, "groq", 1200, time.Second); err != nil {
+		t.Fatalf("queued request: %v", err)
+	}
+	if waited := time.Since(start); waited < 25*time.Millisecond {
+		t.Errorf("queued request waited %v, want about 50ms", waited)
+	}
+
+	// A deadline sooner than the next slot turns the request away at once
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(short, "groq", 1200, time.Second); !errors.Is(err, ErrLimited) {
+		t.Errorf("request past its deadline: %v, want ErrLimited", err)
+	}
+}
+
+func TestLimiterPrunesIdleKeys(t *testing.T) {
+	c := &clock{t: time.Now()}
+	l := NewLimiter()
+	l.now = c.now
+
+	l.Wait(context.Background(), "player-1", 10, 0)
+	l.Prune()
+	if len(l.buckets) != 1 {
+		t.Fatalf("pruned a bucket in use")
+	}
+
+	c.t = c.t.Add(time.Minute)
+	l.Prune()
+	if len(l.buckets) != 0 {
+		t.Errorf("kept %d idle buckets, want 0", len(l.buckets))
+	}
+}
@@ -0,0 +1,168 @@
+package bankruptcy
+
+import (
+	"context"
+	"math"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/ingredients"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ledger"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Game statuses involved in the bankruptcy flow
+const (
+	StatusActive   = "active"
+	StatusBankrupt = "bankrupt"
+	StatusFinished = "finished"
+)
+
+// NegativeDaysLimit is how many days in a row with negative money trigger
+// the bankruptcy prompt (GDD)
+const NegativeDaysLimit = 3
+
+// Loan is the emergency loan offered by a world (countries.config.emergency_loan)
+type Loan struct {
+	Amount        int64   `json:"amount"`
+	Interest      float64 `json:"interest"`
+	RepaymentRate float64 `json:"repayment_rate"`
+}
+
+// DefaultLoan is used when a world has no emergency_loan configured
+var DefaultLoan = Loan{Amount: 10000, Interest: 0.10, RepaymentRate: 0.30}
+
+// TotalDue is what the player owes after taking the loan
+func (l Loan) TotalDue() int64 {
+	return int64(math.Round(float64(l.Amount) * (1 + l.Interest)))
+}
+
+// NegativeDays returns the new streak of negative days after a day closes
+// with the given money
+func NegativeDays(previous int, money int64) int {
+	if money < 0 {
+		return previous + 1
+	}
+	return 0
+}
+
+// StatusAfter returns the session status after a day closes
+func StatusAfter(status string, negativeDays int) string {
+	if negativeDays >= NegativeDaysLimit {
+		return StatusBankrupt
+	}
+	return status
+}
+
+// Repayment is how much of the day's profit goes to the outstanding loan.
+// Days without profit don't pay anything.
+func Repayment(profit, balance int64, rate float64) int64 {
+	if profit <= 0 || balance <= 0 || rate <= 0 {
+		return 0
+	}
+	pay := int64(math.Ceil(float64(profit) * rate))
+	return min(pay, balance)
+}
+
+// SaveSnapshot stores the session state before a day is simulated so it can
+// be restored with "reiniciar día"
+func SaveSnapshot(ctx context.Context, tx pgx.Tx, gameID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO day_snapshots
+		(session_id, game_day, money, reputation, weather, weather_forecast,
+		 current_location, location_day, negative_days, loan_balance, loan_repayment_rate, inventory)
+		SELECT g.id, g.game_day, g.money, g.reputation, g.weather, g.weather_forecast,
+		       g.current_location, g.location_day, COALESCE(g.negative_days, 0),
+		       COALESCE(g.loan_balance, 0), COALESCE(g.loan_repayment_rate, 0),
+		       (SELECT COALESCE(jsonb_agg(to_jsonb(i)), '[]'::jsonb) FROM inventory i WHERE i.session_id = g.id)
+		FROM game_sessions g
+		WHERE g.id = $1
+		ON CONFLICT (session_id, game_day) DO UPDATE SET
+			money = EXCLUDED.money,
+			reputation = EXCLUDED.reputation,
+			weather = EXCLUDED.weather,
+			weather_forecast = EXCLUDED.weather_forecast,
+			current_location = EXCLUDED.current_location,
+			location_day = EXCLUDED.location_day,
+			negative_days = EXCLUDED.negative_days,
+			loan_balance = EXCLUDED.loan_balance,
+			loan_repayment_rate = EXCLUDED.loan_repayment_rate,
+			inventory = EXCLUDED.inventory,
+			created_at = NOW()
+	`, gameID)
+	return err
+}
+
+// RepayLoan takes a day's repayment off the loan being paid, marking it
+// paid off once nothing is left
+func RepayLoan(ctx context.Context, tx pgx.Tx, gameID uuid.UUID, day int, amount int64) error {
+	if amount <= 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+		UPDATE loans
+		SET balance = GREATEST(balance - $3, 0),
+		    paid_off_day = CASE WHEN balance <= $3 THEN $2 END
+		WHERE session_id = $1 AND balance > 0
+	`, gameID, day, amount)
+	return err
+}
+
+// syncLoan puts the session's restored loan balance back on its last loan
+func syncLoan(ctx context.Context, tx pgx.Tx, gameID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE loans l
+		SET balance = COALESCE(g.loan_balance, 0),
+		    paid_off_day = CASE WHEN COALESCE(g.loan_balance, 0) > 0 THEN NULL ELSE l.paid_off_day END
+		FROM game_sessions g
+		WHERE g.id = $1 AND l.id = (
+			SELECT id FROM loans
+			WHERE session_id = $1
+			ORDER BY game_day DESC, created_at DESC, id
+			LIMIT 1
+		)
+	`, gameID)
+	return err
+}
+
+// forgetCreatorUses takes back the uses counted on creator ingredients by
+// the purchases made since a day snapshot. Must run before ledger.Rewind,
+// which forgets those purchases.
+func forgetCreatorUses(ctx context.Context, tx pgx.Tx, gameID uuid.UUID, day int) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE content_creations c
+		SET times_used = GREATEST(c.times_used - u.uses, 0)
+		FROM (
+			SELECT substring(t.reference FROM length($4) + 1)::uuid AS id, COUNT(*) AS uses
+			FROM money_transactions t
+			JOIN day_snapshots s ON s.session_id = t.session_id AND s.game_day = $2
+			WHERE t.session_id = $1 AND t.created_at >= s.created_at
+			  AND t.reason = $3 AND starts_with(t.reference, $4)
+			GROUP BY 1
+		) u
+		WHERE c.id = u.id
+	`, gameID, day, ledger.ReasonPurchase, ingredients.CreatorPrefix)
+	return err
+}
+
+// recountDishes recomputes the sales stats of the game's lab dishes from
+// sales_log, once the replayed days were cleared from it
+func recountDishes(ctx context.Context, tx pgx.Tx, gameID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE player_dishes d
+		SET times_sold = COALESCE(s.sold, 0),
+		    total_revenue = COALESCE(s.revenue, 0),
+		    avg_satisfaction = COALESCE(s.satisfaction, 0)
+		FROM player_dishes p
+		LEFT JOIN (
+			SELECT substring(product_type FROM 6)::uuid AS id,
+			       COUNT(*) AS sold,
+			       SUM(price_sold) AS revenue,
+			       ROUND(AVG(COALESCE(customer_satisfaction, 0)))::int AS satisfaction
+			FROM sales_log
+			WHERE session_id = $1 AND starts_with(product_type, 'dish:')
+			GROUP BY 1
+		) s ON s.id = p.id
+		WHERE d.id = p.id AND p.session_id = $1
+	`, gameID)
+	return err
+}
@@ -0,0 +1,55 @@
+package bankruptcy
+
+import "testing"
+
+func TestThreeNegativeDaysBankrupt(t *testing.T) {
+	days := 0
+	status := StatusActive
+	for _, money := range []int64{-100, -50, -10} {
+		if status == StatusBankrupt {
+			t.Fatalf("went bankrupt before %d negative days", NegativeDaysLimit)
+		}
+		days = NegativeDays(days, money)
+		status = StatusAfter(status, days)
+	}
+	if status != StatusBankrupt {
+		t.Errorf("expected bankrupt after 3 negative days, got %s (%d days)", status, days)
+	}
+}
+
+func TestPositiveDayResetsStreak(t *testing.T) {
+	days := NegativeDays(2, 0)
+	if days != 0 {
+		t.Errorf("expected streak reset, got %d", days)
+	}
+	if got := StatusAfter(StatusActive, days); got != StatusActive {
+		t.Errorf("expected active, got %s", got)
+	}
+}
+
+func TestRepayment(t *testing.T) {
+	tests := []struct {
+		name    string
+		profit  int64
+		balance int64
+		rate    float64
+		want    int64
+	}{
+		{"share of profit", 1000, 11000, 0.3, 300},
+		{"capped at balance", 1000, 120, 0.3, 120},
+		{"no profit", -500, 11000, 0.3, 0},
+		{"no loan", 1000, 0, 0.3, 0},
+		{"rounds up", 10, 100, 0.3, 3},
+	}
+	for _, tt := range tests {
+		if got := Repayment(tt.profit, tt.balance, tt.rate); got != tt.want {
+			t.Errorf("%s: Repayment = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoanTotalDue(t *testing.T) {
+	if got := DefaultLoan.TotalDue(); got != 11000 {
+		t.Errorf("expected 11000, got %d", got)
+	}
+}
@@ -0,0 +1,475 @@
+package bankruptcy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/games"
+	"github.com/alonsoalpizar/calleviva/backend/internal/inventory"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ledger"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pgLockNotAvailable is returned by FOR UPDATE NOWAIT when a running day
+// holds the session row
+const pgLockNotAvailable = "55P03"
+
+type Handler struct {
+	db *pgxpool.Pool
+}
+
+func NewHandler(db *pgxpool.Pool) *Handler {
+	return &Handler{db: db}
+}
+
+// SetupRoutes mounts bankruptcy routes (requires auth)
+func (h *Handler) SetupRoutes(r chi.Router) {
+	r.Get("/bankruptcy", h.GetStatus)
+	r.Post("/bankruptcy/loan", h.TakeLoan)
+	r.Post("/bankruptcy/restart-day", h.RestartDay)
+	r.Post("/bankruptcy/new-game", h.NewGame)
+}
+
+// sessionState is the part of game_sessions the bankruptcy flow reads
+type sessionState struct {
+	WorldType     string
+	GameDay       int
+	Money         int64
+	Status        string
+	NegativeDays  int
+	LoanBalance   int64
+	RepaymentRate float64
+}
+
+const sessionColumns = `
+	world_type, game_day, money, COALESCE(status, 'active'),
+	COALESCE(negative_days, 0), COALESCE(loan_balance, 0),
+	COALESCE(loan_repayment_rate, 0)::float8
+`
+
+func (s *sessionState) scan(row pgx.Row) error {
+	return row.Scan(&s.WorldType, &s.GameDay, &s.Money, &s.Status,
+		&s.NegativeDays, &s.LoanBalance, &s.RepaymentRate)
+}
+
+// GET /api/v1/games/{gameID}/bankruptcy
+// Negative days count and, when bankrupt, the options of the prompt
+func (h *Handler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, playerID, ok := parseIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var s sessionState
+	err := s.scan(h.db.QueryRow(ctx, `
+		SELECT `+sessionColumns+`
+		FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+	`, gameID, playerID))
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	resp := StatusResponse{
+		Status:        s.Status,
+		Bankrupt:      s.Status == StatusBankrupt,
+		Money:         s.Money,
+		NegativeDays:  s.NegativeDays,
+		Limit:         NegativeDaysLimit,
+		LoanBalance:   s.LoanBalance,
+		RepaymentRate: s.RepaymentRate,
+		Options:       []Option{},
+	}
+
+	if !resp.Bankrupt {
+		if s.NegativeDays > 0 {
+			resp.Message = "Cuidado: estás en números rojos. Si seguís así, vas a quebrar."
+		}
+		render.JSON(w, r, resp)
+		return
+	}
+
+	resp.Message = "¡Uy! Se te acabó la plata. Tranqui, todavía tenés opciones."
+
+	var hasSnapshot bool
+	h.db.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM day_snapshots WHERE session_id = $1 AND game_day = $2)
+	`, gameID, s.GameDay-1).Scan(&hasSnapshot)
+
+	restart := Option{Code: OptionRestartDay, Name: "Reiniciar el día", Available: hasSnapshot}
+	if !hasSnapshot {
+		restart.Reason = "No hay un día anterior guardado"
+	}
+
+	loan := loadLoan(ctx, h.db, s.WorldType)
+	emergency := Option{Code: OptionLoan, Name: "Préstamo de emergencia", Available: s.LoanBalance == 0, Loan: &loan}
+	if s.LoanBalance > 0 {
+		emergency.Reason = "Todavía debés el préstamo anterior"
+	}
+
+	resp.Options = []Option{
+		restart,
+		emergency,
+		{Code: OptionNewGame, Name: "Empezar de nuevo", Available: true},
+	}
+	render.JSON(w, r, resp)
+}
+
+// POST /api/v1/games/{gameID}/bankruptcy/loan
+// Takes the emergency loan; it's paid back from future day profits
+func (h *Handler) TakeLoan(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, playerID, ok := parseIDs(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error de transacción"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	s, ok := lockBankrupt(ctx, w, r, tx, gameID, playerID)
+	if !ok {
+		return
+	}
+
+	if s.LoanBalance > 0 {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Todavía debés el préstamo anterior"})
+		return
+	}
+
+	loan := loadLoan(ctx, tx, s.WorldType)
+	due := loan.TotalDue()
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO loans (session_id, game_day, amount, total_due, repayment_rate, balance)
+		VALUES ($1, $2, $3, $4, $5, $4)
+	`, gameID, s.GameDay, loan.Amount, due, loan.RepaymentRate)
+	if err != nil {
+		log.Printf("Error creating loan for %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al crear el préstamo"})
+		return
+	}
+
+	resp := LoanResponse{Success: true, Amount: loan.Amount, TotalDue: due}
+	resp.NewMoney, err = ledger.Post(ctx, tx, gameID, ledger.Entry{
+		GameDay:     s.GameDay,
+		Amount:      loan.Amount,
+		Account:     ledger.AccountLoans,
+		Reason:      ledger.ReasonLoan,
+		Description: "Préstamo de emergencia",
+	})
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al actualizar dinero"})
+		return
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE game_sessions
+		SET loan_balance = $2,
+		    loan_repayment_rate = $3,
+		    negative_days = 0,
+		    status = $4,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING loan_balance, status
+	`, gameID, due, loan.RepaymentRate, StatusActive).Scan(&resp.LoanBalance, &resp.Status)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al actualizar la partida"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al confirmar"})
+		return
+	}
+
+	render.JSON(w, r, resp)
+}
+
+// POST /api/v1/games/{gameID}/bankruptcy/restart-day
+// Restores the snapshot taken before the last day and lets the player replay it
+func (h *Handler) RestartDay(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, playerID, ok := parseIDs(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error de transacción"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	s, ok := lockBankrupt(ctx, w, r, tx, gameID, playerID)
+	if !ok {
+		return
+	}
+
+	day := s.GameDay - 1
+	resp := RestartResponse{Success: true}
+	err = tx.QueryRow(ctx, `
+		UPDATE game_sessions g
+		SET game_day = s.game_day,
+		    money = s.money,
+		    reputation = s.reputation,
+		    weather = s.weather,
+		    weather_forecast = s.weather_forecast,
+		    current_location = s.current_location,
+		    location_day = s.location_day,
+		    negative_days = s.negative_days,
+		    loan_balance = s.loan_balance,
+		    loan_repayment_rate = s.loan_repayment_rate,
+		    status = $3,
+		    updated_at = NOW()
+		FROM day_snapshots s
+		WHERE g.id = $1 AND s.session_id = g.id AND s.game_day = $2
+		RETURNING g.game_day, g.money, g.reputation, g.status
+	`, gameID, day, StatusActive).Scan(&resp.GameDay, &resp.Money, &resp.Reputation, &resp.Status)
+	if err == pgx.ErrNoRows {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "No hay un día anterior guardado"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error restoring snapshot %d for %s: %v", day, gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al reiniciar el día"})
+		return
+	}
+
+	// Forget the replayed day so it can be simulated again
+	for _, table := range []string{"sales_log", "day_summaries"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE session_id = $1 AND game_day >= $2`, gameID, day); err != nil {
+			log.Printf("Error clearing %s for %s: %v", table, gameID, err)
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Error al reiniciar el día"})
+			return
+		}
+	}
+	// Lab dishes only count the sales still logged
+	if err := recountDishes(ctx, tx, gameID); err != nil {
+		log.Printf("Error recounting dishes for %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al reiniciar el día"})
+		return
+	}
+	// Money goes back to the snapshot, so do its movements and the creator
+	// ingredient uses they counted
+	if err := forgetCreatorUses(ctx, tx, gameID, day); err != nil {
+		log.Printf("Error forgetting creator uses for %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al reiniciar el día"})
+		return
+	}
+	if err := ledger.Rewind(ctx, tx, gameID, day); err != nil {
+		log.Printf("Error rewinding ledger for %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al reiniciar el día"})
+		return
+	}
+
+	// The replayed day keeps its market prices; later sheets are regenerated
+	for _, table := range []string{"loans", "market_prices"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE session_id = $1 AND game_day > $2`, gameID, day); err != nil {
+			log.Printf("Error clearing %s for %s: %v", table, gameID, err)
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Error al reiniciar el día"})
+			return
+		}
+	}
+
+	// Loans taken later are gone; the one left owes the restored balance
+	if err := syncLoan(ctx, tx, gameID); err != nil {
+		log.Printf("Error restoring loan for %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al reiniciar el día"})
+		return
+	}
+
+	// Ingredients used or spoiled during the day come back too. Snapshots
+	// taken before the inventory existed leave the lots as they are.
+	var stock []byte
+	err = tx.QueryRow(ctx, `
+		SELECT inventory FROM day_snapshots WHERE session_id = $1 AND game_day = $2
+	`, gameID, day).Scan(&stock)
+	if err != nil && err != pgx.ErrNoRows {
+		log.Printf("Error loading inventory snapshot for %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al reiniciar el día"})
+		return
+	}
+	if stock != nil {
+		if err := inventory.Restore(ctx, tx, gameID, stock); err != nil {
+			log.Printf("Error restoring inventory for %s: %v", gameID, err)
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Error al reiniciar el día"})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al confirmar"})
+		return
+	}
+
+	render.JSON(w, r, resp)
+}
+
+// POST /api/v1/games/{gameID}/bankruptcy/new-game
+// Closes the bankrupt game and starts a fresh one in the same world
+func (h *Handler) NewGame(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, playerID, ok := parseIDs(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error de transacción"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	s, ok := lockBankrupt(ctx, w, r, tx, gameID, playerID)
+	if !ok {
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE game_sessions SET status = $2, updated_at = NOW() WHERE id = $1
+	`, gameID, StatusFinished); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cerrar la partida"})
+		return
+	}
+
+	var name *string
+	tx.QueryRow(ctx, `SELECT name FROM game_sessions WHERE id = $1`, gameID).Scan(&name)
+
+	game, err := games.CreateSession(ctx, tx, playerID.String(), s.WorldType, name)
+	if err != nil {
+		log.Printf("Error creating new game after bankruptcy of %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al crear la partida"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al confirmar"})
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, NewGameResponse{Success: true, Game: game})
+}
+
+// parseIDs reads the game ID and the authenticated player, writing the error
+// response when either is missing
+func parseIDs(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+	return gameID, playerID, true
+}
+
+// lockBankrupt locks the session and checks it is bankrupt
+func lockBankrupt(ctx context.Context, w http.ResponseWriter, r *http.Request, tx pgx.Tx, gameID, playerID uuid.UUID) (sessionState, bool) {
+	var s sessionState
+	err := s.scan(tx.QueryRow(ctx, `
+		SELECT `+sessionColumns+`
+		FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+		FOR UPDATE NOWAIT
+	`, gameID, playerID))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgLockNotAvailable {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, map[string]string{"error": "El día está en curso, esperá a que termine"})
+			return s, false
+		}
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return s, false
+	}
+
+	if s.Status != StatusBankrupt {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "La partida no está en quiebra"})
+		return s, false
+	}
+	return s, true
+}
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// loadLoan reads the world's emergency loan, falling back to DefaultLoan
+func loadLoan(ctx context.Context, db querier, worldType string) Loan {
+	var raw []byte
+	err := db.QueryRow(ctx, `
+		SELECT config->'emergency_loan'
+		FROM parameters
+		WHERE category = 'countries' AND code = $1 AND is_active = true
+	`, worldType).Scan(&raw)
+	if err != nil || len(raw) == 0 {
+		return DefaultLoan
+	}
+
+	loan := DefaultLoan
+	if err := json.Unmarshal(raw, &loan); err != nil || loan.Amount <= 0 {
+		return DefaultLoan
+	}
+	return loan
+}
@@ -0,0 +1,57 @@
+package bankruptcy
+
+import "github.com/alonsoalpizar/calleviva/backend/internal/models"
+
+// Bankruptcy options offered to the player
+const (
+	OptionRestartDay = "restart_day"
+	OptionLoan       = "emergency_loan"
+	OptionNewGame    = "new_game"
+)
+
+// Option is one of the choices in the bankruptcy prompt
+type Option struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"` // why it's not available
+	Loan      *Loan  `json:"loan,omitempty"`
+}
+
+// StatusResponse is the response for GET /bankruptcy
+type StatusResponse struct {
+	Status        string   `json:"status"`
+	Bankrupt      bool     `json:"bankrupt"`
+	Money         int64    `json:"money"`
+	NegativeDays  int      `json:"negative_days"`
+	Limit         int      `json:"negative_days_limit"`
+	LoanBalance   int64    `json:"loan_balance"`
+	RepaymentRate float64  `json:"loan_repayment_rate"`
+	Message       string   `json:"message,omitempty"`
+	Options       []Option `json:"options"`
+}
+
+// LoanResponse is the response for POST /bankruptcy/loan
+type LoanResponse struct {
+	Success     bool   `json:"success"`
+	Amount      int64  `json:"amount"`
+	TotalDue    int64  `json:"total_due"`
+	NewMoney    int64  `json:"new_money"`
+	LoanBalance int64  `json:"loan_balance"`
+	Status      string `json:"status"`
+}
+
+// RestartResponse is the response for POST /bankruptcy/restart-day
+type RestartResponse struct {
+	Success    bool   `json:"success"`
+	GameDay    int    `json:"game_day"`
+	Money      int64  `json:"money"`
+	Reputation int    `json:"reputation"`
+	Status     string `json:"status"`
+}
+
+// NewGameResponse is the response for POST /bankruptcy/new-game
+type NewGameResponse struct {
+	Success bool               `json:"success"`
+	Game    models.GameSession `json:"game"`
+}
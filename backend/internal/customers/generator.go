@@ -0,0 +1,230 @@
+package customers
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"strconv"
+)
+
+// Seed derives a deterministic seed for a game and day
+func Seed(gameID string, day int) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(gameID))
+	h.Write([]byte(":" + strconv.Itoa(day)))
+	return int64(h.Sum64())
+}
+
+// preferenceWeights is how common each flavor preference is
+var preferenceWeights = []struct {
+	flavor string
+	weight int
+}{
+	{FlavorSavory, 5},
+	{FlavorSweet, 3},
+	{FlavorFresh, 2},
+}
+
+// Generator produces the customers of a day. Given the same seed and
+// inputs it produces the same customers in the same order.
+type Generator struct {
+	rng     *rand.Rand
+	loc     Location
+	cond    Conditions
+	curve   map[int]float64
+	types   []Type
+	weights []float64
+	total   float64
+}
+
+// NewGenerator prepares customer generation for a location and day.
+// Types not present in the location's customer_mix are never generated;
+// with no mix every type is equally likely.
+func NewGenerator(seed int64, loc Location, types []Type, cond Conditions) *Generator {
+	if len(types) == 0 {
+		types = DefaultTypes
+	}
+
+	g := &Generator{
+		rng:   rand.New(rand.NewSource(seed)),
+		loc:   loc,
+		cond:  cond,
+		curve: HourCurve(loc.BestHours),
+	}
+
+	for _, t := range types {
+		w := 1.0
+		if len(loc.CustomerMix) > 0 {
+			w = loc.CustomerMix[t.Code]
+		}
+		if w <= 0 {
+			continue
+		}
+		g.types = append(g.types, t)
+		g.weights = append(g.weights, w)
+		g.total += w
+	}
+
+	// A mix that matches no known type falls back to all types
+	if len(g.types) == 0 {
+		for _, t := range types {
+			g.types = append(g.types, t)
+			g.weights = append(g.weights, 1)
+			g.total++
+		}
+	}
+
+	return g
+}
+
+// Rand exposes the generator's random source so callers can keep drawing
+// from the same deterministic sequence
+func (g *Generator) Rand() *rand.Rand {
+	return g.rng
+}
+
+// Hour generates the customers that arrive during an hour
+func (g *Generator) Hour(hour int) []Customer {
+	mean := baseLoad(g.loc, g.cond) * g.curve[hour]
+	n := poisson(g.rng, mean)
+
+	out := make([]Customer, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, g.customer(hour))
+	}
+	return out
+}
+
+func (g *Generator) customer(hour int) Customer {
+	t := g.pickType()
+
+	orderSize := t.OrderSize
+	if orderSize < 1 {
+		orderSize = 1
+	}
+
+	loyalty := g.rng.Intn(31)
+	if t.LoyaltyBonus {
+		loyalty += 40
+	}
+
+	return Customer{
+		Type:             t.Code,
+		Hour:             hour,
+		Budget:           budgetLevel(t.Budget),
+		BudgetFactor:     budgetFactor(t.Budget) * (0.9 + g.rng.Float64()*0.2),
+		Patience:         g.patience(t.Patience),
+		Preference:       g.preference(),
+		Loyalty:          clamp(loyalty, 0, 100),
+		Mood:             clamp(5+g.rng.Intn(4)+moodShift(g.cond.WeatherMood), 1, 10),
+		OrderSize:        orderSize,
+		QualitySensitive: t.QualitySensitive,
+		ReputationImpact: t.ReputationImpact,
+	}
+}
+
+func (g *Generator) pickType() Type {
+	n := g.rng.Float64() * g.total
+	for i, w := range g.weights {
+		if n < w {
+			return g.types[i]
+		}
+		n -= w
+	}
+	return g.types[len(g.types)-1]
+}
+
+// patience draws a 1-10 patience within the type's band
+func (g *Generator) patience(level string) int {
+	switch level {
+	case "low":
+		return 2 + g.rng.Intn(3)
+	case "high":
+		return 7 + g.rng.Intn(4)
+	default:
+		return 4 + g.rng.Intn(4)
+	}
+}
+
+func (g *Generator) preference() string {
+	total := 0
+	for _, p := range preferenceWeights {
+		total += p.weight
+	}
+	n := g.rng.Intn(total)
+	for _, p := range preferenceWeights {
+		if n < p.weight {
+			return p.flavor
+		}
+		n -= p.weight
+	}
+	return FlavorSavory
+}
+
+func budgetLevel(level string) string {
+	switch level {
+	case "low", "high":
+		return level
+	default:
+		return "medium"
+	}
+}
+
+// budgetFactor is how much of a product's expected price a budget level pays
+func budgetFactor(level string) float64 {
+	switch level {
+	case "low":
+		return 0.85
+	case "high":
+		return 1.35
+	default:
+		return 1.05
+	}
+}
+
+// moodShift moves the base mood according to the weather's mood
+func moodShift(weatherMood string) int {
+	switch weatherMood {
+	case "happy":
+		return 2
+	case "sad":
+		return -1
+	case "scared":
+		return -2
+	default:
+		return 0
+	}
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// poisson draws a customer count with the given mean
+func poisson(rng *rand.Rand, mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+	if mean > 30 {
+		n := int(math.Round(rng.NormFloat64()*math.Sqrt(mean) + mean))
+		if n < 0 {
+			return 0
+		}
+		return n
+	}
+
+	limit := math.Exp(-mean)
+	k := 0
+	p := rng.Float64()
+	for p > limit {
+		k++
+		p *= rng.Float64()
+	}
+	return k
+}
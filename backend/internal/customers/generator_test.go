@@ -0,0 +1,88 @@
+package customers
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+var testLocation = Location{
+	Code:        "universidad",
+	Name:        "Universidad",
+	FootTraffic: "high",
+	Competition: "medium",
+	BestHours:   "7-15",
+}
+
+func TestSeed(t *testing.T) {
+	if Seed("game", 1) != Seed("game", 1) {
+		t.Error("Seed is not stable")
+	}
+	if Seed("game", 1) == Seed("game", 2) {
+		t.Error("different days should get different seeds")
+	}
+}
+
+func TestExpectedPerHourFormula(t *testing.T) {
+	cond := Conditions{WeatherModifier: 1.2, EventModifier: 1.0, Reputation: 50}
+
+	// 10 (high) × 1.0 (medium competition) × 1.0 (peak) × 1.2 × 1.0 × 1.2 (rep 41-60)
+	got := ExpectedPerHour(testLocation, 12, cond)
+	if math.Abs(got-14.4) > 1e-9 {
+		t.Errorf("peak hour: expected 14.4, got %v", got)
+	}
+
+	// Off-peak hours use 0.35 of the peak
+	got = ExpectedPerHour(testLocation, 17, cond)
+	if math.Abs(got-14.4*0.35) > 1e-9 {
+		t.Errorf("off-peak hour: expected %v, got %v", 14.4*0.35, got)
+	}
+
+	// Unset modifiers are neutral
+	if ExpectedPerHour(testLocation, 12, Conditions{}) != 10 {
+		t.Error("zero conditions should not change base traffic")
+	}
+}
+
+func TestGeneratorIsDeterministic(t *testing.T) {
+	cond := Conditions{WeatherModifier: 1.0, Reputation: 30}
+	a := NewGenerator(99, testLocation, DefaultTypes, cond)
+	b := NewGenerator(99, testLocation, DefaultTypes, cond)
+
+	for h := OpenHour; h <= CloseHour; h++ {
+		if !reflect.DeepEqual(a.Hour(h), b.Hour(h)) {
+			t.Fatalf("hour %d differs for the same seed", h)
+		}
+	}
+}
+
+func TestGeneratorAttributes(t *testing.T) {
+	loc := testLocation
+	loc.CustomerMix = map[string]float64{"student": 1}
+
+	gen := NewGenerator(5, loc, DefaultTypes, Conditions{WeatherMood: "happy"})
+	total := 0
+	for h := OpenHour; h <= CloseHour; h++ {
+		for _, c := range gen.Hour(h) {
+			total++
+			if c.Type != "student" {
+				t.Fatalf("customer mix only allows students, got %s", c.Type)
+			}
+			if c.Budget != "low" {
+				t.Errorf("students have low budget, got %s", c.Budget)
+			}
+			if c.Patience < 1 || c.Patience > 10 || c.Mood < 1 || c.Mood > 10 {
+				t.Errorf("patience and mood must be 1-10: %+v", c)
+			}
+			if c.Loyalty < 0 || c.Loyalty > 100 {
+				t.Errorf("loyalty must be 0-100: %+v", c)
+			}
+			if c.Preference == "" || c.OrderSize < 1 {
+				t.Errorf("missing preference or order size: %+v", c)
+			}
+		}
+	}
+	if total == 0 {
+		t.Fatal("expected customers during the day")
+	}
+}
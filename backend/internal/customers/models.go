@@ -0,0 +1,92 @@
+package customers
+
+import "encoding/json"
+
+// Customer preferences (GDD 4.2), matched against a product's flavor
+const (
+	FlavorSweet  = "sweet"
+	FlavorSavory = "savory"
+	FlavorFresh  = "fresh"
+)
+
+// Type is a customer type from the customer_types parameters
+type Type struct {
+	Code             string  `json:"code"`
+	Name             string  `json:"name"`
+	Budget           string  `json:"budget"`   // low, medium, high
+	Patience         string  `json:"patience"` // low, medium, high
+	TipChance        float64 `json:"tip_chance,omitempty"`
+	OrderSize        int     `json:"order_size,omitempty"`
+	QualitySensitive bool    `json:"quality_sensitive,omitempty"`
+	LoyaltyBonus     bool    `json:"loyalty_bonus,omitempty"`
+	ReputationImpact float64 `json:"reputation_impact,omitempty"`
+	ViralChance      float64 `json:"viral_chance,omitempty"`
+}
+
+// DefaultTypes is used when no customer_types parameters are configured
+var DefaultTypes = []Type{
+	{Code: "student", Name: "Estudiante", Budget: "low", Patience: "medium"},
+	{Code: "worker", Name: "Oficinista", Budget: "medium", Patience: "low"},
+	{Code: "family", Name: "Familia", Budget: "medium", Patience: "medium", OrderSize: 3},
+	{Code: "tourist", Name: "Turista", Budget: "high", Patience: "high"},
+}
+
+// ParseType builds a Type from a customer_types parameter row
+func ParseType(code, name string, config []byte) (Type, error) {
+	t := Type{}
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &t); err != nil {
+			return t, err
+		}
+	}
+	t.Code = code
+	t.Name = name
+	return t, nil
+}
+
+// Location is the part of a locations_* parameter that drives traffic
+type Location struct {
+	Code        string             `json:"code"`
+	Name        string             `json:"name"`
+	FootTraffic string             `json:"foot_traffic"`         // low, medium, high, very_high, event_based
+	Competition string             `json:"competition"`          // low, medium, high, very_high
+	BestHours   string             `json:"best_hours,omitempty"` // "12-20", "6-9,17-20", "eventos"
+	Rent        float64            `json:"rent"`
+	CustomerMix map[string]float64 `json:"customer_mix,omitempty"` // relative weight per customer type
+}
+
+// ParseLocation builds a Location from a locations_* parameter row
+func ParseLocation(code, name string, config []byte) (Location, error) {
+	loc := Location{}
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &loc); err != nil {
+			return loc, err
+		}
+	}
+	loc.Code = code
+	loc.Name = name
+	return loc, nil
+}
+
+// Conditions are the day-level modifiers of the traffic formula
+type Conditions struct {
+	WeatherModifier float64 // customer_modifier of the weather parameter
+	WeatherMood     string  // mood of the weather parameter: happy, neutral, sad, scared
+	EventModifier   float64 // 1.0 when there is no event
+	Reputation      int     // 0-100
+}
+
+// Customer is a generated customer with the GDD 4.2 attributes
+type Customer struct {
+	Type             string  `json:"type"`
+	Hour             int     `json:"hour"`
+	Budget           string  `json:"budget"`        // low, medium, high
+	BudgetFactor     float64 `json:"budget_factor"` // share of the expected price they will pay
+	Patience         int     `json:"patience"`      // 1-10
+	Preference       string  `json:"preference"`    // sweet, savory, fresh
+	Loyalty          int     `json:"loyalty"`       // 0-100
+	Mood             int     `json:"mood"`          // 1-10
+	OrderSize        int     `json:"order_size"`
+	QualitySensitive bool    `json:"quality_sensitive,omitempty"`
+	ReputationImpact float64 `json:"reputation_impact,omitempty"`
+}
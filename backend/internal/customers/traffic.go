@@ -0,0 +1,130 @@
+package customers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/reputation"
+)
+
+// Business hours of a day (inclusive)
+const (
+	OpenHour  = 6
+	CloseHour = 18
+)
+
+// defaultHourCurve is the GDD traffic curve (Zona Industrial) used when a
+// location has no best_hours configured
+var defaultHourCurve = map[int]float64{
+	6: 0.4, 7: 0.6, 8: 0.4, 9: 0.3, 10: 0.3, 11: 0.7, 12: 1.0,
+	13: 0.8, 14: 0.5, 15: 0.3, 16: 0.4, 17: 0.6, 18: 0.4,
+}
+
+// HourTraffic is the expected number of customers in an hour
+type HourTraffic struct {
+	Hour     int     `json:"hour"`
+	Expected float64 `json:"expected"`
+}
+
+// ExpectedPerHour applies the GDD formula:
+// BASE_TRAFFIC × HOUR × WEATHER × EVENT × REPUTATION
+// (base traffic already discounted by the location's competition)
+func ExpectedPerHour(loc Location, hour int, cond Conditions) float64 {
+	return baseLoad(loc, cond) * HourCurve(loc.BestHours)[hour]
+}
+
+// Forecast returns the expected traffic for every business hour
+func Forecast(loc Location, cond Conditions) []HourTraffic {
+	curve := HourCurve(loc.BestHours)
+	base := baseLoad(loc, cond)
+
+	hours := make([]HourTraffic, 0, CloseHour-OpenHour+1)
+	for h := OpenHour; h <= CloseHour; h++ {
+		hours = append(hours, HourTraffic{Hour: h, Expected: base * curve[h]})
+	}
+	return hours
+}
+
+// baseLoad is everything in the formula except the hour modifier
+func baseLoad(loc Location, cond Conditions) float64 {
+	return BaseTraffic(loc.FootTraffic) *
+		CompetitionModifier(loc.Competition) *
+		positive(cond.WeatherModifier) *
+		positive(cond.EventModifier) *
+		reputation.TrafficModifier(cond.Reputation)
+}
+
+// positive treats unset modifiers as neutral
+func positive(mod float64) float64 {
+	if mod <= 0 {
+		return 1.0
+	}
+	return mod
+}
+
+// BaseTraffic converts a location's foot_traffic level into customers per peak hour
+func BaseTraffic(level string) float64 {
+	switch level {
+	case "low":
+		return 4
+	case "medium":
+		return 7
+	case "high":
+		return 10
+	case "very_high":
+		return 14
+	case "event_based":
+		return 8
+	default:
+		return 7
+	}
+}
+
+// CompetitionModifier reduces traffic where other trucks compete for customers
+func CompetitionModifier(level string) float64 {
+	switch level {
+	case "low":
+		return 1.1
+	case "high":
+		return 0.85
+	case "very_high":
+		return 0.7
+	default:
+		return 1.0
+	}
+}
+
+// HourCurve builds the per-hour traffic modifier from a best_hours spec
+// like "12-20" or "6-9,17-20". Unknown specs ("eventos") get a flat curve.
+func HourCurve(bestHours string) map[int]float64 {
+	if bestHours == "" {
+		return defaultHourCurve
+	}
+
+	mods := map[int]float64{}
+	parsed := false
+	for _, part := range strings.Split(bestHours, ",") {
+		bounds := strings.Split(strings.TrimSpace(part), "-")
+		if len(bounds) != 2 {
+			continue
+		}
+		from, err1 := strconv.Atoi(bounds[0])
+		to, err2 := strconv.Atoi(bounds[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		parsed = true
+		for h := from; h < to; h++ {
+			mods[h] = 1.0
+		}
+	}
+
+	for h := OpenHour; h <= CloseHour; h++ {
+		if !parsed {
+			mods[h] = 0.5
+		} else if _, ok := mods[h]; !ok {
+			mods[h] = 0.35
+		}
+	}
+	return mods
+}
@@ -0,0 +1,202 @@
+package demand
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/customers"
+)
+
+// GDD 4.1 economy constants
+const (
+	SuggestedMarkup     = 2.2 // PRECIO_SUGERIDO = COSTO_BASE × 2.2
+	Sensitivity         = 0.5 // weight of the price gap in FACTOR_PRECIO
+	ComplaintThreshold  = 1.3 // above this share of the expected price customers complain
+	LowQualityThreshold = 0.8 // below this share customers suspect low quality
+)
+
+// How a customer perceives a price
+const (
+	PerceptionFair       = "fair"
+	PerceptionComplaint  = "complaint"
+	PerceptionLowQuality = "low_quality"
+)
+
+// Purchase outcomes
+const (
+	OutcomeBuy          = "buy"
+	OutcomeTooExpensive = "too_expensive"
+	OutcomeNoMatch      = "no_match"
+)
+
+// Product is something on the menu as the demand model sees it
+type Product struct {
+	Code          string
+	Price         int
+	ExpectedPrice int
+	Flavor        string // empty matches every preference
+	Popularity    int    // 1-100
+}
+
+// Decision is what a customer did in front of the menu
+type Decision struct {
+	Outcome    string
+	Index      int // menu index of the chosen product, -1 when none
+	Perception string
+	Complaint  bool // price above the complaint threshold
+}
+
+// SuggestedPrice applies the GDD markup to a base cost
+func SuggestedPrice(cost int) int {
+	return int(math.Round(float64(cost) * SuggestedMarkup))
+}
+
+// PriceFactor is the GDD elasticity factor:
+// 1 - ((PRICE - EXPECTED) / EXPECTED × 0.5), never below 0.
+// 1.0 at the expected price, above 1 when cheaper.
+func PriceFactor(price, expected int) float64 {
+	if expected <= 0 {
+		return 1.0
+	}
+	f := 1 - (float64(price-expected)/float64(expected))*Sensitivity
+	if f < 0 {
+		return 0
+	}
+	return f
+}
+
+// Perceive classifies a price against the expected price
+func Perceive(price, expected int) string {
+	if expected <= 0 {
+		return PerceptionFair
+	}
+	ratio := float64(price) / float64(expected)
+	switch {
+	case ratio > ComplaintThreshold:
+		return PerceptionComplaint
+	case ratio < LowQualityThreshold:
+		return PerceptionLowQuality
+	default:
+		return PerceptionFair
+	}
+}
+
+// FairBand returns the price range that triggers neither complaints nor
+// low quality suspicion
+func FairBand(expected int) (int, int) {
+	return int(math.Ceil(float64(expected) * LowQualityThreshold)),
+		int(math.Floor(float64(expected) * ComplaintThreshold))
+}
+
+// ClampToFairBand moves a price into the fair band of the expected price
+func ClampToFairBand(price, expected int) int {
+	if expected <= 0 {
+		return price
+	}
+	lo, hi := FairBand(expected)
+	if price < lo {
+		return lo
+	}
+	if price > hi {
+		return hi
+	}
+	return price
+}
+
+// Decide picks what a customer does in front of the menu:
+//  1. Keep the products matching their preference. With none, a customer in
+//     a good mood may still look at the whole menu; otherwise they leave (no_match).
+//  2. Choose one weighted by popularity and how attractive its price is.
+//  3. Buy with probability FACTOR_PRECIO computed against what this customer
+//     expects to pay (expected price × budget factor).
+func Decide(rng *rand.Rand, c customers.Customer, menu []Product) Decision {
+	none := Decision{Outcome: OutcomeNoMatch, Index: -1, Perception: PerceptionFair}
+	if len(menu) == 0 {
+		return none
+	}
+
+	candidates := matching(menu, c.Preference)
+	if len(candidates) == 0 {
+		if rng.Float64() >= openness(c.Mood) {
+			return none
+		}
+		candidates = make([]int, len(menu))
+		for i := range menu {
+			candidates[i] = i
+		}
+	}
+
+	idx, ok := choose(rng, c, menu, candidates)
+	if !ok {
+		return none
+	}
+	p := menu[idx]
+
+	d := Decision{Index: idx, Perception: Perceive(p.Price, p.ExpectedPrice)}
+	d.Complaint = d.Perception == PerceptionComplaint
+
+	budget := c.BudgetFactor
+	if budget <= 0 {
+		budget = 1.0
+	}
+	willing := int(math.Round(float64(p.ExpectedPrice) * budget))
+	if rng.Float64() < PriceFactor(p.Price, willing) {
+		d.Outcome = OutcomeBuy
+	} else {
+		d.Outcome = OutcomeTooExpensive
+	}
+	return d
+}
+
+// matching returns the menu indexes whose flavor fits the preference
+func matching(menu []Product, preference string) []int {
+	var out []int
+	for i, p := range menu {
+		if p.Flavor == "" || preference == "" || p.Flavor == preference {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// openness is the chance a customer without a matching product tries
+// something else: 30% at mood 1 up to 57% at mood 10
+func openness(mood int) float64 {
+	return 0.27 + float64(mood)*0.03
+}
+
+// choose picks a candidate weighted by popularity × price attractiveness.
+// Quality sensitive customers avoid suspiciously cheap products.
+func choose(rng *rand.Rand, c customers.Customer, menu []Product, candidates []int) (int, bool) {
+	weights := make([]float64, len(candidates))
+	total := 0.0
+	for i, idx := range candidates {
+		p := menu[idx]
+		pop := float64(p.Popularity)
+		if pop < 1 {
+			pop = 1
+		}
+		w := pop * math.Max(PriceFactor(p.Price, p.ExpectedPrice), 0.05)
+		if Perceive(p.Price, p.ExpectedPrice) == PerceptionLowQuality {
+			if c.QualitySensitive {
+				w = 0
+			} else {
+				w *= 0.7
+			}
+		}
+		weights[i] = w
+		total += w
+	}
+	if total <= 0 {
+		return -1, false
+	}
+
+	n := rng.Float64() * total
+	for i, w := range weights {
+		if n < w {
+			return candidates[i], true
+		}
+		n -= w
+	}
+	return candidates[len(candidates)-1], true
+}
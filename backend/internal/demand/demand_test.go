@@ -0,0 +1,127 @@
+package demand
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/customers"
+)
+
+func TestPriceFactor(t *testing.T) {
+	tests := []struct {
+		price, expected int
+		want            float64
+	}{
+		{1000, 1000, 1.0},
+		{1300, 1000, 0.85},
+		{800, 1000, 1.1},
+		{3000, 1000, 0.0}, // never negative
+		{500, 0, 1.0},     // unknown expected price is neutral
+	}
+	for _, tt := range tests {
+		if got := PriceFactor(tt.price, tt.expected); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("PriceFactor(%d, %d) = %v, want %v", tt.price, tt.expected, got, tt.want)
+		}
+	}
+}
+
+func TestPerceive(t *testing.T) {
+	tests := []struct {
+		price int
+		want  string
+	}{
+		{1000, PerceptionFair},
+		{1300, PerceptionFair},
+		{1301, PerceptionComplaint},
+		{800, PerceptionFair},
+		{799, PerceptionLowQuality},
+	}
+	for _, tt := range tests {
+		if got := Perceive(tt.price, 1000); got != tt.want {
+			t.Errorf("Perceive(%d, 1000) = %s, want %s", tt.price, got, tt.want)
+		}
+	}
+}
+
+func TestClampToFairBand(t *testing.T) {
+	if got := ClampToFairBand(20000, 5000); got != 6500 {
+		t.Errorf("expected 6500, got %d", got)
+	}
+	if got := ClampToFairBand(1000, 5000); got != 4000 {
+		t.Errorf("expected 4000, got %d", got)
+	}
+	if got := ClampToFairBand(5500, 5000); got != 5500 {
+		t.Errorf("fair price should not change, got %d", got)
+	}
+	if SuggestedPrice(1000) != 2200 {
+		t.Errorf("suggested price should be cost × 2.2")
+	}
+}
+
+func TestDecideNoMatch(t *testing.T) {
+	menu := []Product{{Code: "churchill", Price: 2000, ExpectedPrice: 2000, Flavor: customers.FlavorSweet, Popularity: 70}}
+	c := customers.Customer{Preference: customers.FlavorSavory, BudgetFactor: 1.0, Mood: 1}
+
+	rng := rand.New(rand.NewSource(1))
+	noMatch := 0
+	for i := 0; i < 1000; i++ {
+		if Decide(rng, c, menu).Outcome == OutcomeNoMatch {
+			noMatch++
+		}
+	}
+	// A grumpy customer only looks past their preference 30% of the time
+	if noMatch < 600 || noMatch > 800 {
+		t.Errorf("expected ~700 no_match out of 1000, got %d", noMatch)
+	}
+
+	if d := Decide(rng, c, nil); d.Outcome != OutcomeNoMatch || d.Index != -1 {
+		t.Errorf("empty menu should be no_match, got %+v", d)
+	}
+}
+
+func TestDecidePriceSensitivity(t *testing.T) {
+	c := customers.Customer{Preference: customers.FlavorSavory, BudgetFactor: 1.0, Mood: 5}
+	fair := []Product{{Code: "casado", Price: 4500, ExpectedPrice: 4500, Flavor: customers.FlavorSavory, Popularity: 80}}
+	abusive := []Product{{Code: "casado", Price: 9000, ExpectedPrice: 4500, Flavor: customers.FlavorSavory, Popularity: 80}}
+
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 100; i++ {
+		if d := Decide(rng, c, fair); d.Outcome != OutcomeBuy || d.Complaint {
+			t.Fatalf("a fair price at full budget should always sell, got %+v", d)
+		}
+	}
+
+	bought, complaints := 0, 0
+	for i := 0; i < 1000; i++ {
+		d := Decide(rng, c, abusive)
+		if d.Outcome == OutcomeBuy {
+			bought++
+		}
+		if d.Complaint {
+			complaints++
+		}
+	}
+	// Double the expected price: FACTOR = 0.5
+	if bought < 420 || bought > 580 {
+		t.Errorf("expected ~500 buys at factor 0.5, got %d", bought)
+	}
+	if complaints != 1000 {
+		t.Errorf("every customer should complain at 2× the price, got %d", complaints)
+	}
+}
+
+func TestDecideQualitySensitive(t *testing.T) {
+	menu := []Product{
+		{Code: "cheap", Price: 500, ExpectedPrice: 1000, Popularity: 50},
+		{Code: "fair", Price: 1000, ExpectedPrice: 1000, Popularity: 50},
+	}
+	c := customers.Customer{BudgetFactor: 1.0, Mood: 5, QualitySensitive: true}
+
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 200; i++ {
+		if d := Decide(rng, c, menu); d.Index == 0 {
+			t.Fatal("quality sensitive customers should never pick a suspiciously cheap product")
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package games
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/database"
+	"github.com/alonsoalpizar/calleviva/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxNameLength matches game_sessions.name
+const maxNameLength = 100
+
+const (
+	maxViolations  = 20 // recorded per request; the keys are the client's
+	maxFieldLength = 50 // matches game_state_audit.field
+)
+
+// cosmeticStats are the keys of game_sessions.stats a client may write: the
+// truck's look, which the server never reads
+var cosmeticStats = map[string]bool{"truck": true}
+
+// Violation is a field a client tried to write that only the server changes
+type Violation struct {
+	Field     string
+	Attempted json.RawMessage
+}
+
+// CheckUpdate splits a PATCH body into the cosmetic update and the fields
+// the client isn't allowed to write
+func CheckUpdate(body map[string]json.RawMessage) (models.UpdateGameRequest, []Violation, error) {
+	var req models.UpdateGameRequest
+	var violations []Violation
+
+	for field, raw := range body {
+		switch field {
+		case "name":
+			var name string
+			if err := json.Unmarshal(raw, &name); err != nil {
+				return req, nil, errors.New("name tiene que ser texto")
+			}
+			name = strings.TrimSpace(name)
+			if name == "" || len([]rune(name)) > maxNameLength {
+				return req, nil, errors.New("El nombre tiene que tener entre 1 y 100 caracteres")
+			}
+			req.Name = &name
+
+		case "stats":
+			var stats map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &stats); err != nil {
+				return req, nil, errors.New("stats tiene que ser un objeto")
+			}
+			for key, value := range stats {
+				if !cosmeticStats[key] {
+					violations = append(violations, Violation{Field: "stats." + key, Attempted: value})
+					continue
+				}
+				if req.Stats == nil {
+					req.Stats = map[string]json.RawMessage{}
+				}
+				req.Stats[key] = value
+			}
+
+		default:
+			violations = append(violations, Violation{Field: field, Attempted: raw})
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Field < violations[j].Field })
+	if len(violations) > maxViolations {
+		violations = violations[:maxViolations]
+	}
+	for i := range violations {
+		if field := []rune(violations[i].Field); len(field) > maxFieldLength {
+			violations[i].Field = string(field[:maxFieldLength])
+		}
+	}
+	return req, violations, nil
+}
+
+// statsPatch is the stats parameter of an update: the keys to merge, or SQL
+// NULL when there are none. Marshalling a nil map gives the JSON null, which
+// COALESCE keeps and || turns the stats object into an array.
+func statsPatch(stats map[string]json.RawMessage) any {
+	if len(stats) == 0 {
+		return nil
+	}
+	patch, _ := json.Marshal(stats)
+	return patch
+}
+
+// flagViolations records rejected writes in game_state_audit with the value
+// the session had, so they can be reviewed
+func flagViolations(ctx context.Context, game models.GameSession, violations []Violation) error {
+	current, _ := json.Marshal(map[string]any{
+		"money":            game.Money,
+		"game_day":         game.GameDay,
+		"reputation":       game.Reputation,
+		"weather":          game.Weather,
+		"status":           game.Status,
+		"current_location": game.CurrentLocation,
+	})
+	var values map[string]json.RawMessage
+	json.Unmarshal(current, &values)
+
+	// One batch: all the attempts of the request are recorded or none
+	batch := &pgx.Batch{}
+	for _, v := range violations {
+		var currentValue any
+		if raw, ok := values[v.Field]; ok {
+			currentValue = raw
+		}
+		batch.Queue(`
+			INSERT INTO game_state_audit (session_id, player_id, field, attempted_value, current_value, source, reason)
+			VALUES ($1, $2, $3, $4, $5, 'patch', 'El cliente intentó cambiar estado del servidor')
+		`, game.ID, game.PlayerID, v.Field, v.Attempted, currentValue)
+	}
+	return database.Pool.SendBatch(ctx, batch).Close()
+}
@@ -0,0 +1,98 @@
+package games
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func body(t *testing.T, raw string) map[string]json.RawMessage {
+	t.Helper()
+	var b map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestCheckUpdateAcceptsCosmeticFields(t *testing.T) {
+	req, violations, err := CheckUpdate(body(t, `{"name": " La Chinita ", "stats": {"truck": {"theme": "coral"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 0 {
+		t.Errorf("expected no violations, got %+v", violations)
+	}
+	if req.Name == nil || *req.Name != "La Chinita" {
+		t.Errorf("expected the trimmed name, got %v", req.Name)
+	}
+	if _, ok := req.Stats["truck"]; !ok {
+		t.Errorf("expected the truck look to be kept, got %v", req.Stats)
+	}
+}
+
+func TestCheckUpdateFlagsEconomicFields(t *testing.T) {
+	_, violations, err := CheckUpdate(body(t, `{"name": "X", "money": 999999, "game_day": 40, "stats": {"truck": {}, "wins": 3}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"game_day", "money", "stats.wins"}
+	if len(violations) != len(want) {
+		t.Fatalf("expected %v, got %+v", want, violations)
+	}
+	for i, v := range violations {
+		if v.Field != want[i] {
+			t.Errorf("expected %s, got %s", want[i], v.Field)
+		}
+	}
+	if string(violations[1].Attempted) != "999999" {
+		t.Errorf("expected the attempted money to be kept, got %s", violations[1].Attempted)
+	}
+}
+
+func TestCheckUpdateValidatesName(t *testing.T) {
+	for _, raw := range []string{`{"name": "   "}`, `{"name": 5}`, `{"stats": []}`} {
+		if _, _, err := CheckUpdate(body(t, raw)); err == nil {
+			t.Errorf("expected %s to be rejected", raw)
+		}
+	}
+}
+
+func TestStatsPatchOnNameOnlyUpdate(t *testing.T) {
+	req, _, err := CheckUpdate(body(t, `{"name": "La Chinita"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patch := statsPatch(req.Stats); patch != nil {
+		t.Errorf("expected SQL NULL for a name-only update, got %s", patch)
+	}
+
+	req, _, _ = CheckUpdate(body(t, `{"stats": {"truck": {"theme": "coral"}}}`))
+	if patch, ok := statsPatch(req.Stats).([]byte); !ok || string(patch) != `{"truck":{"theme":"coral"}}` {
+		t.Errorf("expected the truck look as JSON, got %v", statsPatch(req.Stats))
+	}
+}
+
+func TestCheckUpdateCapsViolations(t *testing.T) {
+	keys := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		keys = append(keys, fmt.Sprintf(`"k%03d": 1`, i))
+	}
+	long := "a" + strings.Repeat("x", 200) // sorts first
+	_, violations, err := CheckUpdate(body(t, `{"`+long+`": 1, "stats": {`+strings.Join(keys, ", ")+`}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != maxViolations {
+		t.Errorf("expected %d violations, got %d", maxViolations, len(violations))
+	}
+	if violations[0].Field != long[:maxFieldLength] {
+		t.Errorf("expected the long key truncated, got %q", violations[0].Field)
+	}
+	for _, v := range violations {
+		if len(v.Field) > maxFieldLength {
+			t.Errorf("field %q is longer than %d", v.Field, maxFieldLength)
+		}
+	}
+}
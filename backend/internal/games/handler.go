@@ -3,13 +3,18 @@ package games
 import (
 	"context"
 	"encoding/json"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
 	"github.com/alonsoalpizar/calleviva/backend/internal/database"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ledger"
+	"github.com/alonsoalpizar/calleviva/backend/internal/menu"
 	"github.com/alonsoalpizar/calleviva/backend/internal/models"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // POST /api/v1/games - Create new game
@@ -34,39 +39,68 @@ func HandleCreate(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
+	var name *string
+	if req.Name != "" {
+		name = &req.Name
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create game")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	game, err := CreateSession(ctx, tx, claims.PlayerID, req.WorldType, name)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create game")
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create game")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, game)
+}
+
+// CreateSession inserts a new game with the world's starting money and its
+// starter cart. Also used to start over after a bankruptcy.
+func CreateSession(ctx context.Context, tx pgx.Tx, playerID, worldType string, name *string) (models.GameSession, error) {
+	var game models.GameSession
+
 	// Get starting money from parameters
 	var startingMoney int64 = 15000 // default
-	err = database.Pool.QueryRow(ctx, `
+	_ = tx.QueryRow(ctx, `
 		SELECT (config->>'starting_money')::bigint
 		FROM parameters
 		WHERE category = 'countries' AND code = $1 AND is_active = true
-	`, req.WorldType).Scan(&startingMoney)
+	`, worldType).Scan(&startingMoney)
 	// Ignore error, use default
 
-	var game models.GameSession
-	var name *string
-	if req.Name != "" {
-		name = &req.Name
-	}
-
-	err = database.Pool.QueryRow(ctx, `
+	err := tx.QueryRow(ctx, `
 		INSERT INTO game_sessions (player_id, world_type, name, money)
 		VALUES ($1, $2, $3, $4)
 		RETURNING id, player_id, world_type, name, game_day, money, reputation,
 		          current_location, weather, status, stats, created_at, updated_at
-	`, claims.PlayerID, req.WorldType, name, startingMoney).Scan(
+	`, playerID, worldType, name, startingMoney).Scan(
 		&game.ID, &game.PlayerID, &game.WorldType, &game.Name,
 		&game.GameDay, &game.Money, &game.Reputation,
 		&game.CurrentLocation, &game.Weather, &game.Status,
 		&game.Stats, &game.CreatedAt, &game.UpdatedAt,
 	)
-
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to create game")
-		return
+		return game, err
 	}
 
-	respondJSON(w, http.StatusCreated, game)
+	if err := ledger.Open(ctx, tx, uuid.MustParse(game.ID), game.GameDay, game.Money); err != nil {
+		return game, err
+	}
+
+	// Every game starts with a basic cart
+	err = menu.CreateStarterTruck(ctx, tx, game.ID)
+	return game, err
 }
 
 // GET /api/v1/games - List player's games
@@ -153,7 +187,7 @@ func HandleGet(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, game)
 }
 
-// PATCH /api/v1/games/{gameID} - Update game (save progress)
+// PATCH /api/v1/games/{gameID} - Update cosmetic fields (name, truck look)
 func HandleUpdate(w http.ResponseWriter, r *http.Request) {
 	claims, err := auth.GetClaimsFromContext(r.Context())
 	if err != nil {
@@ -163,83 +197,68 @@ func HandleUpdate(w http.ResponseWriter, r *http.Request) {
 
 	gameID := chi.URLParam(r, "gameID")
 
-	var req models.UpdateGameRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
+	req, violations, err := CheckUpdate(body)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	// Verify ownership
-	var exists bool
+	var game models.GameSession
 	err = database.Pool.QueryRow(ctx, `
-		SELECT EXISTS(SELECT 1 FROM game_sessions WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL)
-	`, gameID, claims.PlayerID).Scan(&exists)
-	if err != nil || !exists {
+		SELECT id, player_id, world_type, name, game_day, money, reputation,
+		       current_location, weather, status, stats, created_at, updated_at
+		FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+	`, gameID, claims.PlayerID).Scan(
+		&game.ID, &game.PlayerID, &game.WorldType, &game.Name,
+		&game.GameDay, &game.Money, &game.Reputation,
+		&game.CurrentLocation, &game.Weather, &game.Status,
+		&game.Stats, &game.CreatedAt, &game.UpdatedAt,
+	)
+	if err != nil {
 		respondError(w, http.StatusNotFound, "Game not found")
 		return
 	}
 
-	// Build dynamic update
-	query := "UPDATE game_sessions SET "
-	args := []interface{}{}
-	argNum := 1
-
-	if req.Name != nil {
-		query += "name = $" + itoa(argNum) + ", "
-		args = append(args, *req.Name)
-		argNum++
-	}
-	if req.GameDay != nil {
-		query += "game_day = $" + itoa(argNum) + ", "
-		args = append(args, *req.GameDay)
-		argNum++
-	}
-	if req.Money != nil {
-		query += "money = $" + itoa(argNum) + ", "
-		args = append(args, *req.Money)
-		argNum++
-	}
-	if req.Reputation != nil {
-		query += "reputation = $" + itoa(argNum) + ", "
-		args = append(args, *req.Reputation)
-		argNum++
-	}
-	if req.CurrentLocation != nil {
-		query += "current_location = $" + itoa(argNum) + ", "
-		args = append(args, *req.CurrentLocation)
-		argNum++
-	}
-	if req.Weather != nil {
-		query += "weather = $" + itoa(argNum) + ", "
-		args = append(args, *req.Weather)
-		argNum++
-	}
-	if req.Status != nil {
-		query += "status = $" + itoa(argNum) + ", "
-		args = append(args, *req.Status)
-		argNum++
-	}
-	if req.Stats != nil {
-		query += "stats = $" + itoa(argNum) + ", "
-		args = append(args, req.Stats)
-		argNum++
+	// Economic state is server-authoritative: reject and flag the attempt
+	if len(violations) > 0 {
+		if err := flagViolations(ctx, game, violations); err != nil {
+			log.Printf("Error flagging update of game %s: %v", gameID, err)
+		}
+		fields := make([]string, len(violations))
+		for i, v := range violations {
+			fields[i] = v.Field
+		}
+		respondJSON(w, http.StatusForbidden, map[string]any{
+			"error":  "Solo podés cambiar el nombre y el aspecto del truck",
+			"fields": fields,
+		})
+		return
 	}
 
-	if len(args) == 0 {
+	if req.Name == nil && len(req.Stats) == 0 {
 		respondError(w, http.StatusBadRequest, "No fields to update")
 		return
 	}
 
-	// Remove last comma and add WHERE clause
-	query = query[:len(query)-2] + " WHERE id = $" + itoa(argNum) + " AND player_id = $" + itoa(argNum+1) +
-		" RETURNING id, player_id, world_type, name, game_day, money, reputation, current_location, weather, status, stats, created_at, updated_at"
-	args = append(args, gameID, claims.PlayerID)
-
-	var game models.GameSession
-	err = database.Pool.QueryRow(ctx, query, args...).Scan(
+	err = database.Pool.QueryRow(ctx, `
+		UPDATE game_sessions
+		SET name = COALESCE($3, name),
+		    stats = COALESCE(stats, '{}'::jsonb) || COALESCE($4::jsonb, '{}'::jsonb),
+		    updated_at = NOW()
+		WHERE id = $1 AND player_id = $2
+		RETURNING id, player_id, world_type, name, game_day, money, reputation,
+		          current_location, weather, status, stats, created_at, updated_at
+	`, gameID, claims.PlayerID, req.Name, statsPatch(req.Stats)).Scan(
 		&game.ID, &game.PlayerID, &game.WorldType, &game.Name,
 		&game.GameDay, &game.Money, &game.Reputation,
 		&game.CurrentLocation, &game.Weather, &game.Status,
@@ -0,0 +1,52 @@
+package ingredients
+
+import (
+	"context"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Fallback is the catalog used by worlds that don't have their own
+const Fallback = "ingredients_cr"
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// CategoryFor is the parameters category of a world's ingredient catalog
+func CategoryFor(worldType string) string {
+	return "ingredients_" + models.WorldSuffix(worldType)
+}
+
+// Category resolves the ingredient catalog of a world, falling back to
+// Fallback when the world has no active ingredients
+func Category(ctx context.Context, db querier, worldType string) string {
+	category := CategoryFor(worldType)
+	if category == Fallback {
+		return category
+	}
+
+	var exists bool
+	err := db.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM parameters WHERE category = $1 AND is_active = true)
+	`, category).Scan(&exists)
+	if err != nil || !exists {
+		return Fallback
+	}
+	return category
+}
+
+// ForGame resolves the ingredient catalog of a game's world
+func ForGame(ctx context.Context, db querier, gameID uuid.UUID) (string, error) {
+	var worldType string
+	err := db.QueryRow(ctx, `
+		SELECT world_type FROM game_sessions WHERE id = $1
+	`, gameID).Scan(&worldType)
+	if err != nil {
+		return "", err
+	}
+	return Category(ctx, db, worldType), nil
+}
@@ -0,0 +1,17 @@
+package ingredients
+
+import "testing"
+
+func TestCategoryFor(t *testing.T) {
+	cases := map[string]string{
+		"costa_rica": "ingredients_cr",
+		"mexico":     "ingredients_mx",
+		"usa":        "ingredients_us",
+		"unknown":    Fallback,
+	}
+	for world, want := range cases {
+		if got := CategoryFor(world); got != want {
+			t.Errorf("CategoryFor(%q) = %q, want %q", world, got, want)
+		}
+	}
+}
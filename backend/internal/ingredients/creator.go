@@ -0,0 +1,49 @@
+package ingredients
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// CreatorPrefix marks the market code of an ingredient made in the creator:
+// creator_<content_creations.id>
+const CreatorPrefix = "creator_"
+
+// execer is satisfied by both *pgxpool.Pool and pgx.Tx
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// CreatorCode is the market code of a creator ingredient
+func CreatorCode(id uuid.UUID) string {
+	return CreatorPrefix + id.String()
+}
+
+// CreationID returns the content_creations id behind a market code, if the
+// ingredient comes from the creator
+func CreationID(code string) (uuid.UUID, bool) {
+	rest, ok := strings.CutPrefix(code, CreatorPrefix)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(rest)
+	return id, err == nil
+}
+
+// RecordUse counts a purchase of a creator ingredient so its creator sees
+// how much it's used. System ingredients are ignored.
+func RecordUse(ctx context.Context, db execer, code string) error {
+	id, ok := CreationID(code)
+	if !ok {
+		return nil
+	}
+	_, err := db.Exec(ctx, `
+		UPDATE content_creations
+		SET times_used = times_used + 1, last_used_at = NOW()
+		WHERE id = $1
+	`, id)
+	return err
+}
@@ -0,0 +1,22 @@
+package ingredients
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCreationID(t *testing.T) {
+	id := uuid.New()
+
+	got, ok := CreationID(CreatorCode(id))
+	if !ok || got != id {
+		t.Errorf("CreationID(CreatorCode(%s)) = %s, %v", id, got, ok)
+	}
+
+	for _, code := range []string{"arroz", id.String(), "creator_", "creator_not-a-uuid"} {
+		if _, ok := CreationID(code); ok {
+			t.Errorf("CreationID(%q) should not be a creator ingredient", code)
+		}
+	}
+}
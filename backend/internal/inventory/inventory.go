@@ -0,0 +1,121 @@
+package inventory
+
+import "sort"
+
+// Lot is a purchase of an ingredient still in stock (an inventory row)
+type Lot struct {
+	ID          string `json:"id"`
+	Code        string `json:"code"`
+	Quantity    int    `json:"quantity"`
+	CostPerUnit int    `json:"cost_per_unit"`
+	AcquiredDay int    `json:"acquired_day"`
+	ExpiresDay  *int   `json:"expires_day,omitempty"` // nil = doesn't spoil
+}
+
+// Take is how many units a consumption removes from a lot
+type Take struct {
+	LotID    string
+	Quantity int
+	Cost     int64
+}
+
+// Spoiled is what a perishable ingredient lost overnight
+type Spoiled struct {
+	Code     string `json:"code"`
+	Quantity int    `json:"quantity"`
+	Value    int64  `json:"value"` // what those units cost
+}
+
+// ExpiresDay returns the first day a lot bought on acquiredDay can't be
+// used anymore, or nil when the ingredient doesn't spoil
+func ExpiresDay(acquiredDay, shelfLifeDays int) *int {
+	if shelfLifeDays <= 0 {
+		return nil
+	}
+	day := acquiredDay + shelfLifeDays
+	return &day
+}
+
+// SortFIFO orders lots so the ones closest to spoiling are used first
+func SortFIFO(lots []Lot) {
+	sort.SliceStable(lots, func(i, j int) bool {
+		a, b := lots[i].ExpiresDay, lots[j].ExpiresDay
+		switch {
+		case a != nil && b == nil:
+			return true
+		case a == nil && b != nil:
+			return false
+		case a != nil && b != nil && *a != *b:
+			return *a < *b
+		}
+		return lots[i].AcquiredDay < lots[j].AcquiredDay
+	})
+}
+
+// Consume takes n units from lots in FIFO order. It returns what was taken
+// and how many units were missing.
+func Consume(lots []Lot, n int) ([]Take, int) {
+	SortFIFO(lots)
+
+	var takes []Take
+	for i := range lots {
+		if n == 0 {
+			break
+		}
+		q := min(lots[i].Quantity, n)
+		if q <= 0 {
+			continue
+		}
+		lots[i].Quantity -= q
+		n -= q
+		takes = append(takes, Take{LotID: lots[i].ID, Quantity: q, Cost: int64(q) * int64(lots[i].CostPerUnit)})
+	}
+	return takes, n
+}
+
+// Expired returns the lots that can't be used on the given day
+func Expired(lots []Lot, day int) []Lot {
+	var out []Lot
+	for _, l := range lots {
+		if l.ExpiresDay != nil && *l.ExpiresDay <= day && l.Quantity > 0 {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// Summarize groups expired lots by ingredient
+func Summarize(expired []Lot) []Spoiled {
+	byCode := map[string]*Spoiled{}
+	var codes []string
+	for _, l := range expired {
+		s, ok := byCode[l.Code]
+		if !ok {
+			s = &Spoiled{Code: l.Code}
+			byCode[l.Code] = s
+			codes = append(codes, l.Code)
+		}
+		s.Quantity += l.Quantity
+		s.Value += int64(l.Quantity) * int64(l.CostPerUnit)
+	}
+
+	sort.Strings(codes)
+	out := make([]Spoiled, 0, len(codes))
+	for _, c := range codes {
+		out = append(out, *byCode[c])
+	}
+	return out
+}
+
+// AverageCost is the weighted cost per unit of the lots in stock
+func AverageCost(lots []Lot) int {
+	var units, total int64
+	for _, l := range lots {
+		units += int64(l.Quantity)
+		total += int64(l.Quantity) * int64(l.CostPerUnit)
+	}
+	if units == 0 {
+		return 0
+	}
+	return int((total + units/2) / units)
+}
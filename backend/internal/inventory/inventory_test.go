@@ -0,0 +1,68 @@
+package inventory
+
+import "testing"
+
+func day(d int) *int { return &d }
+
+func TestExpiresDay(t *testing.T) {
+	if ExpiresDay(4, 0) != nil {
+		t.Error("non perishable ingredients should not expire")
+	}
+	if got := ExpiresDay(4, 3); got == nil || *got != 7 {
+		t.Errorf("expected day 7, got %v", got)
+	}
+}
+
+func TestConsumeFIFO(t *testing.T) {
+	lots := []Lot{
+		{ID: "new", Quantity: 5, CostPerUnit: 300, AcquiredDay: 3, ExpiresDay: day(6)},
+		{ID: "dry", Quantity: 5, CostPerUnit: 100, AcquiredDay: 1},
+		{ID: "old", Quantity: 2, CostPerUnit: 200, AcquiredDay: 1, ExpiresDay: day(4)},
+	}
+
+	takes, missing := Consume(lots, 4)
+	if missing != 0 {
+		t.Fatalf("expected no missing units, got %d", missing)
+	}
+	if len(takes) != 2 || takes[0].LotID != "old" || takes[0].Quantity != 2 || takes[1].LotID != "new" || takes[1].Quantity != 2 {
+		t.Errorf("expected the soonest to spoil first, got %+v", takes)
+	}
+	if takes[0].Cost != 400 || takes[1].Cost != 600 {
+		t.Errorf("unexpected costs %+v", takes)
+	}
+
+	_, missing = Consume(lots, 20)
+	if missing != 12 {
+		t.Errorf("expected 12 missing units, got %d", missing)
+	}
+}
+
+func TestSpoilage(t *testing.T) {
+	lots := []Lot{
+		{Code: "pescado", Quantity: 3, CostPerUnit: 1000, AcquiredDay: 1, ExpiresDay: day(3)},
+		{Code: "pescado", Quantity: 2, CostPerUnit: 1000, AcquiredDay: 2, ExpiresDay: day(4)},
+		{Code: "arroz", Quantity: 10, CostPerUnit: 200, AcquiredDay: 1},
+		{Code: "tomate", Quantity: 1, CostPerUnit: 150, AcquiredDay: 1, ExpiresDay: day(3)},
+	}
+
+	expired := Expired(lots, 3)
+	if len(expired) != 2 {
+		t.Fatalf("expected 2 expired lots, got %d", len(expired))
+	}
+
+	spoiled := Summarize(expired)
+	want := []Spoiled{{Code: "pescado", Quantity: 3, Value: 3000}, {Code: "tomate", Quantity: 1, Value: 150}}
+	if len(spoiled) != len(want) || spoiled[0] != want[0] || spoiled[1] != want[1] {
+		t.Errorf("Summarize = %+v, want %+v", spoiled, want)
+	}
+}
+
+func TestAverageCost(t *testing.T) {
+	lots := []Lot{{Quantity: 3, CostPerUnit: 100}, {Quantity: 1, CostPerUnit: 300}}
+	if got := AverageCost(lots); got != 150 {
+		t.Errorf("expected 150, got %d", got)
+	}
+	if got := AverageCost(nil); got != 0 {
+		t.Errorf("expected 0, got %d", got)
+	}
+}
@@ -0,0 +1,191 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Purchase is a batch of units entering the inventory
+type Purchase struct {
+	Code          string
+	Quantity      int
+	CostPerUnit   int
+	Day           int
+	ShelfLifeDays int
+	Source        string // purchase, starter, event, reward
+}
+
+// Add stores a new lot and updates the player's stock of the ingredient
+func Add(ctx context.Context, tx pgx.Tx, gameID, playerID uuid.UUID, p Purchase) (int, error) {
+	if p.Quantity <= 0 {
+		return 0, fmt.Errorf("invalid quantity %d", p.Quantity)
+	}
+	if p.Source == "" {
+		p.Source = "purchase"
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO inventory (session_id, item_type, quantity, cost_per_unit, acquired_day, expires_day)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, gameID, p.Code, p.Quantity, p.CostPerUnit, p.Day, ExpiresDay(p.Day, p.ShelfLifeDays))
+	if err != nil {
+		return 0, err
+	}
+
+	var stock int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO player_ingredients (session_id, player_id, ingredient_code, quantity, source)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (session_id, player_id, ingredient_code)
+		DO UPDATE SET quantity = player_ingredients.quantity + EXCLUDED.quantity
+		RETURNING quantity
+	`, gameID, playerID, p.Code, p.Quantity, p.Source).Scan(&stock)
+	return stock, err
+}
+
+// LoadLots returns the game's lots in stock grouped by ingredient, FIFO sorted
+func LoadLots(ctx context.Context, db querier, gameID uuid.UUID) (map[string][]Lot, error) {
+	rows, err := db.Query(ctx, `
+		SELECT id::text, item_type, quantity, cost_per_unit, acquired_day, expires_day
+		FROM inventory
+		WHERE session_id = $1 AND quantity > 0
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lots := map[string][]Lot{}
+	for rows.Next() {
+		var l Lot
+		if err := rows.Scan(&l.ID, &l.Code, &l.Quantity, &l.CostPerUnit, &l.AcquiredDay, &l.ExpiresDay); err != nil {
+			return nil, err
+		}
+		lots[l.Code] = append(lots[l.Code], l)
+	}
+	for _, l := range lots {
+		SortFIFO(l)
+	}
+	return lots, rows.Err()
+}
+
+// Stock sums the units in stock per ingredient
+func Stock(lots map[string][]Lot) map[string]int {
+	stock := make(map[string]int, len(lots))
+	for code, ls := range lots {
+		for _, l := range ls {
+			stock[code] += l.Quantity
+		}
+	}
+	return stock
+}
+
+// Use removes the ingredients consumed during a day, oldest lots first.
+// Returns the cost of the units taken.
+func Use(ctx context.Context, tx pgx.Tx, gameID uuid.UUID, used map[string]int) (int64, error) {
+	if len(used) == 0 {
+		return 0, nil
+	}
+
+	lots, err := LoadLots(ctx, tx, gameID)
+	if err != nil {
+		return 0, err
+	}
+
+	var cost int64
+	var all []Take
+	for code, n := range used {
+		takes, missing := Consume(lots[code], n)
+		if missing > 0 {
+			return 0, fmt.Errorf("not enough %s: missing %d", code, missing)
+		}
+		for _, t := range takes {
+			cost += t.Cost
+		}
+		all = append(all, takes...)
+	}
+
+	return cost, Remove(ctx, tx, gameID, all)
+}
+
+// Remove takes units out of their lots and updates the player's stock
+func Remove(ctx context.Context, tx pgx.Tx, gameID uuid.UUID, takes []Take) error {
+	batch := &pgx.Batch{}
+	for _, t := range takes {
+		batch.Queue(`
+			UPDATE inventory SET quantity = quantity - $2, updated_at = NOW() WHERE id = $1
+		`, t.LotID, t.Quantity)
+	}
+	batch.Queue(`DELETE FROM inventory WHERE session_id = $1 AND quantity <= 0`, gameID)
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+	return Sync(ctx, tx, gameID)
+}
+
+// SpoilExpired throws away the lots that can't be used on the given day
+func SpoilExpired(ctx context.Context, tx pgx.Tx, gameID uuid.UUID, day int) ([]Spoiled, error) {
+	lots, err := LoadLots(ctx, tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []Lot
+	for _, ls := range lots {
+		expired = append(expired, Expired(ls, day)...)
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM inventory
+		WHERE session_id = $1 AND expires_day IS NOT NULL AND expires_day <= $2
+	`, gameID, day)
+	if err != nil {
+		return nil, err
+	}
+
+	return Summarize(expired), Sync(ctx, tx, gameID)
+}
+
+// Sync recomputes player_ingredients.quantity from the lots in stock
+func Sync(ctx context.Context, tx pgx.Tx, gameID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE player_ingredients pi
+		SET quantity = COALESCE((
+			SELECT SUM(i.quantity) FROM inventory i
+			WHERE i.session_id = pi.session_id AND i.item_type = pi.ingredient_code
+		), 0)
+		WHERE pi.session_id = $1
+	`, gameID)
+	return err
+}
+
+// Restore replaces the game's lots with a snapshot taken by
+// day_snapshots.inventory (jsonb rows of the inventory table)
+func Restore(ctx context.Context, tx pgx.Tx, gameID uuid.UUID, snapshot []byte) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM inventory WHERE session_id = $1`, gameID); err != nil {
+		return err
+	}
+	if len(snapshot) > 0 {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO inventory
+			SELECT * FROM jsonb_populate_recordset(NULL::inventory, $2::jsonb)
+			WHERE session_id = $1
+		`, gameID, snapshot)
+		if err != nil {
+			return err
+		}
+	}
+	return Sync(ctx, tx, gameID)
+}
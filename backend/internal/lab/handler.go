@@ -9,9 +9,11 @@ import (
 	"strings"
 	"time"
 
+	aiconfig "github.com/alonsoalpizar/calleviva/backend/internal/ai/config"
 	"github.com/alonsoalpizar/calleviva/backend/internal/ai/orchestrator"
 	"github.com/alonsoalpizar/calleviva/backend/internal/ai/providers"
 	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ingredients"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
@@ -122,11 +124,22 @@ JSON (solo esto, nada más):
 	if err := svc.RegisterTemplate("dish_generation", template); err != nil {
 		log.Printf("Warning: Failed to register template: %v", err)
 	}
+	// Long, creative descriptions. The ai_templates parameter can move it to
+	// another provider or model.
+	svc.SetProfile("dish_generation", aiconfig.TemplateProfile{
+		MaxTokens:   800,
+		Temperature: 0.9,
+	})
 
 	h.aiService = svc
 	return nil
 }
 
+// AIService returns the AI service, nil until InitAI succeeds
+func (h *Handler) AIService() *orchestrator.Service {
+	return h.aiService
+}
+
 // GET /api/v1/games/{gameID}/lab/ingredients
 // Only returns ingredients the player owns
 func (h *Handler) GetIngredients(w http.ResponseWriter, r *http.Request) {
@@ -154,17 +167,24 @@ func (h *Handler) GetIngredients(w http.ResponseWriter, r *http.Request) {
 		FromCreator: []Ingredient{},
 	}
 
+	catalog, err := ingredients.ForGame(ctx, h.db, gameID)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Game not found"})
+		return
+	}
+
 	// Get only ingredients the player OWNS (from player_ingredients table)
 	rows, err := h.db.Query(ctx, `
 		SELECT p.code, p.name, p.icon, p.config
 		FROM parameters p
 		INNER JOIN player_ingredients pi ON pi.ingredient_code = p.code
-		WHERE p.category = 'ingredients_cr'
+		WHERE p.category = $3
 		  AND p.is_active = true
 		  AND pi.session_id = $1
 		  AND pi.player_id = $2
 		ORDER BY p.name
-	`, gameID, playerID)
+	`, gameID, playerID, catalog)
 	if err != nil {
 		log.Printf("Error fetching default ingredients: %v", err)
 	} else {
@@ -201,40 +221,22 @@ func (h *Handler) GetIngredients(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Get approved ingredients from creator
+	// Get approved creator ingredients sold in this game's market
 	creatorRows, err := h.db.Query(ctx, `
-		SELECT id, name, recipe
-		FROM content_creations
-		WHERE content_type = 'ingredientes' AND status = 'approved' AND is_active = true
-		ORDER BY created_at DESC
-	`)
+		SELECT code, name, COALESCE(icon, ''), COALESCE((config->>'cost')::int, 0)
+		FROM market_ingredients
+		WHERE category = $1 AND config->>'source' = 'creator'
+		ORDER BY name
+	`, catalog)
 	if err != nil {
 		log.Printf("Error fetching creator ingredients: %v", err)
 	} else {
 		defer creatorRows.Close()
 		for creatorRows.Next() {
-			var id uuid.UUID
-			var name string
-			var recipe json.RawMessage
-
-			if err := creatorRows.Scan(&id, &name, &recipe); err != nil {
+			ingredient := Ingredient{Type: "creator"}
+			if err := creatorRows.Scan(&ingredient.ID, &ingredient.Name, &ingredient.Icon, &ingredient.BaseCost); err != nil {
 				continue
 			}
-
-			ingredient := Ingredient{
-				ID:   id.String(),
-				Type: "creator",
-				Name: name,
-			}
-
-			// Try to extract icon from recipe
-			var recipeData map[string]interface{}
-			if json.Unmarshal(recipe, &recipeData) == nil {
-				if icon, ok := recipeData["icon"].(string); ok {
-					ingredient.Icon = icon
-				}
-			}
-
 			response.FromCreator = append(response.FromCreator, ingredient)
 		}
 	}
@@ -290,13 +292,10 @@ func (h *Handler) GenerateDish(w http.ResponseWriter, r *http.Request) {
 	var generated AIGeneratedDish
 
 	if h.aiService != nil && h.aiService.IsReady() {
-		resp, err := h.aiService.GenerateText(ctx, "dish_generation", map[string]interface{}{
+		resp, err := h.aiService.GenerateText(orchestrator.WithPlayer(ctx, playerID), "dish_generation", map[string]interface{}{
 			"Ingredientes": ingredientStr,
 			"Prompt":       req.PlayerPrompt,
-		}, providers.Config{
-			MaxTokens:   800, // Increased for elaborate descriptions
-			Temperature: 0.9, // More creative
-		})
+		}, providers.Config{})
 
 		if err != nil {
 			log.Printf("AI generation failed: %v", err)
@@ -318,12 +317,12 @@ func (h *Handler) GenerateDish(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate generated data
-	if generated.Price < 1500 {
-		generated.Price = 1500
-	}
-	if generated.Price > 15000 {
-		generated.Price = 15000
+	// Keep the price where customers neither complain nor suspect low quality
+	costs, err := h.ingredientCosts(ctx, gameID, req.Ingredients)
+	if err != nil {
+		log.Printf("Error loading ingredient costs of %s: %v", gameID, err)
 	}
+	generated.Price = dishPrice(generated.Price, expectedPrice(req.Ingredients, costs))
 	if generated.Popularity < 1 {
 		generated.Popularity = 50
 	}
@@ -641,6 +640,44 @@ func (h *Handler) checkAndUpdateUsage(ctx context.Context, playerID string) (int
 	return usage.MaxHits - usage.HitsUsed - 1, nil
 }
 
+// ingredientCosts looks up the market cost of the dish's ingredients. The
+// client's base_cost is only for display and is never trusted.
+func (h *Handler) ingredientCosts(ctx context.Context, gameID string, list []Ingredient) (map[string]int, error) {
+	id, err := uuid.Parse(gameID)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := ingredients.ForGame(ctx, h.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, len(list))
+	for i, ing := range list {
+		codes[i] = ing.ID
+	}
+	rows, err := h.db.Query(ctx, `
+		SELECT code, COALESCE((config->>'cost')::int, 0)
+		FROM market_ingredients
+		WHERE category = $1 AND code = ANY($2)
+	`, catalog, codes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	costs := map[string]int{}
+	for rows.Next() {
+		var code string
+		var cost int
+		if err := rows.Scan(&code, &cost); err != nil {
+			return nil, err
+		}
+		costs[code] = cost
+	}
+	return costs, rows.Err()
+}
+
 // fallbackGeneration creates a dish with natural tone without AI
 func (h *Handler) fallbackGeneration(ingredients []string, prompt string) AIGeneratedDish {
 	// Simple, memorable names
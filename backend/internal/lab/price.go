@@ -0,0 +1,33 @@
+package lab
+
+import "github.com/alonsoalpizar/calleviva/backend/internal/demand"
+
+// Absolute bounds of a dish price, for when ingredient costs are unknown
+const (
+	minDishPrice = 1500
+	maxDishPrice = 15000
+)
+
+// expectedPrice is what customers expect to pay for a dish: the GDD
+// suggested price over the market cost of its ingredients (0 when none is
+// known). Ingredients missing from costs count as free.
+func expectedPrice(list []Ingredient, costs map[string]int) int {
+	cost := 0
+	for _, ing := range list {
+		cost += costs[ing.ID]
+	}
+	return demand.SuggestedPrice(cost)
+}
+
+// dishPrice keeps a generated price where customers neither complain nor
+// suspect low quality, and always within the absolute bounds
+func dishPrice(price, expected int) int {
+	price = demand.ClampToFairBand(price, expected)
+	if price < minDishPrice {
+		return minDishPrice
+	}
+	if price > maxDishPrice {
+		return maxDishPrice
+	}
+	return price
+}
@@ -0,0 +1,45 @@
+package lab
+
+import (
+	"testing"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/demand"
+)
+
+func TestExpectedPriceIgnoresClientCost(t *testing.T) {
+	list := []Ingredient{
+		{ID: "arroz", BaseCost: 999999},
+		{ID: "frijoles", BaseCost: 0},
+		{ID: "desconocido", BaseCost: 5000},
+	}
+	costs := map[string]int{"arroz": 400, "frijoles": 600}
+
+	if got, want := expectedPrice(list, costs), demand.SuggestedPrice(1000); got != want {
+		t.Errorf("expectedPrice = %d, want %d from the market costs", got, want)
+	}
+}
+
+func TestDishPriceKeepsAbsoluteBounds(t *testing.T) {
+	cases := []struct {
+		price, expected, want int
+	}{
+		{price: 0, expected: 0, want: minDishPrice},
+		{price: 1, expected: 0, want: minDishPrice},
+		{price: 900000, expected: 0, want: maxDishPrice},
+		{price: 4000, expected: 0, want: 4000},
+	}
+	for _, c := range cases {
+		if got := dishPrice(c.price, c.expected); got != c.want {
+			t.Errorf("dishPrice(%d, %d) = %d, want %d", c.price, c.expected, got, c.want)
+		}
+	}
+
+	expected := demand.SuggestedPrice(2000)
+	lo, hi := demand.FairBand(expected)
+	if got := dishPrice(1, expected); got != max(lo, minDishPrice) {
+		t.Errorf("cheap dish = %d, want the fair band floor %d", got, lo)
+	}
+	if got := dishPrice(900000, expected); got != min(hi, maxDishPrice) {
+		t.Errorf("expensive dish = %d, want the fair band ceiling %d", got, hi)
+	}
+}
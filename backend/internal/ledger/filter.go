@@ -0,0 +1,103 @@
+package ledger
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Page sizes for GET /ledger
+const (
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+// Filter narrows down a game's ledger
+type Filter struct {
+	Reasons []string
+	Account string
+	FromDay int    // 0 = since the start
+	ToDay   int    // 0 = up to today
+	Flow    string // "in", "out" or "" for both
+	Limit   int
+	Offset  int
+}
+
+// ParseFilter reads the filter from the query string:
+// ?reason=purchase,rent&account=inventory&from_day=2&to_day=5&flow=out&limit=50&offset=0
+func ParseFilter(q url.Values) (Filter, error) {
+	f := Filter{Account: q.Get("account"), Flow: q.Get("flow"), Limit: defaultLimit}
+
+	if v := q.Get("reason"); v != "" {
+		for _, reason := range strings.Split(v, ",") {
+			reason = strings.TrimSpace(reason)
+			if !slices.Contains(Reasons, reason) {
+				return f, fmt.Errorf("Motivo desconocido: %s", reason)
+			}
+			f.Reasons = append(f.Reasons, reason)
+		}
+	}
+	if f.Flow != "" && f.Flow != "in" && f.Flow != "out" {
+		return f, fmt.Errorf("flow tiene que ser in u out")
+	}
+
+	ints := []struct {
+		name string
+		dst  *int
+	}{
+		{"from_day", &f.FromDay},
+		{"to_day", &f.ToDay},
+		{"limit", &f.Limit},
+		{"offset", &f.Offset},
+	}
+	for _, p := range ints {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, fmt.Errorf("%s tiene que ser un número positivo", p.name)
+		}
+		*p.dst = n
+	}
+	if f.ToDay > 0 && f.FromDay > f.ToDay {
+		return f, fmt.Errorf("from_day no puede ser mayor que to_day")
+	}
+	if f.Limit == 0 || f.Limit > maxLimit {
+		f.Limit = maxLimit
+	}
+	return f, nil
+}
+
+// Where builds the SQL conditions for the filter. Parameter $1 is the game.
+func (f Filter) Where() (string, []any) {
+	where := "session_id = $1"
+	args := []any{}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where += " AND " + fmt.Sprintf(cond, len(args)+1)
+	}
+
+	if len(f.Reasons) > 0 {
+		add("reason = ANY($%d)", f.Reasons)
+	}
+	if f.Account != "" {
+		add("(debit_account = $%[1]d OR credit_account = $%[1]d)", f.Account)
+	}
+	if f.FromDay > 0 {
+		add("game_day >= $%d", f.FromDay)
+	}
+	if f.ToDay > 0 {
+		add("game_day <= $%d", f.ToDay)
+	}
+	switch f.Flow {
+	case "in":
+		where += " AND amount > 0"
+	case "out":
+		where += " AND amount < 0"
+	}
+	return where, args
+}
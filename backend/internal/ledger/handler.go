@@ -0,0 +1,140 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Handler struct {
+	db *pgxpool.Pool
+}
+
+func NewHandler(db *pgxpool.Pool) *Handler {
+	return &Handler{db: db}
+}
+
+// SetupRoutes mounts ledger routes (requires auth)
+func (h *Handler) SetupRoutes(r chi.Router) {
+	r.Get("/ledger", h.GetLedger)
+}
+
+// GET /api/v1/games/{gameID}/ledger
+// Lists where the game's money came from and went, newest first
+func (h *Handler) GetLedger(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	filter, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+		return
+	}
+
+	resp := LedgerResponse{
+		Transactions: []Transaction{},
+		Summary:      Summary{ByReason: map[string]int64{}},
+		Limit:        filter.Limit,
+		Offset:       filter.Offset,
+	}
+	err = h.db.QueryRow(ctx, `
+		SELECT money FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+	`, gameID, playerID).Scan(&resp.Balance)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	where, args := filter.Where()
+	args = append([]any{gameID}, args...)
+
+	// Totals over the whole filter, not just the page
+	rows, err := h.db.Query(ctx, `
+		SELECT reason, COUNT(*)::int,
+		       COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0)::bigint,
+		       COALESCE(-SUM(amount) FILTER (WHERE amount < 0), 0)::bigint
+		FROM money_transactions
+		WHERE `+where+`
+		GROUP BY reason
+	`, args...)
+	if err != nil {
+		log.Printf("Error summarizing ledger of %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar los movimientos"})
+		return
+	}
+	for rows.Next() {
+		var reason string
+		var count int
+		var income, expenses int64
+		if err := rows.Scan(&reason, &count, &income, &expenses); err != nil {
+			rows.Close()
+			log.Printf("Error scanning ledger summary: %v", err)
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Error al cargar los movimientos"})
+			return
+		}
+		resp.Total += count
+		resp.Summary.Income += income
+		resp.Summary.Expenses += expenses
+		resp.Summary.ByReason[reason] = income - expenses
+	}
+	rows.Close()
+	resp.Summary.Net = resp.Summary.Income - resp.Summary.Expenses
+
+	page := len(args)
+	rows, err = h.db.Query(ctx, `
+		SELECT id, game_day, amount, balance_after, debit_account, credit_account,
+		       reason, reference, description, created_at
+		FROM money_transactions
+		WHERE `+where+`
+		ORDER BY created_at DESC, game_day DESC
+		LIMIT $`+fmt.Sprint(page+1)+` OFFSET $`+fmt.Sprint(page+2),
+		append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		log.Printf("Error fetching ledger of %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar los movimientos"})
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Transaction
+		err := rows.Scan(&t.ID, &t.GameDay, &t.Amount, &t.BalanceAfter, &t.DebitAccount,
+			&t.CreditAccount, &t.Reason, &t.Reference, &t.Description, &t.CreatedAt)
+		if err != nil {
+			log.Printf("Error scanning transaction: %v", err)
+			continue
+		}
+		resp.Transactions = append(resp.Transactions, t)
+	}
+
+	render.JSON(w, r, resp)
+}
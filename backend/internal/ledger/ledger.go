@@ -0,0 +1,151 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Accounts. The player's money is AccountCash; the others say where it came
+// from or where it went.
+const (
+	AccountCash        = "cash"
+	AccountCapital     = "capital" // starting money
+	AccountInventory   = "inventory"
+	AccountSales       = "sales"
+	AccountRent        = "rent"
+	AccountUpgrades    = "upgrades"
+	AccountLoans       = "loans"
+	AccountWages       = "wages"
+	AccountAdjustments = "adjustments"
+)
+
+// Reasons
+const (
+	ReasonOpening       = "opening"        // starting money
+	ReasonPurchase      = "purchase"       // ingredients bought at the market
+	ReasonSellBack      = "sell_back"      // ingredients returned to the market
+	ReasonRent          = "rent"           // location paid for the day
+	ReasonSales         = "sales"          // the day's sales
+	ReasonSupplies      = "supplies"       // ingredients of catalog products, paid during the day
+	ReasonUpgrade       = "upgrade"        // upgrades and equipment
+	ReasonLoan          = "loan"           // emergency loan received
+	ReasonLoanRepayment = "loan_repayment" // part of the day's profit
+	ReasonWages         = "wages"          // staff
+	ReasonAdjustment    = "adjustment"     // balance changed by hand
+)
+
+// Reasons lists the valid reasons, for filtering
+var Reasons = []string{
+	ReasonOpening, ReasonPurchase, ReasonSellBack, ReasonRent, ReasonSales, ReasonSupplies,
+	ReasonUpgrade, ReasonLoan, ReasonLoanRepayment, ReasonWages, ReasonAdjustment,
+}
+
+// Entry is a change to a game's money. Amount is what it does to the cash:
+// positive comes in, negative goes out.
+type Entry struct {
+	GameDay     int
+	Amount      int64
+	Account     string // the other side of the movement
+	Reason      string
+	Reference   string
+	Description string
+}
+
+// Accounts returns the debited and credited accounts of an entry: money
+// coming in debits cash, money going out credits it
+func (e Entry) Accounts() (debit, credit string) {
+	if e.Amount >= 0 {
+		return AccountCash, e.Account
+	}
+	return e.Account, AccountCash
+}
+
+// Total adds up what a set of entries does to the cash
+func Total(entries []Entry) int64 {
+	var total int64
+	for _, e := range entries {
+		total += e.Amount
+	}
+	return total
+}
+
+// Post applies an entry to the game's money and records it. Returns the new
+// balance. Every change to game_sessions.money goes through here.
+func Post(ctx context.Context, tx pgx.Tx, gameID uuid.UUID, e Entry) (int64, error) {
+	if e.Account == "" || e.Reason == "" {
+		return 0, fmt.Errorf("ledger entry needs an account and a reason")
+	}
+
+	var balance int64
+	err := tx.QueryRow(ctx, `
+		UPDATE game_sessions SET money = money + $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING money
+	`, gameID, e.Amount).Scan(&balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, record(ctx, tx, gameID, e, balance)
+}
+
+// PostAll posts several entries in order, skipping the ones that don't move
+// any money. Returns the final balance.
+func PostAll(ctx context.Context, tx pgx.Tx, gameID uuid.UUID, entries []Entry) (int64, error) {
+	var balance int64
+	posted := false
+	for _, e := range entries {
+		if e.Amount == 0 {
+			continue
+		}
+		b, err := Post(ctx, tx, gameID, e)
+		if err != nil {
+			return 0, err
+		}
+		balance, posted = b, true
+	}
+	if !posted {
+		err := tx.QueryRow(ctx, `SELECT money FROM game_sessions WHERE id = $1`, gameID).Scan(&balance)
+		return balance, err
+	}
+	return balance, nil
+}
+
+// Open records the starting money of a new game, which is already in the
+// session row
+func Open(ctx context.Context, tx pgx.Tx, gameID uuid.UUID, day int, money int64) error {
+	return record(ctx, tx, gameID, Entry{
+		GameDay:     day,
+		Amount:      money,
+		Account:     AccountCapital,
+		Reason:      ReasonOpening,
+		Description: "Capital inicial",
+	}, money)
+}
+
+// Rewind forgets the entries posted since a day snapshot was taken, when the
+// session goes back to it
+func Rewind(ctx context.Context, tx pgx.Tx, gameID uuid.UUID, day int) error {
+	_, err := tx.Exec(ctx, `
+		DELETE FROM money_transactions t
+		USING day_snapshots s
+		WHERE t.session_id = $1 AND s.session_id = t.session_id AND s.game_day = $2
+		  AND t.created_at >= s.created_at
+	`, gameID, day)
+	return err
+}
+
+// record inserts an entry. clock_timestamp keeps the entries of one
+// transaction in the order they were posted.
+func record(ctx context.Context, tx pgx.Tx, gameID uuid.UUID, e Entry, balance int64) error {
+	debit, credit := e.Accounts()
+	_, err := tx.Exec(ctx, `
+		INSERT INTO money_transactions
+		(session_id, game_day, amount, balance_after, debit_account, credit_account, reason, reference, description, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''), clock_timestamp())
+	`, gameID, e.GameDay, e.Amount, balance, debit, credit, e.Reason, e.Reference, e.Description)
+	return err
+}
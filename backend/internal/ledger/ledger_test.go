@@ -0,0 +1,61 @@
+package ledger
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestEntryAccounts(t *testing.T) {
+	debit, credit := Entry{Amount: 500, Account: AccountInventory}.Accounts()
+	if debit != AccountCash || credit != AccountInventory {
+		t.Errorf("money coming in should debit cash, got %s/%s", debit, credit)
+	}
+	debit, credit = Entry{Amount: -500, Account: AccountRent}.Accounts()
+	if debit != AccountRent || credit != AccountCash {
+		t.Errorf("money going out should credit cash, got %s/%s", debit, credit)
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	f, err := ParseFilter(url.Values{
+		"reason":   {"purchase, rent"},
+		"from_day": {"2"},
+		"to_day":   {"5"},
+		"flow":     {"out"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Reasons) != 2 || f.FromDay != 2 || f.ToDay != 5 || f.Limit != defaultLimit {
+		t.Errorf("unexpected filter %+v", f)
+	}
+
+	where, args := f.Where()
+	want := "session_id = $1 AND reason = ANY($2) AND game_day >= $3 AND game_day <= $4 AND amount < 0"
+	if where != want || len(args) != 3 {
+		t.Errorf("unexpected conditions %q with %d args", where, len(args))
+	}
+
+	if f, _ := ParseFilter(url.Values{"limit": {"5000"}}); f.Limit != maxLimit {
+		t.Errorf("expected the limit capped at %d, got %d", maxLimit, f.Limit)
+	}
+
+	bad := []url.Values{
+		{"reason": {"lottery"}},
+		{"flow": {"sideways"}},
+		{"from_day": {"abc"}},
+		{"from_day": {"6"}, "to_day": {"2"}},
+	}
+	for _, q := range bad {
+		if _, err := ParseFilter(q); err == nil {
+			t.Errorf("expected %v to be rejected", q)
+		}
+	}
+}
+
+func TestAccountFilterMatchesBothSides(t *testing.T) {
+	where, args := Filter{Account: AccountRent}.Where()
+	if where != "session_id = $1 AND (debit_account = $2 OR credit_account = $2)" || len(args) != 1 {
+		t.Errorf("unexpected conditions %q", where)
+	}
+}
@@ -0,0 +1,39 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Transaction is a row of money_transactions
+type Transaction struct {
+	ID            uuid.UUID `json:"id"`
+	GameDay       int       `json:"game_day"`
+	Amount        int64     `json:"amount"` // + comes in, - goes out
+	BalanceAfter  int64     `json:"balance_after"`
+	DebitAccount  string    `json:"debit_account"`
+	CreditAccount string    `json:"credit_account"`
+	Reason        string    `json:"reason"`
+	Reference     *string   `json:"reference,omitempty"`
+	Description   *string   `json:"description,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Summary adds up the filtered transactions
+type Summary struct {
+	Income   int64            `json:"income"`
+	Expenses int64            `json:"expenses"` // positive
+	Net      int64            `json:"net"`
+	ByReason map[string]int64 `json:"by_reason"`
+}
+
+// LedgerResponse is the response for GET /ledger
+type LedgerResponse struct {
+	Transactions []Transaction `json:"transactions"`
+	Summary      Summary       `json:"summary"`
+	Balance      int64         `json:"balance"` // current money
+	Total        int           `json:"total"`   // transactions matching the filter
+	Limit        int           `json:"limit"`
+	Offset       int           `json:"offset"`
+}
@@ -0,0 +1,201 @@
+package location
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ledger"
+	"github.com/alonsoalpizar/calleviva/backend/internal/models"
+	"github.com/alonsoalpizar/calleviva/backend/internal/reputation"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pgLockNotAvailable is returned by FOR UPDATE NOWAIT when another
+// transaction (a running day) holds the session row
+const pgLockNotAvailable = "55P03"
+
+type Handler struct {
+	db *pgxpool.Pool
+}
+
+func NewHandler(db *pgxpool.Pool) *Handler {
+	return &Handler{db: db}
+}
+
+// SetupRoutes mounts location routes (requires auth)
+func (h *Handler) SetupRoutes(r chi.Router) {
+	r.Post("/location/set", h.SetLocation)
+}
+
+// POST /api/v1/games/{gameID}/location/set
+// Picks the location for the current day and pays its rent
+func (h *Handler) SetLocation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	var req SetLocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.LocationID == "" {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "location_id requerido"})
+		return
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error de transacción"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// NOWAIT: if the day is being simulated the row is locked, so fail fast
+	var worldType, status string
+	var gameDay, rep int
+	var money int64
+	var currentLocation *string
+	var locationDay *int
+	err = tx.QueryRow(ctx, `
+		SELECT world_type, game_day, money, reputation, current_location, location_day,
+		       COALESCE(status, 'active')
+		FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+		FOR UPDATE NOWAIT
+	`, gameID, playerID).Scan(&worldType, &gameDay, &money, &rep, &currentLocation, &locationDay, &status)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgLockNotAvailable {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, map[string]string{"error": "El día está en curso, no podés cambiar de ubicación"})
+			return
+		}
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	if status != "active" {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "La partida no está activa"})
+		return
+	}
+
+	loc, err := loadLocation(ctx, tx, models.WorldSuffix(worldType), req.LocationID)
+	if err == pgx.ErrNoRows {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Ubicación no válida"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading location %s: %v", req.LocationID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar la ubicación"})
+		return
+	}
+
+	if rep < loc.MinReputation {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{
+			"error": fmt.Sprintf("Necesitás %d de reputación (nivel %s) para instalarte en %s",
+				loc.MinReputation, reputation.TierFor(loc.MinReputation).Name, loc.Name),
+		})
+		return
+	}
+
+	// Already paid for this spot today: nothing to charge. The day's rent
+	// covers one spot, so moving somewhere else has to wait for tomorrow.
+	if currentLocation != nil && locationDay != nil && *locationDay == gameDay {
+		if *currentLocation == loc.ID {
+			render.JSON(w, r, SetLocationResponse{Success: true, Location: loc, CostApplied: 0, NewMoney: money})
+			return
+		}
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Ya pagaste el alquiler de hoy; podés cambiar de ubicación mañana"})
+		return
+	}
+
+	if money < loc.Cost {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "No tenés suficiente dinero"})
+		return
+	}
+
+	newMoney, err := ledger.Post(ctx, tx, gameID, ledger.Entry{
+		GameDay:     gameDay,
+		Amount:      -loc.Cost,
+		Account:     ledger.AccountRent,
+		Reason:      ledger.ReasonRent,
+		Reference:   loc.ID,
+		Description: "Alquiler en " + loc.Name,
+	})
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al actualizar dinero"})
+		return
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE game_sessions
+		SET current_location = $2, location_day = game_day, updated_at = NOW()
+		WHERE id = $1
+	`, gameID, loc.ID)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al actualizar la ubicación"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al confirmar"})
+		return
+	}
+
+	render.JSON(w, r, SetLocationResponse{
+		Success:     true,
+		Location:    loc,
+		CostApplied: loc.Cost,
+		NewMoney:    newMoney,
+	})
+}
+
+// loadLocation reads a location and its requirements from locations_<suffix>
+func loadLocation(ctx context.Context, tx pgx.Tx, suffix, code string) (Location, error) {
+	var loc Location
+	var rent float64
+	err := tx.QueryRow(ctx, `
+		SELECT code, name, COALESCE(icon, ''),
+		       COALESCE((config->>'rent')::float8, 0),
+		       COALESCE((config->>'min_reputation')::int, 0),
+		       COALESCE(config->>'requirement', '')
+		FROM parameters
+		WHERE category = $1 AND code = $2 AND is_active = true
+	`, "locations_"+suffix, code).Scan(&loc.ID, &loc.Name, &loc.Icon, &rent, &loc.MinReputation, &loc.Requirement)
+	loc.Cost = int64(rent)
+	return loc, err
+}
@@ -0,0 +1,24 @@
+package location
+
+// Location is a spot from the world's locations_* parameters
+type Location struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Icon          string `json:"icon,omitempty"`
+	Cost          int64  `json:"cost"`
+	MinReputation int    `json:"min_reputation"`
+	Requirement   string `json:"requirement,omitempty"`
+}
+
+// SetLocationRequest is the request body for POST /location/set
+type SetLocationRequest struct {
+	LocationID string `json:"location_id"`
+}
+
+// SetLocationResponse is the response for POST /location/set
+type SetLocationResponse struct {
+	Success     bool     `json:"success"`
+	Location    Location `json:"location"`
+	CostApplied int64    `json:"cost_applied"`
+	NewMoney    int64    `json:"new_money"`
+}
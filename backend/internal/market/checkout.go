@@ -0,0 +1,302 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ingredients"
+	"github.com/alonsoalpizar/calleviva/backend/internal/inventory"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ledger"
+	"github.com/alonsoalpizar/calleviva/backend/internal/pricing"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxCartLines caps the distinct ingredients in one order
+const maxCartLines = 30
+
+// BulkTier is a quantity discount: buying at least MinQuantity units of an
+// ingredient in one order takes Discount off that line
+type BulkTier struct {
+	MinQuantity int     `json:"min_quantity"`
+	Discount    float64 `json:"discount"`
+}
+
+// BulkTiers are the checkout discounts, from the smallest order up
+var BulkTiers = []BulkTier{
+	{MinQuantity: 10, Discount: 0.05},
+	{MinQuantity: 25, Discount: 0.10},
+	{MinQuantity: 50, Discount: 0.15},
+}
+
+// BulkDiscount returns the discount rate for a line of the given quantity
+func BulkDiscount(quantity int) float64 {
+	rate := 0.0
+	for _, t := range BulkTiers {
+		if quantity >= t.MinQuantity {
+			rate = t.Discount
+		}
+	}
+	return rate
+}
+
+// PriceLine prices a checkout line at the unit price with its bulk discount
+func PriceLine(unitPrice, quantity int) (subtotal, discount int64) {
+	subtotal = int64(unitPrice) * int64(quantity)
+	discount = int64(math.Round(float64(subtotal) * BulkDiscount(quantity)))
+	return subtotal, discount
+}
+
+// MergeCart validates a basket and adds up repeated ingredients, keeping
+// the order they first appear in
+func MergeCart(items []CartItem) ([]CartItem, error) {
+	if len(items) == 0 {
+		return nil, errors.New("El carrito está vacío")
+	}
+
+	var merged []CartItem
+	index := map[string]int{}
+	for _, item := range items {
+		if item.IngredientCode == "" {
+			return nil, errors.New("ingredient_code is required")
+		}
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("La cantidad de %s tiene que ser mayor a 0", item.IngredientCode)
+		}
+		if i, ok := index[item.IngredientCode]; ok {
+			merged[i].Quantity += item.Quantity
+		} else {
+			index[item.IngredientCode] = len(merged)
+			merged = append(merged, item)
+		}
+	}
+
+	if len(merged) > maxCartLines {
+		return nil, fmt.Errorf("El carrito admite hasta %d ingredientes", maxCartLines)
+	}
+	for _, item := range merged {
+		if item.Quantity > maxBuyQuantity {
+			return nil, fmt.Errorf("La cantidad de %s tiene que estar entre 1 y %d", item.IngredientCode, maxBuyQuantity)
+		}
+	}
+	return merged, nil
+}
+
+// POST /api/v1/games/{gameID}/market/checkout
+// Buys a whole basket in one transaction: every line goes through or none does
+func (h *Handler) Checkout(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	var req CheckoutRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	items, err := MergeCart(req.Items)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+		return
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error de transacción"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the session until the whole order is in
+	var playerMoney int64
+	var gameDay int
+	var weather, worldType string
+	err = tx.QueryRow(ctx, `
+		SELECT money, game_day, COALESCE(weather, 'sunny'), world_type FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND status = 'active'
+		FOR UPDATE
+	`, gameID, playerID).Scan(&playerMoney, &gameDay, &weather, &worldType)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	category := ingredients.Category(ctx, tx, worldType)
+	sheet, err := pricing.Load(ctx, tx, gameID, gameDay, weather, category)
+	if err != nil {
+		log.Printf("Error loading market prices: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar precios"})
+		return
+	}
+
+	catalog, err := loadCartIngredients(ctx, tx, category, items)
+	if err != nil {
+		log.Printf("Error loading cart ingredients: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar ingredientes"})
+		return
+	}
+
+	// Validate and price the whole basket before touching anything
+	resp := CheckoutResponse{Success: true, Lines: make([]CheckoutLine, 0, len(items))}
+	for _, item := range items {
+		ing, ok := catalog[item.IngredientCode]
+		quote, priced := sheet[item.IngredientCode]
+		if !ok || !priced {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, map[string]string{
+				"error":           "Ingrediente no encontrado",
+				"ingredient_code": item.IngredientCode,
+			})
+			return
+		}
+
+		subtotal, discount := PriceLine(quote.Price, item.Quantity)
+		resp.Lines = append(resp.Lines, CheckoutLine{
+			IngredientCode: item.IngredientCode,
+			Name:           ing.Name,
+			Quantity:       item.Quantity,
+			UnitPrice:      quote.Price,
+			Subtotal:       subtotal,
+			Discount:       discount,
+			Total:          subtotal - discount,
+			ExpiresDay:     inventory.ExpiresDay(gameDay, ing.ShelfLife),
+		})
+		resp.Subtotal += subtotal
+		resp.Discount += discount
+	}
+	resp.TotalCost = resp.Subtotal - resp.Discount
+
+	if playerMoney < resp.TotalCost {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": fmt.Sprintf("No tenés suficiente dinero. Necesitás ₡%d", resp.TotalCost),
+		})
+		return
+	}
+
+	for i, line := range resp.Lines {
+		resp.NewBalance, err = ledger.Post(ctx, tx, gameID, ledger.Entry{
+			GameDay:     gameDay,
+			Amount:      -line.Total,
+			Account:     ledger.AccountInventory,
+			Reason:      ledger.ReasonPurchase,
+			Reference:   line.IngredientCode,
+			Description: fmt.Sprintf("%d × %s (carrito)", line.Quantity, line.Name),
+		})
+		if err != nil {
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Error al actualizar dinero"})
+			return
+		}
+
+		// The lot keeps what each unit actually cost after the discount
+		costPerUnit := int(math.Round(float64(line.Total) / float64(line.Quantity)))
+		stock, err := inventory.Add(ctx, tx, gameID, playerID, inventory.Purchase{
+			Code:          line.IngredientCode,
+			Quantity:      line.Quantity,
+			CostPerUnit:   costPerUnit,
+			Day:           gameDay,
+			ShelfLifeDays: catalog[line.IngredientCode].ShelfLife,
+		})
+		if err != nil {
+			log.Printf("Error adding %s to inventory: %v", line.IngredientCode, err)
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{
+				"error":           "Error al agregar ingrediente",
+				"ingredient_code": line.IngredientCode,
+			})
+			return
+		}
+		resp.Lines[i].Stock = stock
+
+		if err := pricing.RecordPurchase(ctx, tx, gameID, gameDay, line.IngredientCode, line.Quantity); err != nil {
+			log.Printf("Error recording purchase volume: %v", err)
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Error al completar compra"})
+			return
+		}
+
+		if err := ingredients.RecordUse(ctx, tx, line.IngredientCode); err != nil {
+			log.Printf("Error recording use of %s: %v", line.IngredientCode, err)
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Error al completar compra"})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al completar compra"})
+		return
+	}
+
+	resp.Message = fmt.Sprintf("¡Compraste %d ingredientes!", len(resp.Lines))
+	if resp.Discount > 0 {
+		resp.Message = fmt.Sprintf("¡Compraste %d ingredientes y te ahorraste ₡%d!", len(resp.Lines), resp.Discount)
+	}
+	render.JSON(w, r, resp)
+}
+
+// cartIngredient is what checkout needs from a market ingredient
+type cartIngredient struct {
+	Name      string
+	ShelfLife int
+}
+
+// loadCartIngredients reads the active ingredients in the basket
+func loadCartIngredients(ctx context.Context, tx pgx.Tx, category string, items []CartItem) (map[string]cartIngredient, error) {
+	codes := make([]string, len(items))
+	for i, item := range items {
+		codes[i] = item.IngredientCode
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT code, name, COALESCE((config->>'shelf_life_days')::int, 0)
+		FROM market_ingredients
+		WHERE category = $1 AND code = ANY($2) AND is_active = true
+	`, category, codes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := map[string]cartIngredient{}
+	for rows.Next() {
+		var code string
+		var ing cartIngredient
+		if err := rows.Scan(&code, &ing.Name, &ing.ShelfLife); err != nil {
+			return nil, err
+		}
+		found[code] = ing
+	}
+	return found, rows.Err()
+}
@@ -0,0 +1,48 @@
+package market
+
+import "testing"
+
+func TestBulkDiscount(t *testing.T) {
+	cases := map[int]float64{1: 0, 9: 0, 10: 0.05, 24: 0.05, 25: 0.10, 50: 0.15, 100: 0.15}
+	for quantity, want := range cases {
+		if got := BulkDiscount(quantity); got != want {
+			t.Errorf("BulkDiscount(%d) = %v, want %v", quantity, got, want)
+		}
+	}
+}
+
+func TestPriceLine(t *testing.T) {
+	subtotal, discount := PriceLine(350, 30)
+	if subtotal != 10500 || discount != 1050 {
+		t.Errorf("expected 10500 with 1050 off, got %d with %d off", subtotal, discount)
+	}
+	if _, discount := PriceLine(350, 3); discount != 0 {
+		t.Errorf("small lines should not get a discount, got %d", discount)
+	}
+}
+
+func TestMergeCart(t *testing.T) {
+	items, err := MergeCart([]CartItem{
+		{IngredientCode: "tomate", Quantity: 4},
+		{IngredientCode: "queso", Quantity: 2},
+		{IngredientCode: "tomate", Quantity: 6},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].IngredientCode != "tomate" || items[0].Quantity != 10 {
+		t.Errorf("expected repeated lines merged in order, got %+v", items)
+	}
+
+	bad := [][]CartItem{
+		nil,
+		{{IngredientCode: "", Quantity: 1}},
+		{{IngredientCode: "tomate", Quantity: 0}},
+		{{IngredientCode: "tomate", Quantity: 60}, {IngredientCode: "tomate", Quantity: 60}},
+	}
+	for _, cart := range bad {
+		if _, err := MergeCart(cart); err == nil {
+			t.Errorf("expected %+v to be rejected", cart)
+		}
+	}
+}
@@ -9,12 +9,19 @@ import (
 	"time"
 
 	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ingredients"
+	"github.com/alonsoalpizar/calleviva/backend/internal/inventory"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ledger"
+	"github.com/alonsoalpizar/calleviva/backend/internal/pricing"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// maxBuyQuantity caps a single purchase
+const maxBuyQuantity = 100
+
 type Handler struct {
 	db *pgxpool.Pool
 }
@@ -29,6 +36,8 @@ func (h *Handler) SetupRoutes(r chi.Router) {
 		r.Get("/catalog", h.GetCatalog)
 		r.Get("/inventory", h.GetInventory)
 		r.Post("/buy", h.BuyIngredient)
+		r.Post("/checkout", h.Checkout)
+		r.Post("/sell", h.SellBack)
 	})
 }
 
@@ -53,18 +62,29 @@ func (h *Handler) GetCatalog(w http.ResponseWriter, r *http.Request) {
 	}
 	playerID, _ := uuid.Parse(claims.PlayerID)
 
-	// Get player's current money
+	// Get player's current money and the day the prices are for
 	var playerMoney int64
+	var gameDay int
+	var weather, worldType string
 	err = h.db.QueryRow(ctx, `
-		SELECT money FROM game_sessions
+		SELECT money, game_day, COALESCE(weather, 'sunny'), world_type FROM game_sessions
 		WHERE id = $1 AND player_id = $2 AND status = 'active'
-	`, gameID, playerID).Scan(&playerMoney)
+	`, gameID, playerID).Scan(&playerMoney, &gameDay, &weather, &worldType)
 	if err != nil {
 		render.Status(r, http.StatusNotFound)
 		render.JSON(w, r, map[string]string{"error": "Game not found"})
 		return
 	}
 
+	catalog := ingredients.Category(ctx, h.db, worldType)
+	sheet, err := pricing.Load(ctx, h.db, gameID, gameDay, weather, catalog)
+	if err != nil {
+		log.Printf("Error loading market prices: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch catalog"})
+		return
+	}
+
 	// Get all ingredients with owned status
 	rows, err := h.db.Query(ctx, `
 		SELECT
@@ -76,13 +96,16 @@ func (h *Handler) GetCatalog(w http.ResponseWriter, r *http.Request) {
 			COALESCE(p.config->>'type', '') as type,
 			COALESCE(p.config->'tags', '[]'::jsonb) as tags,
 			COALESCE(p.config->>'source', 'system') as source,
-			CASE WHEN pi.id IS NOT NULL THEN true ELSE false END as owned
-		FROM parameters p
+			COALESCE(p.config->>'creator_name', '') as creator_name,
+			CASE WHEN pi.id IS NOT NULL THEN true ELSE false END as owned,
+			COALESCE(pi.quantity, 0) as stock,
+			COALESCE((p.config->>'shelf_life_days')::int, 0) as shelf_life
+		FROM market_ingredients p
 		LEFT JOIN player_ingredients pi
 			ON pi.ingredient_code = p.code
 			AND pi.session_id = $1
 			AND pi.player_id = $2
-		WHERE p.category = 'ingredients_cr' AND p.is_active = true
+		WHERE p.category = $3 AND p.is_active = true
 		ORDER BY
 			CASE p.config->>'tier'
 				WHEN 'basic' THEN 1
@@ -93,7 +116,7 @@ func (h *Handler) GetCatalog(w http.ResponseWriter, r *http.Request) {
 			END,
 			(p.config->>'cost')::int,
 			p.name
-	`, gameID, playerID)
+	`, gameID, playerID, catalog)
 	if err != nil {
 		log.Printf("Error fetching catalog: %v", err)
 		render.Status(r, http.StatusInternalServerError)
@@ -111,7 +134,8 @@ func (h *Handler) GetCatalog(w http.ResponseWriter, r *http.Request) {
 
 		err := rows.Scan(
 			&ing.Code, &ing.Name, &ing.Icon, &ing.Cost,
-			&ing.Tier, &ing.Type, &tagsJSON, &ing.Source, &ing.Owned,
+			&ing.Tier, &ing.Type, &tagsJSON, &ing.Source, &ing.CreatorName, &ing.Owned,
+			&ing.Stock, &ing.ShelfLife,
 		)
 		if err != nil {
 			log.Printf("Error scanning ingredient: %v", err)
@@ -123,6 +147,13 @@ func (h *Handler) GetCatalog(w http.ResponseWriter, r *http.Request) {
 			ing.Tags = []string{}
 		}
 
+		// Today's price
+		ing.BasePrice = ing.Cost
+		if q, ok := sheet[ing.Code]; ok {
+			ing.Cost = q.Price
+			ing.PriceChange = q.Change
+		}
+
 		ingredients = append(ingredients, ing)
 		stats.TotalAvailable++
 
@@ -144,6 +175,7 @@ func (h *Handler) GetCatalog(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, CatalogResponse{
 		Ingredients: ingredients,
 		PlayerMoney: playerMoney,
+		GameDay:     gameDay,
 		Stats:       stats,
 	})
 }
@@ -169,6 +201,13 @@ func (h *Handler) GetInventory(w http.ResponseWriter, r *http.Request) {
 	}
 	playerID, _ := uuid.Parse(claims.PlayerID)
 
+	catalog, err := ingredients.ForGame(ctx, h.db, gameID)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Game not found"})
+		return
+	}
+
 	rows, err := h.db.Query(ctx, `
 		SELECT
 			p.code,
@@ -178,12 +217,15 @@ func (h *Handler) GetInventory(w http.ResponseWriter, r *http.Request) {
 			COALESCE(p.config->>'tier', 'common') as tier,
 			COALESCE(p.config->>'type', '') as type,
 			COALESCE(p.config->'tags', '[]'::jsonb) as tags,
-			COALESCE(p.config->>'source', 'system') as source
+			COALESCE(p.config->>'source', 'system') as source,
+			COALESCE(p.config->>'creator_name', '') as creator_name,
+			pi.quantity,
+			COALESCE((p.config->>'shelf_life_days')::int, 0) as shelf_life
 		FROM player_ingredients pi
-		JOIN parameters p ON p.code = pi.ingredient_code AND p.category = 'ingredients_cr'
+		JOIN market_ingredients p ON p.code = pi.ingredient_code AND p.category = $3
 		WHERE pi.session_id = $1 AND pi.player_id = $2
 		ORDER BY p.name
-	`, gameID, playerID)
+	`, gameID, playerID, catalog)
 	if err != nil {
 		log.Printf("Error fetching inventory: %v", err)
 		render.Status(r, http.StatusInternalServerError)
@@ -199,7 +241,8 @@ func (h *Handler) GetInventory(w http.ResponseWriter, r *http.Request) {
 
 		err := rows.Scan(
 			&ing.Code, &ing.Name, &ing.Icon, &ing.Cost,
-			&ing.Tier, &ing.Type, &tagsJSON, &ing.Source,
+			&ing.Tier, &ing.Type, &tagsJSON, &ing.Source, &ing.CreatorName,
+			&ing.Stock, &ing.ShelfLife,
 		)
 		if err != nil {
 			continue
@@ -212,14 +255,24 @@ func (h *Handler) GetInventory(w http.ResponseWriter, r *http.Request) {
 		ingredients = append(ingredients, ing)
 	}
 
+	lots := []inventory.Lot{}
+	byCode, err := inventory.LoadLots(ctx, h.db, gameID)
+	if err != nil {
+		log.Printf("Error fetching inventory lots: %v", err)
+	}
+	for _, ing := range ingredients {
+		lots = append(lots, byCode[ing.Code]...)
+	}
+
 	render.JSON(w, r, InventoryResponse{
 		Ingredients: ingredients,
+		Lots:        lots,
 		Total:       len(ingredients),
 	})
 }
 
 // POST /api/v1/games/{gameID}/market/buy
-// Purchase an ingredient
+// Purchase units of an ingredient
 func (h *Handler) BuyIngredient(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
@@ -252,27 +305,32 @@ func (h *Handler) BuyIngredient(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if already owned
-	var existingCount int
-	h.db.QueryRow(ctx, `
-		SELECT COUNT(*) FROM player_ingredients
-		WHERE session_id = $1 AND player_id = $2 AND ingredient_code = $3
-	`, gameID, playerID, req.IngredientCode).Scan(&existingCount)
-
-	if existingCount > 0 {
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 || req.Quantity > maxBuyQuantity {
 		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, map[string]string{"error": "Ya tenés este ingrediente"})
+		render.JSON(w, r, map[string]string{
+			"error": fmt.Sprintf("La cantidad tiene que estar entre 1 y %d", maxBuyQuantity),
+		})
 		return
 	}
 
-	// Get ingredient cost
-	var ingredientCost int
+	catalog, err := ingredients.ForGame(ctx, h.db, gameID)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	// Get ingredient
+	var shelfLife int
 	var ingredientName string
 	err = h.db.QueryRow(ctx, `
-		SELECT COALESCE((config->>'cost')::int, 0), name
-		FROM parameters
-		WHERE category = 'ingredients_cr' AND code = $1 AND is_active = true
-	`, req.IngredientCode).Scan(&ingredientCost, &ingredientName)
+		SELECT name, COALESCE((config->>'shelf_life_days')::int, 0)
+		FROM market_ingredients
+		WHERE category = $2 AND code = $1 AND is_active = true
+	`, req.IngredientCode, catalog).Scan(&ingredientName, &shelfLife)
 
 	if err != nil {
 		render.Status(r, http.StatusNotFound)
@@ -280,12 +338,24 @@ func (h *Handler) BuyIngredient(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get player money and check if enough
+	// Start transaction
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error de transacción"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// Get player money and check if enough (locked until commit)
 	var playerMoney int64
-	err = h.db.QueryRow(ctx, `
-		SELECT money FROM game_sessions
+	var gameDay int
+	var weather string
+	err = tx.QueryRow(ctx, `
+		SELECT money, game_day, COALESCE(weather, 'sunny') FROM game_sessions
 		WHERE id = $1 AND player_id = $2 AND status = 'active'
-	`, gameID, playerID).Scan(&playerMoney)
+		FOR UPDATE
+	`, gameID, playerID).Scan(&playerMoney, &gameDay, &weather)
 
 	if err != nil {
 		render.Status(r, http.StatusNotFound)
@@ -293,43 +363,72 @@ func (h *Handler) BuyIngredient(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if playerMoney < int64(ingredientCost) {
+	// Pay today's market price
+	sheet, err := pricing.Load(ctx, tx, gameID, gameDay, weather, catalog)
+	if err != nil {
+		log.Printf("Error loading market prices: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar precios"})
+		return
+	}
+	quote, ok := sheet[req.IngredientCode]
+	if !ok {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Ingrediente no encontrado"})
+		return
+	}
+	ingredientCost := quote.Price
+
+	totalCost := int64(ingredientCost) * int64(req.Quantity)
+	if playerMoney < totalCost {
 		render.Status(r, http.StatusBadRequest)
 		render.JSON(w, r, map[string]string{
-			"error": fmt.Sprintf("No tenés suficiente dinero. Necesitás ₡%d", ingredientCost),
+			"error": fmt.Sprintf("No tenés suficiente dinero. Necesitás ₡%d", totalCost),
 		})
 		return
 	}
 
-	// Start transaction
-	tx, err := h.db.Begin(ctx)
+	// Deduct money
+	newBalance, err := ledger.Post(ctx, tx, gameID, ledger.Entry{
+		GameDay:     gameDay,
+		Amount:      -totalCost,
+		Account:     ledger.AccountInventory,
+		Reason:      ledger.ReasonPurchase,
+		Reference:   req.IngredientCode,
+		Description: fmt.Sprintf("%d × %s", req.Quantity, ingredientName),
+	})
 	if err != nil {
 		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, map[string]string{"error": "Error de transacción"})
+		render.JSON(w, r, map[string]string{"error": "Error al actualizar dinero"})
 		return
 	}
-	defer tx.Rollback(ctx)
 
-	// Deduct money
-	newBalance := playerMoney - int64(ingredientCost)
-	_, err = tx.Exec(ctx, `
-		UPDATE game_sessions SET money = $1, updated_at = NOW()
-		WHERE id = $2 AND player_id = $3
-	`, newBalance, gameID, playerID)
+	// Add the units to the inventory as a new lot
+	stock, err := inventory.Add(ctx, tx, gameID, playerID, inventory.Purchase{
+		Code:          req.IngredientCode,
+		Quantity:      req.Quantity,
+		CostPerUnit:   ingredientCost,
+		Day:           gameDay,
+		ShelfLifeDays: shelfLife,
+	})
 	if err != nil {
+		log.Printf("Error adding %s to inventory: %v", req.IngredientCode, err)
 		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, map[string]string{"error": "Error al actualizar dinero"})
+		render.JSON(w, r, map[string]string{"error": "Error al agregar ingrediente"})
 		return
 	}
 
-	// Add ingredient to inventory
-	_, err = tx.Exec(ctx, `
-		INSERT INTO player_ingredients (session_id, player_id, ingredient_code, source)
-		VALUES ($1, $2, $3, 'purchase')
-	`, gameID, playerID, req.IngredientCode)
-	if err != nil {
+	if err := pricing.RecordPurchase(ctx, tx, gameID, gameDay, req.IngredientCode, req.Quantity); err != nil {
+		log.Printf("Error recording purchase volume: %v", err)
 		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, map[string]string{"error": "Error al agregar ingrediente"})
+		render.JSON(w, r, map[string]string{"error": "Error al completar compra"})
+		return
+	}
+
+	if err := ingredients.RecordUse(ctx, tx, req.IngredientCode); err != nil {
+		log.Printf("Error recording use of %s: %v", req.IngredientCode, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al completar compra"})
 		return
 	}
 
@@ -343,8 +442,13 @@ func (h *Handler) BuyIngredient(w http.ResponseWriter, r *http.Request) {
 	render.Status(r, http.StatusOK)
 	render.JSON(w, r, BuyResponse{
 		Success:        true,
-		Message:        "¡Compraste " + ingredientName + "!",
+		Message:        fmt.Sprintf("¡Compraste %d × %s!", req.Quantity, ingredientName),
 		NewBalance:     newBalance,
 		IngredientCode: req.IngredientCode,
+		Quantity:       req.Quantity,
+		UnitPrice:      ingredientCost,
+		TotalCost:      totalCost,
+		Stock:          stock,
+		ExpiresDay:     inventory.ExpiresDay(gameDay, shelfLife),
 	})
 }
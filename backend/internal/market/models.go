@@ -3,6 +3,7 @@ package market
 import (
 	"time"
 
+	"github.com/alonsoalpizar/calleviva/backend/internal/inventory"
 	"github.com/google/uuid"
 )
 
@@ -11,13 +12,17 @@ type MarketIngredient struct {
 	Code        string   `json:"code"`
 	Name        string   `json:"name"`
 	Icon        string   `json:"icon,omitempty"`
-	Cost        int      `json:"cost"`
-	Tier        string   `json:"tier"` // basic, common, premium, special
+	Cost        int      `json:"cost"`         // today's price
+	BasePrice   int      `json:"base_price"`   // catalog price
+	PriceChange float64  `json:"price_change"` // vs base price, 0.12 = +12%
+	Tier        string   `json:"tier"`         // basic, common, premium, special
 	Type        string   `json:"type,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
 	Owned       bool     `json:"owned"`
-	Source      string   `json:"source,omitempty"`      // system, creator
-	CreatorName string   `json:"creator_name,omitempty"` // if from creator
+	Stock       int      `json:"stock"`                     // units in the inventory
+	ShelfLife   int      `json:"shelf_life_days,omitempty"` // 0 = doesn't spoil
+	Source      string   `json:"source,omitempty"`          // system, creator
+	CreatorName string   `json:"creator_name,omitempty"`    // if from creator
 }
 
 // PlayerIngredient represents an ingredient owned by a player
@@ -35,6 +40,7 @@ type PlayerIngredient struct {
 type CatalogResponse struct {
 	Ingredients []MarketIngredient `json:"ingredients"`
 	PlayerMoney int64              `json:"player_money"`
+	GameDay     int                `json:"game_day"` // day the prices are for
 	Stats       CatalogStats       `json:"stats"`
 }
 
@@ -51,18 +57,89 @@ type CatalogStats struct {
 // InventoryResponse is the response for GET /market/inventory
 type InventoryResponse struct {
 	Ingredients []MarketIngredient `json:"ingredients"`
+	Lots        []inventory.Lot    `json:"lots"` // purchases in stock, with expiry
 	Total       int                `json:"total"`
 }
 
 // BuyRequest is the request body for POST /market/buy
 type BuyRequest struct {
 	IngredientCode string `json:"ingredient_code"`
+	Quantity       int    `json:"quantity"` // defaults to 1
 }
 
 // BuyResponse is the response for POST /market/buy
 type BuyResponse struct {
-	Success       bool   `json:"success"`
-	Message       string `json:"message"`
-	NewBalance    int64  `json:"new_balance"`
+	Success        bool   `json:"success"`
+	Message        string `json:"message"`
+	NewBalance     int64  `json:"new_balance"`
 	IngredientCode string `json:"ingredient_code"`
+	Quantity       int    `json:"quantity"`
+	UnitPrice      int    `json:"unit_price"`
+	TotalCost      int64  `json:"total_cost"`
+	Stock          int    `json:"stock"`
+	ExpiresDay     *int   `json:"expires_day,omitempty"`
+}
+
+// CartItem is one line of a checkout basket
+type CartItem struct {
+	IngredientCode string `json:"ingredient_code"`
+	Quantity       int    `json:"quantity"`
+}
+
+// CheckoutRequest is the request body for POST /market/checkout
+type CheckoutRequest struct {
+	Items []CartItem `json:"items"`
+}
+
+// CheckoutLine is a priced basket line
+type CheckoutLine struct {
+	IngredientCode string `json:"ingredient_code"`
+	Name           string `json:"name"`
+	Quantity       int    `json:"quantity"`
+	UnitPrice      int    `json:"unit_price"`
+	Subtotal       int64  `json:"subtotal"`
+	Discount       int64  `json:"discount"` // bulk discount
+	Total          int64  `json:"total"`
+	Stock          int    `json:"stock"`
+	ExpiresDay     *int   `json:"expires_day,omitempty"`
+}
+
+// CheckoutResponse is the response for POST /market/checkout
+type CheckoutResponse struct {
+	Success    bool           `json:"success"`
+	Message    string         `json:"message"`
+	Lines      []CheckoutLine `json:"lines"`
+	Subtotal   int64          `json:"subtotal"`
+	Discount   int64          `json:"discount"`
+	TotalCost  int64          `json:"total_cost"`
+	NewBalance int64          `json:"new_balance"`
+}
+
+// SellBackRequest is the request body for POST /market/sell
+type SellBackRequest struct {
+	IngredientCode string `json:"ingredient_code"`
+	Quantity       int    `json:"quantity"`         // defaults to 1
+	LotID          string `json:"lot_id,omitempty"` // sell from this lot only
+}
+
+// SoldLot is the part of a lot that was sold back
+type SoldLot struct {
+	LotID         string `json:"lot_id"`
+	Quantity      int    `json:"quantity"`
+	CostPerUnit   int    `json:"cost_per_unit"`
+	RefundPerUnit int    `json:"refund_per_unit"`
+	AgeDays       int    `json:"age_days"`
+}
+
+// SellBackResponse is the response for POST /market/sell
+type SellBackResponse struct {
+	Success        bool      `json:"success"`
+	Message        string    `json:"message"`
+	IngredientCode string    `json:"ingredient_code"`
+	Quantity       int       `json:"quantity"`
+	Refund         int64     `json:"refund"`
+	PaidCost       int64     `json:"paid_cost"` // what the units cost
+	Lots           []SoldLot `json:"lots"`
+	Stock          int       `json:"stock"`
+	NewBalance     int64     `json:"new_balance"`
 }
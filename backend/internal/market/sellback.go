@@ -0,0 +1,203 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ingredients"
+	"github.com/alonsoalpizar/calleviva/backend/internal/inventory"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ledger"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+// Sell-back: the market takes back unused stock for less than it was paid
+const (
+	SellBackRate    = 0.90 // on the day of purchase
+	SellBackDecay   = 0.05 // lost per day by ingredients that don't spoil
+	SellBackMinRate = 0.50 // floor for ingredients that don't spoil
+)
+
+// Depreciation is the share of a lot's cost the market pays back on the
+// given day. Ingredients that don't spoil lose a bit every day; perishables
+// are worth the part of their shelf life they have left.
+func Depreciation(l inventory.Lot, day int) float64 {
+	age := max(0, day-l.AcquiredDay)
+
+	if l.ExpiresDay == nil {
+		return math.Max(SellBackMinRate, SellBackRate-float64(age)*SellBackDecay)
+	}
+
+	shelfLife := *l.ExpiresDay - l.AcquiredDay
+	remaining := *l.ExpiresDay - day
+	if shelfLife <= 0 || remaining <= 0 {
+		return 0
+	}
+	return SellBackRate * float64(remaining) / float64(shelfLife)
+}
+
+// SellBackPrice is what the market pays for one unit of a lot
+func SellBackPrice(l inventory.Lot, day int) int {
+	return int(math.Round(float64(l.CostPerUnit) * Depreciation(l, day)))
+}
+
+// POST /api/v1/games/{gameID}/market/sell
+// Sells unused units back to the market, oldest lots first unless a lot is given
+func (h *Handler) SellBack(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	var req SellBackRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+	if req.IngredientCode == "" {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "ingredient_code is required"})
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "La cantidad tiene que ser mayor a 0"})
+		return
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error de transacción"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var gameDay int
+	var worldType string
+	err = tx.QueryRow(ctx, `
+		SELECT game_day, world_type FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND status = 'active'
+		FOR UPDATE
+	`, gameID, playerID).Scan(&gameDay, &worldType)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	var ingredientName string
+	tx.QueryRow(ctx, `
+		SELECT name FROM market_ingredients WHERE category = $1 AND code = $2
+	`, ingredients.Category(ctx, tx, worldType), req.IngredientCode).Scan(&ingredientName)
+	if ingredientName == "" {
+		ingredientName = req.IngredientCode
+	}
+
+	all, err := inventory.LoadLots(ctx, tx, gameID)
+	if err != nil {
+		log.Printf("Error loading inventory: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar el inventario"})
+		return
+	}
+
+	var lots []inventory.Lot
+	for _, l := range all[req.IngredientCode] {
+		if req.LotID == "" || l.ID == req.LotID {
+			lots = append(lots, l)
+		}
+	}
+	if len(lots) == 0 {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "No tenés ese ingrediente en el inventario"})
+		return
+	}
+
+	byID := make(map[string]inventory.Lot, len(lots))
+	for _, l := range lots {
+		byID[l.ID] = l
+	}
+
+	takes, missing := inventory.Consume(lots, req.Quantity)
+	if missing > 0 {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": fmt.Sprintf("Solo tenés %d unidades de %s", req.Quantity-missing, ingredientName),
+		})
+		return
+	}
+
+	resp := SellBackResponse{Success: true, IngredientCode: req.IngredientCode, Quantity: req.Quantity}
+	for _, t := range takes {
+		l := byID[t.LotID]
+		price := SellBackPrice(l, gameDay)
+		resp.Lots = append(resp.Lots, SoldLot{
+			LotID:         t.LotID,
+			Quantity:      t.Quantity,
+			CostPerUnit:   l.CostPerUnit,
+			RefundPerUnit: price,
+			AgeDays:       max(0, gameDay-l.AcquiredDay),
+		})
+		resp.Refund += int64(price) * int64(t.Quantity)
+		resp.PaidCost += t.Cost
+	}
+
+	if err := inventory.Remove(ctx, tx, gameID, takes); err != nil {
+		log.Printf("Error removing sold stock: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al actualizar el inventario"})
+		return
+	}
+
+	resp.NewBalance, err = ledger.Post(ctx, tx, gameID, ledger.Entry{
+		GameDay:     gameDay,
+		Amount:      resp.Refund,
+		Account:     ledger.AccountInventory,
+		Reason:      ledger.ReasonSellBack,
+		Reference:   req.IngredientCode,
+		Description: fmt.Sprintf("Devolución de %d × %s", req.Quantity, ingredientName),
+	})
+	if err != nil {
+		log.Printf("Error posting sell-back: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al actualizar dinero"})
+		return
+	}
+
+	tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(quantity), 0) FROM inventory WHERE session_id = $1 AND item_type = $2
+	`, gameID, req.IngredientCode).Scan(&resp.Stock)
+
+	if err := tx.Commit(ctx); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al completar la venta"})
+		return
+	}
+
+	resp.Message = fmt.Sprintf("Vendiste %d × %s por ₡%d", req.Quantity, ingredientName, resp.Refund)
+	render.JSON(w, r, resp)
+}
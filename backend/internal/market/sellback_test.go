@@ -0,0 +1,35 @@
+package market
+
+import (
+	"testing"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/inventory"
+)
+
+func expires(d int) *int { return &d }
+
+func TestDepreciationDryGoods(t *testing.T) {
+	rice := inventory.Lot{CostPerUnit: 1000, AcquiredDay: 2}
+	if got := SellBackPrice(rice, 2); got != 900 {
+		t.Errorf("expected 900 on the day of purchase, got %d", got)
+	}
+	if got := SellBackPrice(rice, 4); got != 800 {
+		t.Errorf("expected 800 after two days, got %d", got)
+	}
+	if got := SellBackPrice(rice, 30); got != 500 {
+		t.Errorf("expected the floor of 500, got %d", got)
+	}
+}
+
+func TestDepreciationPerishables(t *testing.T) {
+	fish := inventory.Lot{CostPerUnit: 1000, AcquiredDay: 2, ExpiresDay: expires(4)}
+	if got := SellBackPrice(fish, 2); got != 900 {
+		t.Errorf("expected 900 on the day of purchase, got %d", got)
+	}
+	if got := SellBackPrice(fish, 3); got != 450 {
+		t.Errorf("expected half the shelf life left to pay 450, got %d", got)
+	}
+	if got := SellBackPrice(fish, 4); got != 0 {
+		t.Errorf("expected spoiled stock to be worth nothing, got %d", got)
+	}
+}
@@ -0,0 +1,446 @@
+package menu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ingredients"
+	"github.com/alonsoalpizar/calleviva/backend/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pgLockNotAvailable is returned by FOR UPDATE NOWAIT while the day is running
+const pgLockNotAvailable = "55P03"
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type Handler struct {
+	db *pgxpool.Pool
+}
+
+func NewHandler(db *pgxpool.Pool) *Handler {
+	return &Handler{db: db}
+}
+
+// SetupRoutes mounts menu routes (requires auth)
+func (h *Handler) SetupRoutes(r chi.Router) {
+	r.Get("/menu", h.GetMenu)
+	r.Post("/menu/configure", h.Configure)
+}
+
+// GET /api/v1/games/{gameID}/menu
+func (h *Handler) GetMenu(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	var worldType string
+	err = h.db.QueryRow(ctx, `
+		SELECT world_type FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+	`, gameID, playerID).Scan(&worldType)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	resp := MenuResponse{Success: true, Capacity: 20, Menu: []MenuEntry{}}
+	err = h.db.QueryRow(ctx, `
+		SELECT id, COALESCE(capacity, 20) FROM trucks
+		WHERE session_id = $1 ORDER BY created_at LIMIT 1
+	`, gameID).Scan(&resp.TruckID, &resp.Capacity)
+	if err != nil && err != pgx.ErrNoRows {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar el truck"})
+		return
+	}
+	resp.MaxItems = MaxItems(resp.Capacity)
+
+	resp.Menu, err = loadMenu(ctx, h.db, gameID, playerID, resp.TruckID, models.WorldSuffix(worldType))
+	if err != nil {
+		log.Printf("Error loading menu: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar el menú"})
+		return
+	}
+
+	render.JSON(w, r, resp)
+}
+
+// POST /api/v1/games/{gameID}/menu/configure
+// Replaces the truck's menu with the given products and lab dishes
+func (h *Handler) Configure(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	var req ConfigureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error de transacción"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var worldType string
+	err = tx.QueryRow(ctx, `
+		SELECT world_type FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+		FOR UPDATE NOWAIT
+	`, gameID, playerID).Scan(&worldType)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgLockNotAvailable {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, map[string]string{"error": "El día está en curso, no podés cambiar el menú"})
+			return
+		}
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+	suffix := models.WorldSuffix(worldType)
+
+	truckID, capacity, err := loadOrCreateTruck(ctx, tx, gameID)
+	if err != nil {
+		log.Printf("Error loading truck: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar el truck"})
+		return
+	}
+
+	active, err := validate(ctx, tx, gameID, playerID, suffix, req)
+	var loadErr loadError
+	if errors.As(err, &loadErr) {
+		log.Printf("Error validating menu for %s: %v", gameID, loadErr.err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if maxItems := MaxItems(capacity); active > maxItems {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": fmt.Sprintf("Tu truck solo puede ofrecer %d productos (capacidad %d)", maxItems, capacity),
+		})
+		return
+	}
+
+	if err := saveMenu(ctx, tx, gameID, playerID, truckID, req); err != nil {
+		log.Printf("Error saving menu: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al guardar el menú"})
+		return
+	}
+
+	menu, err := loadMenu(ctx, tx, gameID, playerID, truckID, suffix)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar el menú"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al confirmar"})
+		return
+	}
+
+	render.JSON(w, r, MenuResponse{
+		Success:  true,
+		TruckID:  truckID,
+		Capacity: capacity,
+		MaxItems: MaxItems(capacity),
+		Menu:     menu,
+	})
+}
+
+// loadOrCreateTruck returns the session's truck, creating the starter truck
+// for games created before trucks existed
+func loadOrCreateTruck(ctx context.Context, tx pgx.Tx, gameID uuid.UUID) (uuid.UUID, int, error) {
+	var id uuid.UUID
+	var capacity int
+	query := `
+		SELECT id, COALESCE(capacity, 20) FROM trucks
+		WHERE session_id = $1 ORDER BY created_at LIMIT 1
+	`
+	err := tx.QueryRow(ctx, query, gameID).Scan(&id, &capacity)
+	if err == pgx.ErrNoRows {
+		if err := CreateStarterTruck(ctx, tx, gameID.String()); err != nil {
+			return id, 0, err
+		}
+		err = tx.QueryRow(ctx, query, gameID).Scan(&id, &capacity)
+	}
+	return id, capacity, err
+}
+
+// loadError is a database failure during validate: the message is
+// user-facing, err is what gets logged
+type loadError struct {
+	msg string
+	err error
+}
+
+func (e loadError) Error() string { return e.msg }
+
+// validate checks products, prices and dish ingredients, and returns how many
+// items would be active. Errors are user-facing; loadError means the
+// database failed rather than the request.
+func validate(ctx context.Context, tx pgx.Tx, gameID, playerID uuid.UUID, suffix string, req ConfigureRequest) (int, error) {
+	active := 0
+
+	seen := map[string]bool{}
+	for _, item := range req.Items {
+		if seen[item.ProductType] {
+			return 0, fmt.Errorf("Producto repetido: %s", item.ProductType)
+		}
+		seen[item.ProductType] = true
+
+		var exists bool
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM parameters WHERE category = $1 AND code = $2 AND is_active = true)
+		`, "products_"+suffix, item.ProductType).Scan(&exists)
+		if err != nil {
+			return 0, loadError{"Error al cargar los productos", err}
+		}
+		if !exists {
+			return 0, fmt.Errorf("Producto no válido: %s", item.ProductType)
+		}
+		if item.Price <= 0 {
+			return 0, fmt.Errorf("Precio inválido para %s", item.ProductType)
+		}
+		if item.Active {
+			active++
+		}
+	}
+
+	owned, err := ownedIngredients(ctx, tx, gameID, playerID)
+	if err != nil {
+		return 0, loadError{"Error al cargar el inventario", err}
+	}
+
+	seenDishes := map[uuid.UUID]bool{}
+	for _, d := range req.Dishes {
+		if seenDishes[d.DishID] {
+			return 0, errors.New("Platillo repetido")
+		}
+		seenDishes[d.DishID] = true
+
+		var name string
+		var ingredientsJSON []byte
+		err := tx.QueryRow(ctx, `
+			SELECT name, ingredients FROM player_dishes
+			WHERE id = $1 AND session_id = $2 AND player_id = $3
+		`, d.DishID, gameID, playerID).Scan(&name, &ingredientsJSON)
+		if err == pgx.ErrNoRows {
+			return 0, errors.New("Platillo no encontrado")
+		}
+		if err != nil {
+			return 0, loadError{"Error al cargar el platillo", err}
+		}
+		if d.Price != nil && *d.Price <= 0 {
+			return 0, fmt.Errorf("Precio inválido para %s", name)
+		}
+		if !d.Active {
+			continue
+		}
+		if missing := missingIngredients(ingredientsJSON, owned); len(missing) > 0 {
+			return 0, fmt.Errorf("No tenés en inventario los ingredientes de %s: %v", name, missing)
+		}
+		active++
+	}
+
+	return active, nil
+}
+
+// ownedIngredients returns the ingredient codes the player has in stock in
+// this game. Sold-out ingredients keep their row with quantity 0.
+func ownedIngredients(ctx context.Context, tx pgx.Tx, gameID, playerID uuid.UUID) (map[string]bool, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT ingredient_code FROM player_ingredients
+		WHERE session_id = $1 AND player_id = $2 AND quantity > 0
+	`, gameID, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	owned := map[string]bool{}
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		owned[code] = true
+	}
+	return owned, rows.Err()
+}
+
+// missingIngredients lists the ingredients of a dish the player doesn't own.
+// Same rule as the day simulation: creator ingredients sold in the market
+// (creator_<id>) have to be bought; the ones picked before that stay free.
+func missingIngredients(ingredientsJSON []byte, owned map[string]bool) []string {
+	var list []struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	}
+	json.Unmarshal(ingredientsJSON, &list)
+
+	var missing []string
+	for _, ing := range list {
+		if _, sold := ingredients.CreationID(ing.ID); ing.Type == "creator" && !sold {
+			continue
+		}
+		if !owned[ing.ID] {
+			missing = append(missing, ing.ID)
+		}
+	}
+	return missing
+}
+
+// saveMenu replaces the truck's menu_items and the session's in-menu dishes
+func saveMenu(ctx context.Context, tx pgx.Tx, gameID, playerID, truckID uuid.UUID, req ConfigureRequest) error {
+	if _, err := tx.Exec(ctx, `
+		UPDATE menu_items SET is_active = false, updated_at = NOW() WHERE truck_id = $1
+	`, truckID); err != nil {
+		return err
+	}
+
+	for _, item := range req.Items {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO menu_items (truck_id, product_type, price, is_active)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (truck_id, product_type)
+			DO UPDATE SET price = EXCLUDED.price, is_active = EXCLUDED.is_active, updated_at = NOW()
+		`, truckID, item.ProductType, item.Price, item.Active)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE player_dishes SET is_in_menu = false, updated_at = NOW()
+		WHERE session_id = $1 AND player_id = $2 AND is_in_menu = true
+	`, gameID, playerID); err != nil {
+		return err
+	}
+
+	for _, d := range req.Dishes {
+		_, err := tx.Exec(ctx, `
+			UPDATE player_dishes
+			SET player_price = COALESCE($2, player_price), is_in_menu = $3, updated_at = NOW()
+			WHERE id = $1
+		`, d.DishID, d.Price, d.Active)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadMenu lists the truck's configured products followed by in-menu lab dishes
+func loadMenu(ctx context.Context, db querier, gameID, playerID, truckID uuid.UUID, suffix string) ([]MenuEntry, error) {
+	menu := []MenuEntry{}
+
+	rows, err := db.Query(ctx, `
+		SELECT mi.product_type, COALESCE(p.name, mi.product_type), mi.price, mi.is_active
+		FROM menu_items mi
+		LEFT JOIN parameters p ON p.category = $2 AND p.code = mi.product_type
+		WHERE mi.truck_id = $1
+		ORDER BY mi.is_active DESC, mi.product_type
+	`, truckID, "products_"+suffix)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		e := MenuEntry{Kind: KindProduct}
+		if err := rows.Scan(&e.ProductType, &e.Name, &e.Price, &e.Active); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		menu = append(menu, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dishRows, err := db.Query(ctx, `
+		SELECT id, name, COALESCE(player_price, suggested_price)
+		FROM player_dishes
+		WHERE session_id = $1 AND player_id = $2 AND is_in_menu = true
+		ORDER BY created_at
+	`, gameID, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer dishRows.Close()
+
+	for dishRows.Next() {
+		var id uuid.UUID
+		e := MenuEntry{Kind: KindDish, Active: true}
+		if err := dishRows.Scan(&id, &e.Name, &e.Price); err != nil {
+			return nil, err
+		}
+		e.ProductType = "dish:" + id.String()
+		menu = append(menu, e)
+	}
+	return menu, dishRows.Err()
+}
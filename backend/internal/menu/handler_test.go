@@ -0,0 +1,24 @@
+package menu
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMissingIngredientsRequiresSoldCreatorIngredients(t *testing.T) {
+	const sold = "creator_3f1c2b9e-8a47-4d6b-9c1e-2a5f7d8e9b10"
+	dish := []byte(`[
+		{"id": "arroz", "type": "default"},
+		{"id": "` + sold + `", "type": "creator"},
+		{"id": "7b2e4c1a-0d3f-4e5a-8b6c-9d0e1f2a3b4c", "type": "creator"}
+	]`)
+
+	missing := missingIngredients(dish, map[string]bool{"arroz": true})
+	if want := []string{sold}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing = %v, want %v", missing, want)
+	}
+
+	if missing := missingIngredients(dish, map[string]bool{"arroz": true, sold: true}); len(missing) != 0 {
+		t.Errorf("missing = %v, want none", missing)
+	}
+}
@@ -0,0 +1,48 @@
+package menu
+
+import "github.com/google/uuid"
+
+// Item kinds on the menu
+const (
+	KindProduct = "product"
+	KindDish    = "dish"
+)
+
+// MenuItemInput configures a base product from products_*
+type MenuItemInput struct {
+	ProductType string `json:"product_type"`
+	Price       int    `json:"price"`
+	Active      bool   `json:"active"`
+}
+
+// MenuDishInput configures a dish from the Laboratorio
+type MenuDishInput struct {
+	DishID uuid.UUID `json:"dish_id"`
+	Price  *int      `json:"price,omitempty"` // nil keeps the suggested price
+	Active bool      `json:"active"`
+}
+
+// ConfigureRequest is the request body for POST /menu/configure.
+// It replaces the whole menu: anything not listed is taken off.
+type ConfigureRequest struct {
+	Items  []MenuItemInput `json:"items"`
+	Dishes []MenuDishInput `json:"dishes"`
+}
+
+// MenuEntry is an item currently on the menu
+type MenuEntry struct {
+	ProductType string `json:"product_type"` // product code, or "dish:<uuid>" for lab dishes
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Price       int    `json:"price"`
+	Active      bool   `json:"active"`
+}
+
+// MenuResponse is the response for GET /menu and POST /menu/configure
+type MenuResponse struct {
+	Success  bool        `json:"success"`
+	TruckID  uuid.UUID   `json:"truck_id"`
+	Capacity int         `json:"capacity"`
+	MaxItems int         `json:"max_items"`
+	Menu     []MenuEntry `json:"menu"`
+}
@@ -0,0 +1,39 @@
+package menu
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// StarterTruckType is the truck every new game starts with
+const StarterTruckType = "cart"
+
+// minServingsPerItem is how much of the truck's capacity each active
+// menu item needs, so a 20-serving cart can offer at most 4 items
+const minServingsPerItem = 5
+
+// MaxItems returns how many active items a truck of the given capacity can offer
+func MaxItems(capacity int) int {
+	if n := capacity / minServingsPerItem; n > 1 {
+		return n
+	}
+	return 1
+}
+
+// CreateStarterTruck inserts the session's first truck using the truck_types config
+func CreateStarterTruck(ctx context.Context, tx pgx.Tx, sessionID string) error {
+	capacity, speed := 20, 1.0
+	tx.QueryRow(ctx, `
+		SELECT COALESCE((config->>'capacity')::int, 20), COALESCE((config->>'speed')::float8, 1.0)
+		FROM parameters
+		WHERE category = 'truck_types' AND code = $1 AND is_active = true
+	`, StarterTruckType).Scan(&capacity, &speed)
+	// Ignore error, use defaults
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO trucks (session_id, truck_type, capacity, speed_multiplier)
+		VALUES ($1, $2, $3, $4)
+	`, sessionID, StarterTruckType, capacity, speed)
+	return err
+}
@@ -26,18 +26,27 @@ type CreateGameRequest struct {
 	Name      string `json:"name,omitempty"`
 }
 
+// UpdateGameRequest holds the cosmetic fields a player can change. Money,
+// day, reputation and the rest only change through server actions.
 type UpdateGameRequest struct {
-	Name            *string         `json:"name,omitempty"`
-	GameDay         *int            `json:"game_day,omitempty"`
-	Money           *int64          `json:"money,omitempty"`
-	Reputation      *int            `json:"reputation,omitempty"`
-	CurrentLocation *string         `json:"current_location,omitempty"`
-	Weather         *string         `json:"weather,omitempty"`
-	Status          *string         `json:"status,omitempty"`
-	Stats           json.RawMessage `json:"stats,omitempty"`
+	Name  *string                    `json:"name,omitempty"`
+	Stats map[string]json.RawMessage `json:"stats,omitempty"` // cosmetic keys only (truck look)
 }
 
 type GameListResponse struct {
 	Games []GameSession `json:"games"`
 	Total int           `json:"total"`
 }
+
+// WorldSuffix returns the suffix used by per-world parameter categories
+// (products_cr, locations_mx, ...) for a game's world_type
+func WorldSuffix(worldType string) string {
+	switch worldType {
+	case "mexico":
+		return "mx"
+	case "usa":
+		return "us"
+	default:
+		return "cr"
+	}
+}
@@ -0,0 +1,134 @@
+package pricing
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Ingredient tiers
+const (
+	TierBasic   = "basic"
+	TierCommon  = "common"
+	TierPremium = "premium"
+	TierSpecial = "special"
+)
+
+// Band is how far an ingredient's price can drift from its base cost in a
+// normal day: cheap staples barely move, specialty items swing the most
+func Band(tier string) float64 {
+	switch tier {
+	case TierBasic:
+		return 0.10
+	case TierPremium:
+		return 0.25
+	case TierSpecial:
+		return 0.35
+	default:
+		return 0.15
+	}
+}
+
+// Purchase volume: buying a lot of an ingredient makes it scarce for a while
+const (
+	VolumeWindow   = 3    // days of purchases that count
+	VolumeStep     = 20   // units per price step
+	VolumeIncrease = 0.05 // +5% per step
+	VolumeMax      = 0.25 // at most +25%
+)
+
+// Ingredient is what the engine needs to price an ingredient
+type Ingredient struct {
+	Code     string
+	Type     string
+	Tags     []string
+	Tier     string
+	BaseCost int
+}
+
+// Modifiers are multipliers keyed by ingredient type or tag ("seafood",
+// "fruit"); "*" applies to every ingredient
+type Modifiers map[string]float64
+
+// For returns the modifier that applies to an ingredient: its type first,
+// then its tags in order, then "*"
+func (m Modifiers) For(ing Ingredient) float64 {
+	if len(m) == 0 {
+		return 1.0
+	}
+	if v, ok := m[ing.Type]; ok && v > 0 {
+		return v
+	}
+	for _, tag := range ing.Tags {
+		if v, ok := m[tag]; ok && v > 0 {
+			return v
+		}
+	}
+	if v, ok := m["*"]; ok && v > 0 {
+		return v
+	}
+	return 1.0
+}
+
+// Conditions are today's market conditions for a game
+type Conditions struct {
+	Weather Modifiers      // today's weather (storms raise fish prices)
+	Events  []Modifiers    // active events
+	Volume  map[string]int // units bought per ingredient in the last VolumeWindow days
+}
+
+// Breakdown explains a price
+type Breakdown struct {
+	Fluctuation float64 `json:"fluctuation"` // daily drift within the tier band
+	Weather     float64 `json:"weather"`
+	Event       float64 `json:"event"`
+	Volume      float64 `json:"volume"`
+}
+
+// Quote is an ingredient's price for one day
+type Quote struct {
+	Code      string    `json:"code"`
+	Price     int       `json:"price"`
+	BasePrice int       `json:"base_price"`
+	Change    float64   `json:"change"` // vs base price, 0.12 = +12%
+	Modifiers Breakdown `json:"modifiers"`
+}
+
+// VolumeModifier is the price increase from the game's own recent purchases
+func VolumeModifier(units int) float64 {
+	steps := float64(units / VolumeStep)
+	return 1 + math.Min(steps*VolumeIncrease, VolumeMax)
+}
+
+// Price quotes an ingredient for the day. The draw comes from rng so a
+// seeded generator gives the same sheet every time.
+func Price(rng *rand.Rand, ing Ingredient, cond Conditions) Quote {
+	band := Band(ing.Tier)
+	b := Breakdown{
+		Fluctuation: round3(1 + (rng.Float64()*2-1)*band),
+		Weather:     cond.Weather.For(ing),
+		Event:       1.0,
+		Volume:      VolumeModifier(cond.Volume[ing.Code]),
+	}
+	for _, ev := range cond.Events {
+		b.Event *= ev.For(ing)
+	}
+	b.Event = round3(b.Event)
+
+	base := float64(ing.BaseCost)
+	price := base * b.Fluctuation * b.Weather * b.Event * b.Volume
+	// Keep the market sane whatever stacks up
+	price = math.Max(base*0.5, math.Min(price, base*2))
+
+	q := Quote{
+		Code:      ing.Code,
+		Price:     max(1, int(math.Round(price))),
+		BasePrice: ing.BaseCost,
+		Modifiers: b,
+	}
+	if ing.BaseCost > 0 {
+		q.Change = round3(float64(q.Price)/base - 1)
+	}
+	return q
+}
+
+func round3(v float64) float64 { return math.Round(v*1000) / 1000 }
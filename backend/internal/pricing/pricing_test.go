@@ -0,0 +1,93 @@
+package pricing
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var tomato = Ingredient{Code: "tomate", Type: "vegetable", Tier: TierBasic, BaseCost: 1000}
+var tuna = Ingredient{Code: "atun", Type: "protein", Tags: []string{"seafood"}, Tier: TierPremium, BaseCost: 1000}
+
+func TestPriceStaysInBand(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		q := Price(rng, tomato, Conditions{})
+		if q.Price < 900 || q.Price > 1100 {
+			t.Fatalf("basic ingredient out of its ±10%% band: %d", q.Price)
+		}
+		q = Price(rng, tuna, Conditions{})
+		if q.Price < 750 || q.Price > 1250 {
+			t.Fatalf("premium ingredient out of its ±25%% band: %d", q.Price)
+		}
+	}
+}
+
+func TestPriceIsDeterministic(t *testing.T) {
+	a := Price(rand.New(rand.NewSource(42)), tuna, Conditions{})
+	b := Price(rand.New(rand.NewSource(42)), tuna, Conditions{})
+	if a != b {
+		t.Errorf("same seed should give the same quote: %+v vs %+v", a, b)
+	}
+}
+
+func TestModifiersMatchTypeThenTags(t *testing.T) {
+	m := Modifiers{"seafood": 1.4, "*": 1.05}
+	if got := m.For(tuna); got != 1.4 {
+		t.Errorf("expected the seafood tag to apply, got %v", got)
+	}
+	if got := m.For(tomato); got != 1.05 {
+		t.Errorf("expected the wildcard to apply, got %v", got)
+	}
+	if got := (Modifiers{}).For(tomato); got != 1.0 {
+		t.Errorf("expected no modifier, got %v", got)
+	}
+}
+
+func TestStormRaisesFish(t *testing.T) {
+	cond := Conditions{
+		Weather: Modifiers{"seafood": 1.4},
+		Events:  []Modifiers{{"*": 1.1}},
+	}
+	calm := Price(rand.New(rand.NewSource(7)), tuna, Conditions{})
+	storm := Price(rand.New(rand.NewSource(7)), tuna, cond)
+	if storm.Price <= calm.Price {
+		t.Errorf("expected the storm to raise fish: calm %d, storm %d", calm.Price, storm.Price)
+	}
+	if storm.Modifiers.Weather != 1.4 || storm.Modifiers.Event != 1.1 {
+		t.Errorf("unexpected breakdown %+v", storm.Modifiers)
+	}
+}
+
+func TestVolumeModifier(t *testing.T) {
+	cases := map[int]float64{0: 1.0, 19: 1.0, 20: 1.05, 45: 1.10, 500: 1.25}
+	for units, want := range cases {
+		if got := VolumeModifier(units); got < want-1e-9 || got > want+1e-9 {
+			t.Errorf("VolumeModifier(%d) = %v, want %v", units, got, want)
+		}
+	}
+}
+
+func TestPriceIsClamped(t *testing.T) {
+	cond := Conditions{
+		Weather: Modifiers{"*": 3},
+		Volume:  map[string]int{"tomate": 1000},
+	}
+	if q := Price(rand.New(rand.NewSource(1)), tomato, cond); q.Price != 2000 {
+		t.Errorf("expected the price capped at twice the base, got %d", q.Price)
+	}
+	cond = Conditions{Weather: Modifiers{"*": 0.1}}
+	if q := Price(rand.New(rand.NewSource(1)), tomato, cond); q.Price != 500 {
+		t.Errorf("expected the price floored at half the base, got %d", q.Price)
+	}
+}
+
+func TestUnpricedQuotesOnlyNewIngredients(t *testing.T) {
+	sheet := Sheet{"tomate": {Code: "tomate", Price: 1000}}
+	missing := unpriced([]Ingredient{tomato, tuna}, sheet)
+	if len(missing) != 1 || missing[0].Code != "atun" {
+		t.Errorf("expected only atun to need a quote, got %+v", missing)
+	}
+	if missing := unpriced([]Ingredient{tomato}, sheet); len(missing) != 0 {
+		t.Errorf("expected a complete sheet, got %+v", missing)
+	}
+}
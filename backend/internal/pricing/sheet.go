@@ -0,0 +1,211 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"sort"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/customers"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DB is satisfied by both *pgxpool.Pool and pgx.Tx
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Sheet is a game's price sheet for one day, by ingredient code
+type Sheet map[string]Quote
+
+// Load returns the day's price sheet, quoting and storing the ingredients
+// that aren't on it yet (all of them the first time it's asked for, then
+// the ones added during the day). Stored quotes don't change, so the
+// catalog, the purchases and the day simulation all see the same prices.
+func Load(ctx context.Context, db DB, gameID uuid.UUID, day int, weather, category string) (Sheet, error) {
+	sheet, err := stored(ctx, db, gameID, day)
+	if err != nil {
+		return nil, err
+	}
+
+	ingredients, err := loadIngredients(ctx, db, category)
+	if err != nil {
+		return nil, err
+	}
+	missing := unpriced(ingredients, sheet)
+	if len(missing) == 0 {
+		return sheet, nil
+	}
+	cond, err := loadConditions(ctx, db, gameID, day, weather)
+	if err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(customers.Seed(gameID.String()+":market", day)))
+	batch := &pgx.Batch{}
+	for _, ing := range missing {
+		q := Price(rng, ing, cond)
+		mods, _ := json.Marshal(q.Modifiers)
+		batch.Queue(`
+			INSERT INTO market_prices (session_id, game_day, ingredient_code, price, base_price, modifiers)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (session_id, game_day, ingredient_code) DO NOTHING
+		`, gameID, day, q.Code, q.Price, q.BasePrice, mods)
+	}
+	if batch.Len() > 0 {
+		if err := db.SendBatch(ctx, batch).Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Re-read: a concurrent request may have stored the quotes first
+	return stored(ctx, db, gameID, day)
+}
+
+// unpriced returns the ingredients that have no quote on the sheet yet
+func unpriced(ingredients []Ingredient, sheet Sheet) []Ingredient {
+	var missing []Ingredient
+	for _, ing := range ingredients {
+		if _, ok := sheet[ing.Code]; !ok {
+			missing = append(missing, ing)
+		}
+	}
+	return missing
+}
+
+// RecordPurchase adds bought units to the day's sheet; they push the price
+// up in the following days
+func RecordPurchase(ctx context.Context, db DB, gameID uuid.UUID, day int, code string, quantity int) error {
+	_, err := db.Exec(ctx, `
+		UPDATE market_prices SET purchased = purchased + $4
+		WHERE session_id = $1 AND game_day = $2 AND ingredient_code = $3
+	`, gameID, day, code, quantity)
+	return err
+}
+
+// stored reads an already generated sheet
+func stored(ctx context.Context, db DB, gameID uuid.UUID, day int) (Sheet, error) {
+	rows, err := db.Query(ctx, `
+		SELECT ingredient_code, price, base_price, COALESCE(modifiers, '{}'::jsonb)
+		FROM market_prices
+		WHERE session_id = $1 AND game_day = $2
+	`, gameID, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sheet := Sheet{}
+	for rows.Next() {
+		var q Quote
+		var mods []byte
+		if err := rows.Scan(&q.Code, &q.Price, &q.BasePrice, &mods); err != nil {
+			return nil, err
+		}
+		json.Unmarshal(mods, &q.Modifiers)
+		if q.BasePrice > 0 {
+			q.Change = round3(float64(q.Price)/float64(q.BasePrice) - 1)
+		}
+		sheet[q.Code] = q
+	}
+	return sheet, rows.Err()
+}
+
+// loadIngredients reads the priced ingredients, sorted by code so the
+// random draws always happen in the same order
+func loadIngredients(ctx context.Context, db DB, category string) ([]Ingredient, error) {
+	rows, err := db.Query(ctx, `
+		SELECT code,
+		       COALESCE(config->>'type', ''),
+		       COALESCE(config->'tags', '[]'::jsonb),
+		       COALESCE(config->>'tier', 'common'),
+		       COALESCE((config->>'cost')::int, 0)
+		FROM market_ingredients
+		WHERE category = $1 AND is_active = true
+	`, category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ingredients []Ingredient
+	for rows.Next() {
+		var ing Ingredient
+		var tags []byte
+		if err := rows.Scan(&ing.Code, &ing.Type, &tags, &ing.Tier, &ing.BaseCost); err != nil {
+			return nil, err
+		}
+		json.Unmarshal(tags, &ing.Tags)
+		ingredients = append(ingredients, ing)
+	}
+	sort.Slice(ingredients, func(i, j int) bool { return ingredients[i].Code < ingredients[j].Code })
+	return ingredients, rows.Err()
+}
+
+// loadConditions reads today's weather and events effects on the market and
+// the game's recent purchases
+func loadConditions(ctx context.Context, db DB, gameID uuid.UUID, day int, weather string) (Conditions, error) {
+	cond := Conditions{Volume: map[string]int{}}
+
+	var raw []byte
+	db.QueryRow(ctx, `
+		SELECT config->'ingredient_modifiers' FROM parameters
+		WHERE category = 'weather' AND code = $1 AND is_active = true
+	`, weather).Scan(&raw)
+	if len(raw) > 0 {
+		json.Unmarshal(raw, &cond.Weather)
+	}
+
+	// Events logged for the game that are still running today
+	rows, err := db.Query(ctx, `
+		SELECT p.config->'market_modifiers'
+		FROM events_log e
+		JOIN parameters p ON p.category = 'events' AND p.code = e.event_type AND p.is_active = true
+		WHERE e.session_id = $1
+		  AND e.game_day <= $2
+		  AND e.game_day + COALESCE((p.config->>'duration_days')::int, 1) > $2
+		  AND p.config ? 'market_modifiers'
+	`, gameID, day)
+	if err != nil {
+		return cond, err
+	}
+	for rows.Next() {
+		var mods Modifiers
+		if err := rows.Scan(&raw); err != nil {
+			rows.Close()
+			return cond, err
+		}
+		if json.Unmarshal(raw, &mods) == nil {
+			cond.Events = append(cond.Events, mods)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return cond, err
+	}
+
+	rows, err = db.Query(ctx, `
+		SELECT ingredient_code, SUM(purchased)::int
+		FROM market_prices
+		WHERE session_id = $1 AND game_day >= $2 AND game_day < $3
+		GROUP BY ingredient_code
+	`, gameID, day-VolumeWindow, day)
+	if err != nil {
+		return cond, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var code string
+		var units int
+		if err := rows.Scan(&code, &units); err != nil {
+			return cond, err
+		}
+		cond.Volume[code] = units
+	}
+	return cond, rows.Err()
+}
@@ -0,0 +1,88 @@
+package reputation
+
+import "math"
+
+// Reputation points per customer and streak bonus (GDD 4.5)
+const (
+	PointsSatisfied = 0.5
+	PointsVeryHappy = 1.0
+	PointsLost      = -0.3
+	PointsComplaint = -0.8
+
+	StreakDays  = 5   // consecutive positive days for the bonus
+	StreakBonus = 2.0 // points added every StreakDays positive days
+
+	Min = 0
+	Max = 100
+)
+
+// Tally counts the customers of a day by how they affect reputation
+type Tally struct {
+	Satisfied  int `json:"satisfied"`
+	VeryHappy  int `json:"very_happy"`
+	Lost       int `json:"lost"`
+	Complaints int `json:"complaints"`
+}
+
+// Points is the raw reputation change of a day, before the streak bonus
+func (t Tally) Points() float64 {
+	return float64(t.Satisfied)*PointsSatisfied +
+		float64(t.VeryHappy)*PointsVeryHappy +
+		float64(t.Lost)*PointsLost +
+		float64(t.Complaints)*PointsComplaint
+}
+
+// Result is the outcome of applying a day to a reputation
+type Result struct {
+	Points      float64 `json:"points"`       // raw points from customers
+	StreakBonus float64 `json:"streak_bonus"` // bonus earned today
+	Streak      int     `json:"streak"`       // consecutive positive days including today
+	Earned      int     `json:"earned"`       // change before clamping
+	Change      int     `json:"change"`       // applied change after clamping
+	Reputation  int     `json:"reputation"`   // new reputation
+	Tier        Tier    `json:"tier"`
+}
+
+// Apply adds a day's tally to the current reputation. streak is the number
+// of consecutive positive days before today.
+func Apply(current int, t Tally, streak int) Result {
+	res := Result{Points: t.Points()}
+
+	if math.Round(res.Points) > 0 {
+		res.Streak = streak + 1
+		if res.Streak%StreakDays == 0 {
+			res.StreakBonus = StreakBonus
+		}
+	}
+
+	res.Earned = int(math.Round(res.Points + res.StreakBonus))
+	res.Reputation = Clamp(current + res.Earned)
+	res.Change = res.Reputation - current
+	res.Tier = TierFor(res.Reputation)
+	return res
+}
+
+// StreakFrom counts consecutive positive days from a list of daily
+// reputation earned (before clamping, so a player at 100 keeps the streak)
+// ordered from most recent to oldest
+func StreakFrom(changes []int) int {
+	n := 0
+	for _, c := range changes {
+		if c <= 0 {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// Clamp keeps a reputation within 0-100
+func Clamp(rep int) int {
+	if rep < Min {
+		return Min
+	}
+	if rep > Max {
+		return Max
+	}
+	return rep
+}
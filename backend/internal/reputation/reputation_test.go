@@ -0,0 +1,82 @@
+package reputation
+
+import "testing"
+
+func TestTallyPoints(t *testing.T) {
+	tally := Tally{Satisfied: 10, VeryHappy: 4, Lost: 5, Complaints: 2}
+
+	// 10×0.5 + 4×1.0 - 5×0.3 - 2×0.8 = 5.9
+	if got := tally.Points(); got < 5.899 || got > 5.901 {
+		t.Errorf("expected 5.9 points, got %v", got)
+	}
+}
+
+func TestApplyStreakBonus(t *testing.T) {
+	good := Tally{Satisfied: 4} // +2
+
+	res := Apply(30, good, 3)
+	if res.Streak != 4 || res.StreakBonus != 0 || res.Change != 2 {
+		t.Errorf("4th positive day should not get a bonus: %+v", res)
+	}
+
+	res = Apply(30, good, 4)
+	if res.Streak != 5 || res.StreakBonus != StreakBonus || res.Change != 4 {
+		t.Errorf("5th positive day should get the bonus: %+v", res)
+	}
+
+	res = Apply(30, Tally{Lost: 10}, 4)
+	if res.Streak != 0 || res.StreakBonus != 0 || res.Change != -3 {
+		t.Errorf("a negative day breaks the streak: %+v", res)
+	}
+}
+
+func TestApplyClamps(t *testing.T) {
+	if res := Apply(99, Tally{VeryHappy: 10}, 0); res.Reputation != 100 || res.Change != 1 {
+		t.Errorf("expected clamp at 100 with change 1: %+v", res)
+	}
+	if res := Apply(1, Tally{Complaints: 10}, 0); res.Reputation != 0 || res.Change != -1 {
+		t.Errorf("expected clamp at 0 with change -1: %+v", res)
+	}
+
+	// A good day at the top earns points even if none apply, and keeps the streak
+	res := Apply(100, Tally{VeryHappy: 3}, 2)
+	if res.Change != 0 || res.Earned != 3 || res.Streak != 3 {
+		t.Errorf("expected 3 earned and the streak going at 100: %+v", res)
+	}
+	if got := StreakFrom([]int{res.Earned, 2, 1}); got != 3 {
+		t.Errorf("expected the capped day to count for the streak, got %d", got)
+	}
+}
+
+func TestStreakFrom(t *testing.T) {
+	if got := StreakFrom([]int{3, 1, 2, 0, 5}); got != 3 {
+		t.Errorf("expected streak 3, got %d", got)
+	}
+	if got := StreakFrom(nil); got != 0 {
+		t.Errorf("expected streak 0, got %d", got)
+	}
+}
+
+func TestTiers(t *testing.T) {
+	tests := []struct {
+		rep     int
+		name    string
+		traffic float64
+	}{
+		{0, "Desconocido", 1.0},
+		{20, "Desconocido", 1.0},
+		{21, "Conocido", 1.1},
+		{50, "Popular", 1.2},
+		{61, "Famoso", 1.3},
+		{100, "Leyenda", 1.5},
+		{150, "Leyenda", 1.5},
+	}
+	for _, tt := range tests {
+		if got := TierFor(tt.rep).Name; got != tt.name {
+			t.Errorf("TierFor(%d) = %s, want %s", tt.rep, got, tt.name)
+		}
+		if got := TrafficModifier(tt.rep); got != tt.traffic {
+			t.Errorf("TrafficModifier(%d) = %v, want %v", tt.rep, got, tt.traffic)
+		}
+	}
+}
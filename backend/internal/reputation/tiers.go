@@ -0,0 +1,35 @@
+package reputation
+
+// Tier is a reputation level and its effects (GDD 4.5)
+type Tier struct {
+	Code         string  `json:"code"`
+	Name         string  `json:"name"`
+	Min          int     `json:"min"`
+	Max          int     `json:"max"`
+	TrafficBonus float64 `json:"traffic_bonus"` // extra customers, 0.1 = +10%
+}
+
+// Tiers from lowest to highest
+var Tiers = []Tier{
+	{Code: "unknown", Name: "Desconocido", Min: 0, Max: 20, TrafficBonus: 0},
+	{Code: "known", Name: "Conocido", Min: 21, Max: 40, TrafficBonus: 0.10},
+	{Code: "popular", Name: "Popular", Min: 41, Max: 60, TrafficBonus: 0.20},
+	{Code: "famous", Name: "Famoso", Min: 61, Max: 80, TrafficBonus: 0.30},
+	{Code: "legend", Name: "Leyenda", Min: 81, Max: 100, TrafficBonus: 0.50},
+}
+
+// TierFor returns the tier of a reputation value
+func TierFor(rep int) Tier {
+	rep = Clamp(rep)
+	for i := len(Tiers) - 1; i > 0; i-- {
+		if rep >= Tiers[i].Min {
+			return Tiers[i]
+		}
+	}
+	return Tiers[0]
+}
+
+// TrafficModifier is the customer multiplier for a reputation (1.0 to 1.5)
+func TrafficModifier(rep int) float64 {
+	return 1 + TierFor(rep).TrafficBonus
+}
@@ -0,0 +1,137 @@
+package simulation
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/models"
+	"github.com/alonsoalpizar/calleviva/backend/internal/reputation"
+	"github.com/alonsoalpizar/calleviva/backend/internal/weather"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+// GET /api/v1/games/{gameID}/day
+// Current day info: weather and its effects, tomorrow's forecast and locations
+func (h *Handler) GetDay(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	var s sessionState
+	err = h.db.QueryRow(ctx, `
+		SELECT world_type, game_day, reputation, current_location, location_day,
+		       COALESCE(weather, 'sunny'), weather_forecast
+		FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+	`, gameID, playerID).Scan(
+		&s.WorldType, &s.GameDay, &s.Reputation, &s.Location, &s.LocationDay,
+		&s.Weather, &s.Forecast,
+	)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	types := loadWeatherTypes(ctx, h.db)
+	today, ok := weather.Find(types, s.Weather)
+	if !ok {
+		today = weather.Type{Code: s.Weather, Name: s.Weather, TrafficModifier: 1.0}
+	}
+
+	forecast := s.Forecast
+	if len(forecast) == 0 {
+		forecast = weather.Tomorrow(types, s.Weather)
+	}
+
+	locations, err := h.availableLocations(ctx, models.WorldSuffix(s.WorldType), s.Reputation)
+	if err != nil {
+		log.Printf("Error loading locations: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar las ubicaciones"})
+		return
+	}
+
+	location := ""
+	if s.Location != nil {
+		location = *s.Location
+	}
+	events, err := loadEvents(ctx, h.db, gameID, s.GameDay, location)
+	if err != nil {
+		log.Printf("Error loading events: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar los eventos"})
+		return
+	}
+
+	render.JSON(w, r, DayInfoResponse{
+		GameDay:    s.GameDay,
+		Reputation: s.Reputation,
+		Tier:       reputation.TierFor(s.Reputation),
+		Weather:    s.Weather,
+		WeatherEffects: WeatherEffects{
+			Name:              today.Name,
+			Icon:              today.Icon,
+			TrafficModifier:   today.TrafficModifier,
+			CategoryModifiers: today.CategoryModifiers,
+			Message:           today.Message,
+		},
+		Forecast: ForecastInfo{
+			Tomorrow:      forecast.Chances(types),
+			Probabilities: forecast,
+		},
+		Events:             events,
+		Location:           s.Location,
+		LocationPaid:       s.Location != nil && s.LocationDay != nil && *s.LocationDay == s.GameDay,
+		AvailableLocations: locations,
+	})
+}
+
+// availableLocations lists the world's locations and whether the player's
+// reputation unlocks them
+func (h *Handler) availableLocations(ctx context.Context, suffix string, reputation int) ([]AvailableLocation, error) {
+	rows, err := h.db.Query(ctx, `
+		SELECT code, name, COALESCE(icon, ''),
+		       COALESCE((config->>'rent')::float8, 0),
+		       COALESCE(config->>'foot_traffic', ''),
+		       COALESCE((config->>'min_reputation')::int, 0),
+		       COALESCE(config->>'requirement', '')
+		FROM parameters
+		WHERE category = $1 AND is_active = true
+		ORDER BY sort_order, code
+	`, "locations_"+suffix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := []AvailableLocation{}
+	for rows.Next() {
+		var l AvailableLocation
+		if err := rows.Scan(&l.ID, &l.Name, &l.Icon, &l.Cost, &l.TrafficLevel, &l.MinReputation, &l.Requirement); err != nil {
+			return nil, err
+		}
+		l.Unlocked = reputation >= l.MinReputation
+		locations = append(locations, l)
+	}
+	return locations, rows.Err()
+}
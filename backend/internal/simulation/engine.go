@@ -0,0 +1,233 @@
+package simulation
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/customers"
+	"github.com/alonsoalpizar/calleviva/backend/internal/demand"
+)
+
+// baseServicePerHour is how many customers a speed 1.0 truck can serve in an hour
+const baseServicePerHour = 12
+
+// Run simulates a full day hour by hour.
+// The result depends only on the input, so the same seed replays the same day.
+func Run(in DayInput) *DayResult {
+	gen := customers.NewGenerator(in.Seed, in.Location, in.CustomerTypes, customers.Conditions{
+		WeatherModifier: in.WeatherModifier,
+		WeatherMood:     in.WeatherMood,
+		EventModifier:   in.EventModifier,
+		Reputation:      in.Reputation,
+	})
+	rng := gen.Rand()
+
+	res := &DayResult{
+		Day:          in.Day,
+		LostReasons:  map[string]int{},
+		LocationCost: int64(in.Location.Rent),
+	}
+
+	speed := in.SpeedMultiplier
+	if speed <= 0 {
+		speed = 1.0
+	}
+	servicePerHour := float64(baseServicePerHour) * speed
+	tolerance := in.QueueTolerance
+	if tolerance <= 0 {
+		tolerance = 1.0
+	}
+
+	products := make([]demand.Product, len(in.Menu))
+	for i, item := range in.Menu {
+		products[i] = demand.Product{
+			Code:          item.Code,
+			Price:         item.Price,
+			ExpectedPrice: item.ExpectedPrice,
+			Flavor:        item.Flavor,
+			Popularity:    weatherPopularity(item, in.CategoryModifiers),
+		}
+	}
+
+	stock := in.Capacity
+	ingredients := make(map[string]int, len(in.Stock))
+	for code, n := range in.Stock {
+		ingredients[code] = n
+	}
+	unitsSold := map[string]int{}
+	lose := func(hs *HourStats, reason string) {
+		hs.Lost++
+		res.LostReasons[reason]++
+		res.ReputationTally.Lost++
+	}
+
+	for hour := customers.OpenHour; hour <= customers.CloseHour; hour++ {
+		hs := HourStats{Hour: hour}
+		arrivals := gen.Hour(hour)
+		hs.Customers = len(arrivals)
+
+		for _, c := range arrivals {
+			if len(in.Menu) == 0 || stock <= 0 {
+				lose(&hs, LostSoldOut)
+				continue
+			}
+			if float64(hs.Served) >= servicePerHour*queueTolerance(c.Patience)*tolerance {
+				lose(&hs, LostQueue)
+				continue
+			}
+
+			d := demand.Decide(rng, c, products)
+			if d.Complaint {
+				res.Complaints++
+				res.ReputationTally.Complaints++
+			}
+			switch d.Outcome {
+			case demand.OutcomeNoMatch:
+				lose(&hs, LostNoMatch)
+				continue
+			case demand.OutcomeTooExpensive:
+				lose(&hs, LostTooExpensive)
+				continue
+			}
+
+			// A family orders for everyone; take what's left if it's not enough
+			item := in.Menu[d.Index]
+			units := inStock(item, ingredients, min(max(c.OrderSize, 1), stock))
+			if units == 0 {
+				lose(&hs, LostSoldOut)
+				continue
+			}
+			sat := satisfaction(rng, item, d.Perception)
+			switch {
+			case sat >= 9:
+				res.ReputationTally.VeryHappy++
+			case sat >= 6:
+				res.ReputationTally.Satisfied++
+			}
+
+			stock -= units
+			hs.Served++
+			charged := item.Price
+			if in.CheckoutErrorRate > 0 && rng.Float64() < in.CheckoutErrorRate {
+				// Wrong change: the customer leaves happy, the money doesn't
+				res.CheckoutErrors++
+				res.CheckoutLosses += int64(item.Price * units)
+				charged = 0
+			}
+			hs.Revenue += int64(charged * units)
+			unitsSold[item.Code] += units
+			res.IngredientCosts += int64(item.Cost * units)
+			if len(item.Ingredients) > 0 {
+				res.InventoryCosts += int64(item.Cost * units)
+				if res.IngredientsUsed == nil {
+					res.IngredientsUsed = map[string]int{}
+				}
+				for _, code := range item.Ingredients {
+					ingredients[code] -= units
+					res.IngredientsUsed[code] += units
+				}
+			}
+			// One sale per unit, so sales_log counts servings
+			for range units {
+				res.Sales = append(res.Sales, Sale{
+					Hour:         hour,
+					ItemCode:     item.Code,
+					Price:        charged,
+					Cost:         item.Cost,
+					CustomerType: c.Type,
+					Satisfaction: sat,
+				})
+			}
+		}
+
+		res.CustomersServed += hs.Served
+		res.CustomersLost += hs.Lost
+		res.Revenue += hs.Revenue
+		res.Hours = append(res.Hours, hs)
+	}
+
+	res.Costs = res.IngredientCosts + res.LocationCost
+	res.Profit = res.Revenue - res.Costs
+	// Provisional: the handler applies the streak bonus and clamping
+	res.ReputationChange = int(math.Round(res.ReputationTally.Points()))
+	res.TopProduct = topProduct(unitsSold)
+
+	return res
+}
+
+// weatherPopularity applies today's weather to how much an item is wanted
+// (ice cream on a sunny day, hot drinks in the rain)
+func weatherPopularity(item MenuItem, modifiers map[string]float64) int {
+	m, ok := modifiers[item.Category]
+	if !ok || m <= 0 {
+		return item.Popularity
+	}
+	if p := int(math.Round(float64(item.Popularity) * m)); p > 1 {
+		return p
+	}
+	return 1
+}
+
+// inStock returns how many of the wanted units of an item the ingredients
+// left can make
+func inStock(item MenuItem, stock map[string]int, want int) int {
+	for _, code := range item.Ingredients {
+		want = min(want, max(stock[code], 0))
+	}
+	return want
+}
+
+// queueTolerance scales how busy the truck can be before a customer gives
+// up on the line: patience 5 waits for a full hour of service
+func queueTolerance(patience int) float64 {
+	return 0.7 + float64(patience)*0.06
+}
+
+// satisfaction scores a purchase from 1 to 10: cheaper than expected and
+// popular items make happier customers, while prices outside the fair band
+// (complaints or suspected low quality) hurt
+func satisfaction(rng *rand.Rand, item MenuItem, perception string) int {
+	expected := float64(item.ExpectedPrice)
+	if expected <= 0 {
+		expected = float64(item.Price)
+	}
+	ratio := 1.0
+	if expected > 0 {
+		ratio = float64(item.Price) / expected
+	}
+
+	score := 7 + (1-ratio)*6 + float64(item.Popularity-50)/25 + (rng.Float64()*2 - 1)
+	switch perception {
+	case demand.PerceptionLowQuality:
+		// A suspiciously cheap dish doesn't feel like a bargain
+		score = math.Min(score, 7) - 2
+	case demand.PerceptionComplaint:
+		score -= 2
+	}
+	sat := int(math.Round(score))
+	if sat < 1 {
+		return 1
+	}
+	if sat > 10 {
+		return 10
+	}
+	return sat
+}
+
+// topProduct returns the item with most units sold (ties broken by code)
+func topProduct(units map[string]int) string {
+	codes := make([]string, 0, len(units))
+	for code := range units {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	top, best := "", 0
+	for _, code := range codes {
+		if units[code] > best {
+			top, best = code, units[code]
+		}
+	}
+	return top
+}
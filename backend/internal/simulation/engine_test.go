@@ -0,0 +1,174 @@
+package simulation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/customers"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ledger"
+)
+
+func testInput(seed int64) DayInput {
+	return DayInput{
+		Seed: seed,
+		Day:  3,
+		Location: customers.Location{
+			Code:        "centro",
+			Name:        "Centro",
+			FootTraffic: "high",
+			Competition: "medium",
+			BestHours:   "11-14",
+			Rent:        2000,
+		},
+		Weather:         "sunny",
+		WeatherModifier: 1.2,
+		Reputation:      50,
+		Menu: []MenuItem{
+			{Code: "gallo_pinto", Name: "Gallo Pinto", Kind: KindProduct, Price: 2000, Cost: 800, ExpectedPrice: 2000, Popularity: 70},
+			{Code: "casado", Name: "Casado", Kind: KindProduct, Price: 3500, Cost: 1500, ExpectedPrice: 3500, Popularity: 85},
+		},
+		Capacity:        40,
+		SpeedMultiplier: 1.0,
+	}
+}
+
+func TestRunIsDeterministic(t *testing.T) {
+	a := Run(testInput(42))
+	b := Run(testInput(42))
+
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("same seed produced different days:\n%+v\n%+v", a, b)
+	}
+}
+
+func TestRunTotals(t *testing.T) {
+	res := Run(testInput(7))
+
+	if len(res.Hours) != customers.CloseHour-customers.OpenHour+1 {
+		t.Errorf("expected %d hours, got %d", customers.CloseHour-customers.OpenHour+1, len(res.Hours))
+	}
+	if res.CustomersServed == 0 || res.CustomersServed > len(res.Sales) {
+		t.Errorf("served %d customers but recorded %d sales", res.CustomersServed, len(res.Sales))
+	}
+	if len(res.Sales) > 40 {
+		t.Errorf("sold %d servings with capacity 40", len(res.Sales))
+	}
+
+	var revenue, costs int64
+	for _, s := range res.Sales {
+		revenue += int64(s.Price)
+		costs += int64(s.Cost)
+	}
+	if revenue != res.Revenue {
+		t.Errorf("revenue %d, sales add up to %d", res.Revenue, revenue)
+	}
+	if res.Costs != costs+res.LocationCost {
+		t.Errorf("costs %d, expected %d", res.Costs, costs+res.LocationCost)
+	}
+	if res.Profit != res.Revenue-res.Costs {
+		t.Errorf("profit %d, expected %d", res.Profit, res.Revenue-res.Costs)
+	}
+}
+
+func TestRunEmptyMenuLosesEveryone(t *testing.T) {
+	in := testInput(1)
+	in.Menu = nil
+
+	res := Run(in)
+	if res.CustomersServed != 0 {
+		t.Errorf("expected no sales, got %d", res.CustomersServed)
+	}
+	if res.CustomersLost != res.LostReasons[LostSoldOut] {
+		t.Errorf("expected all lost customers to be sold_out, got %v", res.LostReasons)
+	}
+}
+
+func TestRunCheckoutErrors(t *testing.T) {
+	in := testInput(7)
+	in.CheckoutErrorRate = 1
+	res := Run(in)
+
+	if res.CheckoutErrors != res.CustomersServed {
+		t.Errorf("expected every sale charged wrong, got %d of %d", res.CheckoutErrors, res.CustomersServed)
+	}
+	if res.Revenue != 0 || res.CheckoutLosses == 0 {
+		t.Errorf("expected all revenue lost, revenue %d losses %d", res.Revenue, res.CheckoutLosses)
+	}
+}
+
+func TestRunStockOut(t *testing.T) {
+	in := testInput(7)
+	in.Menu = []MenuItem{
+		{Code: "dish:1", Name: "Casado", Kind: KindDish, Price: 3000, Cost: 1300, ExpectedPrice: 3000, Popularity: 80, Ingredients: []string{"arroz", "pollo"}},
+	}
+	in.Stock = map[string]int{"arroz": 10, "pollo": 3}
+	res := Run(in)
+
+	if res.CustomersServed != 3 {
+		t.Errorf("expected 3 sales with 3 units of pollo, got %d", res.CustomersServed)
+	}
+	if res.IngredientsUsed["pollo"] != 3 || res.IngredientsUsed["arroz"] != 3 {
+		t.Errorf("unexpected ingredients used %v", res.IngredientsUsed)
+	}
+	if res.LostReasons[LostSoldOut] == 0 {
+		t.Error("expected customers lost to the stock-out")
+	}
+	if res.InventoryCosts != res.IngredientCosts {
+		t.Errorf("dish costs should be prepaid: %d vs %d", res.InventoryCosts, res.IngredientCosts)
+	}
+}
+
+func TestRunSellsTheWholeOrder(t *testing.T) {
+	in := testInput(5)
+	in.CustomerTypes = []customers.Type{{Code: "family", Name: "Familia", Budget: "medium", Patience: "high", OrderSize: 3}}
+	in.Capacity = 1000
+	res := Run(in)
+
+	if res.CustomersServed == 0 || len(res.Sales) != 3*res.CustomersServed {
+		t.Errorf("%d families should buy 3 servings each, got %d sales", res.CustomersServed, len(res.Sales))
+	}
+
+	// The last family takes what's left
+	in.Capacity = 4
+	res = Run(in)
+	if res.CustomersServed != 2 || len(res.Sales) != 4 {
+		t.Errorf("expected 2 families to share 4 servings, got %d with %d sales", res.CustomersServed, len(res.Sales))
+	}
+}
+
+func TestDayEntriesAddUpToCashDelta(t *testing.T) {
+	res := &DayResult{Day: 3, Revenue: 9000, IngredientCosts: 4000, InventoryCosts: 3000, LoanRepayment: 500}
+	entries := dayEntries(res, "parque")
+	if got := ledger.Total(entries); got != 9000-1000-500 {
+		t.Errorf("expected the entries to add up to 7500, got %d", got)
+	}
+	for _, e := range entries {
+		if e.GameDay != 3 || e.Reason == "" || e.Account == "" {
+			t.Errorf("incomplete entry %+v", e)
+		}
+	}
+}
+
+func TestEventsBoostTraffic(t *testing.T) {
+	if mod := eventModifier(nil); mod != 1.0 {
+		t.Errorf("no events should be neutral, got %v", mod)
+	}
+	events := []ActiveEvent{{Code: "festival", CustomerBoost: 2.0}, {Code: "rain_sale", CustomerBoost: 0.5}, {Code: "food_critic"}}
+	if mod := eventModifier(events); mod != 1.0 {
+		t.Errorf("festival and rain should cancel out, got %v", mod)
+	}
+
+	customersIn := func(res *DayResult) int {
+		total := 0
+		for _, h := range res.Hours {
+			total += h.Customers
+		}
+		return total
+	}
+	in := testInput(11)
+	plain := customersIn(Run(in))
+	in.EventModifier = 2.0
+	if festival := customersIn(Run(in)); festival <= plain {
+		t.Errorf("a festival should bring more customers: %d vs %d", festival, plain)
+	}
+}
@@ -0,0 +1,49 @@
+package simulation
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// loadEvents reads the events logged for the game that are still running on
+// day. Events tied to a location only count there; location "" keeps them all.
+func loadEvents(ctx context.Context, db querier, gameID uuid.UUID, day int, location string) ([]ActiveEvent, error) {
+	rows, err := db.Query(ctx, `
+		SELECT e.event_type, COALESCE(e.event_name, p.name), COALESCE(p.icon, ''),
+		       COALESCE((p.config->>'customer_boost')::float8, 1.0),
+		       e.game_day + COALESCE((p.config->>'duration_days')::int, 1) - $2
+		FROM events_log e
+		JOIN parameters p ON p.category = 'events' AND p.code = e.event_type AND p.is_active = true
+		WHERE e.session_id = $1
+		  AND e.game_day <= $2
+		  AND e.game_day + COALESCE((p.config->>'duration_days')::int, 1) > $2
+		  AND ($3 = '' OR COALESCE(e.event_data->>'location', $3) = $3)
+		ORDER BY e.game_day, e.created_at
+	`, gameID, day, location)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []ActiveEvent{}
+	for rows.Next() {
+		var ev ActiveEvent
+		if err := rows.Scan(&ev.Code, &ev.Name, &ev.Icon, &ev.CustomerBoost, &ev.DaysLeft); err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+// eventModifier combines the traffic boosts of the running events
+func eventModifier(events []ActiveEvent) float64 {
+	mod := 1.0
+	for _, ev := range events {
+		if ev.CustomerBoost > 0 {
+			mod *= ev.CustomerBoost
+		}
+	}
+	return mod
+}
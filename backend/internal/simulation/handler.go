@@ -0,0 +1,778 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/bankruptcy"
+	"github.com/alonsoalpizar/calleviva/backend/internal/customers"
+	"github.com/alonsoalpizar/calleviva/backend/internal/demand"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ingredients"
+	"github.com/alonsoalpizar/calleviva/backend/internal/inventory"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ledger"
+	"github.com/alonsoalpizar/calleviva/backend/internal/models"
+	"github.com/alonsoalpizar/calleviva/backend/internal/pricing"
+	"github.com/alonsoalpizar/calleviva/backend/internal/reputation"
+	"github.com/alonsoalpizar/calleviva/backend/internal/weather"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Truck defaults when the session has no truck row yet
+const (
+	defaultCapacity = 20
+	defaultSpeed    = 1.0
+	// share of sales a cart without a cash register charges wrong
+	defaultCheckoutErrors = 0.05
+)
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type Handler struct {
+	db *pgxpool.Pool
+}
+
+func NewHandler(db *pgxpool.Pool) *Handler {
+	return &Handler{db: db}
+}
+
+// SetupRoutes mounts day simulation routes (requires auth)
+func (h *Handler) SetupRoutes(r chi.Router) {
+	r.Get("/day", h.GetDay)
+	r.Post("/day/start", h.StartDay)
+	r.Get("/day/results", h.GetResults)
+}
+
+// sessionState is the part of game_sessions the simulation reads
+type sessionState struct {
+	WorldType   string
+	GameDay     int
+	Money       int64
+	Reputation  int
+	Location    *string
+	LocationDay *int
+	Weather     string
+	Forecast    weather.Forecast
+	Status      string
+	// Bankruptcy tracking
+	NegativeDays  int
+	LoanBalance   int64
+	RepaymentRate float64
+}
+
+// truckState is the part of trucks the simulation reads
+type truckState struct {
+	ID             *uuid.UUID
+	Capacity       int
+	Speed          float64
+	QueueTolerance float64
+	CheckoutErrors float64
+}
+
+// POST /api/v1/games/{gameID}/day/start
+// Runs the whole day on the server and closes it
+func (h *Handler) StartDay(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error de transacción"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the session so the same day can't be played twice concurrently
+	var s sessionState
+	err = tx.QueryRow(ctx, `
+		SELECT world_type, game_day, money, reputation, current_location, location_day,
+		       COALESCE(weather, 'sunny'), weather_forecast, COALESCE(status, 'active'),
+		       COALESCE(negative_days, 0), COALESCE(loan_balance, 0),
+		       COALESCE(loan_repayment_rate, 0)::float8
+		FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, gameID, playerID).Scan(
+		&s.WorldType, &s.GameDay, &s.Money, &s.Reputation,
+		&s.Location, &s.LocationDay, &s.Weather, &s.Forecast, &s.Status,
+		&s.NegativeDays, &s.LoanBalance, &s.RepaymentRate,
+	)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	if s.Status == bankruptcy.StatusBankrupt {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Estás en quiebra: elegí una opción para seguir"})
+		return
+	}
+	if s.Status != bankruptcy.StatusActive {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "La partida no está activa"})
+		return
+	}
+
+	// Rent is paid in /location/set, once per day
+	if s.Location == nil || *s.Location == "" || s.LocationDay == nil || *s.LocationDay != s.GameDay {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Elegí una ubicación antes de abrir"})
+		return
+	}
+
+	suffix := models.WorldSuffix(s.WorldType)
+
+	location, err := loadLocation(ctx, tx, suffix, *s.Location)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Ubicación no válida"})
+		return
+	}
+
+	truck, err := loadTruck(ctx, tx, gameID)
+	if err != nil {
+		log.Printf("Error loading truck: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar el truck"})
+		return
+	}
+
+	lots, err := inventory.LoadLots(ctx, tx, gameID)
+	if err != nil {
+		log.Printf("Error loading inventory: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar el inventario"})
+		return
+	}
+
+	// Ingredients not in stock are valued at today's market price
+	catalog := ingredients.Category(ctx, tx, s.WorldType)
+	prices, err := pricing.Load(ctx, tx, gameID, s.GameDay, s.Weather, catalog)
+	if err != nil {
+		log.Printf("Error loading market prices: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar los precios del mercado"})
+		return
+	}
+
+	menu, err := loadMenu(ctx, tx, gameID, playerID, suffix, catalog, lots, prices)
+	if err != nil {
+		log.Printf("Error loading menu: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar el menú"})
+		return
+	}
+	if len(menu) == 0 {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Tu menú está vacío"})
+		return
+	}
+
+	customerTypes, err := loadCustomerTypes(ctx, tx)
+	if err != nil {
+		log.Printf("Error loading customer types: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar los clientes"})
+		return
+	}
+
+	weatherTypes := loadWeatherTypes(ctx, tx)
+	today, ok := weather.Find(weatherTypes, s.Weather)
+	if !ok {
+		today = weather.Type{Code: s.Weather, TrafficModifier: 1.0, Mood: "neutral"}
+	}
+
+	events, err := loadEvents(ctx, tx, gameID, s.GameDay, location.Code)
+	if err != nil {
+		log.Printf("Error loading events: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar los eventos"})
+		return
+	}
+
+	// Keep the state before the day so a bankrupt player can replay it
+	if err := bankruptcy.SaveSnapshot(ctx, tx, gameID); err != nil {
+		log.Printf("Error saving snapshot for day %d of %s: %v", s.GameDay, gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al guardar el día"})
+		return
+	}
+
+	result := Run(DayInput{
+		Seed:              customers.Seed(gameID.String(), s.GameDay),
+		Day:               s.GameDay,
+		Location:          location,
+		CustomerTypes:     customerTypes,
+		Weather:           s.Weather,
+		WeatherModifier:   today.TrafficModifier,
+		WeatherMood:       today.Mood,
+		CategoryModifiers: today.CategoryModifiers,
+		EventModifier:     eventModifier(events),
+		Reputation:        s.Reputation,
+		Menu:              menu,
+		Capacity:          truck.Capacity,
+		SpeedMultiplier:   truck.Speed,
+		QueueTolerance:    truck.QueueTolerance,
+		CheckoutErrorRate: truck.CheckoutErrors,
+		Stock:             inventory.Stock(lots),
+	})
+
+	streak, err := loadStreak(ctx, tx, gameID)
+	if err != nil {
+		log.Printf("Error loading reputation streak: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al calcular la reputación"})
+		return
+	}
+	result.Events = events
+	rep := reputation.Apply(s.Reputation, result.ReputationTally, streak)
+	result.ReputationChange = rep.Change
+	result.ReputationEarned = rep.Earned
+	result.StreakBonus = rep.StreakBonus
+	result.Streak = rep.Streak
+
+	// Tomorrow's weather comes from the forecast the player saw today
+	forecast := s.Forecast
+	if len(forecast) == 0 {
+		forecast = weather.Tomorrow(weatherTypes, s.Weather)
+	}
+	// Take what was cooked from the inventory and throw away what spoiled
+	// overnight (lots that can't be used tomorrow)
+	if _, err := inventory.Use(ctx, tx, gameID, result.IngredientsUsed); err != nil {
+		log.Printf("Error consuming inventory for %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al actualizar el inventario"})
+		return
+	}
+	spoiled, err := inventory.SpoilExpired(ctx, tx, gameID, s.GameDay+1)
+	if err != nil {
+		log.Printf("Error spoiling inventory for %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al actualizar el inventario"})
+		return
+	}
+	result.Spoiled = spoiled
+	for _, sp := range spoiled {
+		result.SpoilageCost += sp.Value
+	}
+	result.Costs += result.SpoilageCost
+	result.Profit -= result.SpoilageCost
+
+	// Loans are paid back from the day's profit
+	result.LoanRepayment = bankruptcy.Repayment(result.Profit, s.LoanBalance, s.RepaymentRate)
+
+	rng := rand.New(rand.NewSource(customers.Seed(gameID.String()+":weather", s.GameDay+1)))
+	entries := dayEntries(result, location.Code)
+	next := nextDay{
+		Entries:       entries,
+		CashDelta:     ledger.Total(entries),
+		Reputation:    rep.Reputation,
+		Weather:       weather.Roll(rng, forecast),
+		LoanRepayment: result.LoanRepayment,
+	}
+	next.NegativeDays = bankruptcy.NegativeDays(s.NegativeDays, s.Money+next.CashDelta)
+	next.Status = bankruptcy.StatusAfter(s.Status, next.NegativeDays)
+	if next.Weather == "" {
+		next.Weather = s.Weather
+	}
+	next.Forecast = weather.Tomorrow(weatherTypes, next.Weather)
+
+	totals, err := saveDay(ctx, tx, gameID, truck.ID, location.Code, s.Weather, result, next)
+	if err != nil {
+		log.Printf("Error saving day %d for %s: %v", s.GameDay, gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al guardar el día"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cerrar el día"})
+		return
+	}
+
+	render.JSON(w, r, StartDayResponse{
+		Success:   true,
+		Location:  location.Code,
+		Weather:   s.Weather,
+		Summary:   result,
+		NewTotals: totals,
+	})
+}
+
+// loadLocation reads a location from the world's locations_* parameters
+func loadLocation(ctx context.Context, tx pgx.Tx, suffix, code string) (customers.Location, error) {
+	var name string
+	var config []byte
+	err := tx.QueryRow(ctx, `
+		SELECT name, config FROM parameters
+		WHERE category = $1 AND code = $2 AND is_active = true
+	`, "locations_"+suffix, code).Scan(&name, &config)
+	if err != nil {
+		return customers.Location{}, err
+	}
+	return customers.ParseLocation(code, name, config)
+}
+
+// loadCustomerTypes reads the customer_types parameters (nil if none configured)
+func loadCustomerTypes(ctx context.Context, tx pgx.Tx) ([]customers.Type, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT code, name, config FROM parameters
+		WHERE category = 'customer_types' AND is_active = true
+		ORDER BY sort_order, code
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []customers.Type
+	for rows.Next() {
+		var code, name string
+		var config []byte
+		if err := rows.Scan(&code, &name, &config); err != nil {
+			return nil, err
+		}
+		t, err := customers.ParseType(code, name, config)
+		if err != nil {
+			log.Printf("Skipping customer type %s: %v", code, err)
+			continue
+		}
+		types = append(types, t)
+	}
+	return types, rows.Err()
+}
+
+// loadStreak counts the consecutive positive reputation days before today
+func loadStreak(ctx context.Context, tx pgx.Tx, gameID uuid.UUID) (int, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT COALESCE(reputation_earned, reputation_change) FROM day_summaries
+		WHERE session_id = $1
+		ORDER BY game_day DESC
+		LIMIT 30
+	`, gameID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var changes []int
+	for rows.Next() {
+		var c int
+		if err := rows.Scan(&c); err != nil {
+			return 0, err
+		}
+		changes = append(changes, c)
+	}
+	return reputation.StreakFrom(changes), rows.Err()
+}
+
+// loadWeatherTypes reads the weather parameters, falling back to the GDD defaults
+func loadWeatherTypes(ctx context.Context, db querier) []weather.Type {
+	rows, err := db.Query(ctx, `
+		SELECT code, name, COALESCE(icon, ''), config FROM parameters
+		WHERE category = 'weather' AND is_active = true
+		ORDER BY sort_order, code
+	`)
+	if err != nil {
+		return weather.DefaultTypes
+	}
+	defer rows.Close()
+
+	var types []weather.Type
+	for rows.Next() {
+		var code, name, icon string
+		var config []byte
+		if err := rows.Scan(&code, &name, &icon, &config); err != nil {
+			return weather.DefaultTypes
+		}
+		t, err := weather.ParseType(code, name, icon, config)
+		if err != nil {
+			log.Printf("Skipping weather %s: %v", code, err)
+			continue
+		}
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return weather.DefaultTypes
+	}
+	return types
+}
+
+// loadTruck reads the session's truck, falling back to a basic cart
+func loadTruck(ctx context.Context, tx pgx.Tx, gameID uuid.UUID) (truckState, error) {
+	truck := truckState{Capacity: defaultCapacity, Speed: defaultSpeed, QueueTolerance: 1.0, CheckoutErrors: defaultCheckoutErrors}
+
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, `
+		SELECT id, COALESCE(capacity, $2), COALESCE(speed_multiplier, 1.0)::float8,
+		       COALESCE(queue_tolerance, 1.0)::float8, COALESCE(checkout_error_rate, $3)::float8
+		FROM trucks
+		WHERE session_id = $1
+		ORDER BY created_at
+		LIMIT 1
+	`, gameID, defaultCapacity, defaultCheckoutErrors).Scan(
+		&id, &truck.Capacity, &truck.Speed, &truck.QueueTolerance, &truck.CheckoutErrors,
+	)
+	if err == pgx.ErrNoRows {
+		return truck, nil
+	}
+	if err != nil {
+		return truck, err
+	}
+
+	truck.ID = &id
+	return truck, nil
+}
+
+// loadMenu builds the day's menu from active menu_items and in-menu lab dishes.
+// Dishes with ingredients the player never bought are left out; the ones
+// that run out of stock during the day lose their sales.
+func loadMenu(ctx context.Context, tx pgx.Tx, gameID, playerID uuid.UUID, suffix, catalog string, lots map[string][]inventory.Lot, prices pricing.Sheet) ([]MenuItem, error) {
+	var menu []MenuItem
+
+	rows, err := tx.Query(ctx, `
+		SELECT mi.product_type, p.name, mi.price,
+		       COALESCE((p.config->>'base_cost')::float8, 0),
+		       COALESCE((p.config->>'base_price')::float8, mi.price),
+		       COALESCE(p.config->>'popularity', ''),
+		       COALESCE(p.config->>'flavor', ''),
+		       COALESCE(p.config->>'category', '')
+		FROM menu_items mi
+		JOIN trucks t ON t.id = mi.truck_id
+		JOIN parameters p ON p.category = $2 AND p.code = mi.product_type AND p.is_active = true
+		WHERE t.session_id = $1 AND mi.is_active = true
+		ORDER BY mi.product_type
+	`, gameID, "products_"+suffix)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var item MenuItem
+		var cost, expected float64
+		var popularity string
+		if err := rows.Scan(&item.Code, &item.Name, &item.Price, &cost, &expected, &popularity, &item.Flavor, &item.Category); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		item.Kind = KindProduct
+		item.Cost = int(cost + 0.5)
+		item.ExpectedPrice = int(expected + 0.5)
+		item.Popularity = popularityScore(popularity)
+		menu = append(menu, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	owned, err := loadOwnedIngredients(ctx, tx, gameID, playerID, catalog)
+	if err != nil {
+		return nil, err
+	}
+	// Dishes cost what the ingredients in stock were paid for, or today's
+	// market price when there's nothing left
+	for code := range owned {
+		if l := lots[code]; len(l) > 0 {
+			owned[code] = inventory.AverageCost(l)
+		} else if q, ok := prices[code]; ok {
+			owned[code] = q.Price
+		}
+	}
+
+	dishRows, err := tx.Query(ctx, `
+		SELECT id, name, COALESCE(player_price, suggested_price), suggested_price,
+		       suggested_popularity, ingredients
+		FROM player_dishes
+		WHERE session_id = $1 AND player_id = $2 AND is_in_menu = true
+		ORDER BY created_at
+	`, gameID, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer dishRows.Close()
+
+	for dishRows.Next() {
+		var id uuid.UUID
+		var item MenuItem
+		var ingredientsJSON []byte
+		if err := dishRows.Scan(&id, &item.Name, &item.Price, &item.ExpectedPrice, &item.Popularity, &ingredientsJSON); err != nil {
+			return nil, err
+		}
+
+		cost, codes, ok := dishCost(ingredientsJSON, owned)
+		if !ok {
+			continue
+		}
+
+		item.Code = "dish:" + id.String()
+		item.Kind = KindDish
+		item.Cost = cost
+		item.Ingredients = codes
+		if cost > 0 {
+			item.ExpectedPrice = demand.SuggestedPrice(cost)
+		}
+		menu = append(menu, item)
+	}
+
+	return menu, dishRows.Err()
+}
+
+// loadOwnedIngredients returns the ingredients the player has bought and
+// their market unit cost
+func loadOwnedIngredients(ctx context.Context, tx pgx.Tx, gameID, playerID uuid.UUID, catalog string) (map[string]int, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT pi.ingredient_code, COALESCE((p.config->>'cost')::int, 0)
+		FROM player_ingredients pi
+		JOIN market_ingredients p ON p.code = pi.ingredient_code AND p.category = $3
+		WHERE pi.session_id = $1 AND pi.player_id = $2
+	`, gameID, playerID, catalog)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	owned := map[string]int{}
+	for rows.Next() {
+		var code string
+		var cost int
+		if err := rows.Scan(&code, &cost); err != nil {
+			return nil, err
+		}
+		owned[code] = cost
+	}
+	return owned, rows.Err()
+}
+
+// dishCost adds up a dish's ingredient costs and lists the ones taken from
+// the inventory. Returns false when an ingredient sold in the market isn't
+// owned.
+func dishCost(ingredientsJSON []byte, owned map[string]int) (int, []string, bool) {
+	var items []struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(ingredientsJSON, &items); err != nil {
+		return 0, nil, false
+	}
+
+	total := 0
+	var codes []string
+	for _, ing := range items {
+		// Creator ingredients picked before they were sold in the market
+		// (plain content_creations ids) stay free
+		if _, sold := ingredients.CreationID(ing.ID); ing.Type == "creator" && !sold {
+			continue
+		}
+		cost, ok := owned[ing.ID]
+		if !ok {
+			return 0, nil, false
+		}
+		total += cost
+		codes = append(codes, ing.ID)
+	}
+	return total, codes, true
+}
+
+// popularityScore maps the products config popularity level to 1-100
+func popularityScore(level string) int {
+	switch level {
+	case "very_high":
+		return 85
+	case "high":
+		return 70
+	case "medium":
+		return 50
+	case "low":
+		return 30
+	}
+	if n, err := strconv.Atoi(level); err == nil && n > 0 {
+		return n
+	}
+	return 60
+}
+
+// nextDay is what changes on the session when a day closes
+type nextDay struct {
+	Entries    []ledger.Entry   // money movements of the day
+	CashDelta  int64            // what the entries add to money
+	Reputation int              // reputation after the day, already clamped
+	Weather    string           // weather of the new day
+	Forecast   weather.Forecast // forecast for the day after
+	// Bankruptcy tracking
+	LoanRepayment int64  // subtracted from loan_balance
+	NegativeDays  int    // days in a row closed with negative money
+	Status        string // 'bankrupt' after too many negative days
+}
+
+// saveDay writes the sales, the day summary and advances the session
+func saveDay(ctx context.Context, tx pgx.Tx, gameID uuid.UUID, truckID *uuid.UUID, location, today string, res *DayResult, next nextDay) (NewTotals, error) {
+	var totals NewTotals
+
+	if len(res.Sales) > 0 {
+		batch := &pgx.Batch{}
+		for _, sale := range res.Sales {
+			batch.Queue(`
+				INSERT INTO sales_log
+				(session_id, truck_id, game_day, game_hour, product_type, price_sold, cost,
+				 customer_type, customer_satisfaction, location, weather)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			`, gameID, truckID, res.Day, sale.Hour, sale.ItemCode, sale.Price, sale.Cost,
+				sale.CustomerType, sale.Satisfaction, location, today)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return totals, err
+		}
+	}
+
+	var topProduct *string
+	if res.TopProduct != "" {
+		topProduct = &res.TopProduct
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO day_summaries
+		(session_id, game_day, location, weather, total_revenue, total_costs, total_profit,
+		 customers_served, customers_lost, reputation_change, reputation_earned, top_product, events)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, gameID, res.Day, location, today, res.Revenue, res.Costs, res.Profit,
+		res.CustomersServed, res.CustomersLost, res.ReputationChange, res.ReputationEarned, topProduct, res.Events)
+	if err != nil {
+		return totals, err
+	}
+
+	if err := updateDishStats(ctx, tx, res.Sales); err != nil {
+		return totals, err
+	}
+
+	if _, err := ledger.PostAll(ctx, tx, gameID, next.Entries); err != nil {
+		return totals, err
+	}
+	if err := bankruptcy.RepayLoan(ctx, tx, gameID, res.Day, next.LoanRepayment); err != nil {
+		return totals, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE game_sessions
+		SET reputation = $2,
+		    game_day = game_day + 1,
+		    weather = $3,
+		    weather_forecast = $4,
+		    loan_balance = GREATEST(COALESCE(loan_balance, 0) - $5, 0),
+		    negative_days = $6,
+		    status = $7,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING money, reputation, game_day, weather, loan_balance, negative_days, status
+	`, gameID, next.Reputation, next.Weather, next.Forecast,
+		next.LoanRepayment, next.NegativeDays, next.Status).Scan(
+		&totals.Money, &totals.Reputation, &totals.GameDay, &totals.Weather,
+		&totals.LoanBalance, &totals.NegativeDays, &totals.Status,
+	)
+	totals.Bankrupt = totals.Status == bankruptcy.StatusBankrupt
+	totals.Tier = reputation.TierFor(totals.Reputation)
+	totals.Forecast = next.Forecast
+
+	return totals, err
+}
+
+// dayEntries are the money movements of a simulated day. The location was
+// already charged when it was set and inventory ingredients when they were
+// bought; what's left are the sales, the catalog products' ingredients paid
+// during the day and the loan repayment.
+func dayEntries(res *DayResult, location string) []ledger.Entry {
+	return []ledger.Entry{
+		{
+			GameDay:     res.Day,
+			Amount:      res.Revenue,
+			Account:     ledger.AccountSales,
+			Reason:      ledger.ReasonSales,
+			Reference:   location,
+			Description: fmt.Sprintf("Ventas del día %d", res.Day),
+		},
+		{
+			GameDay:     res.Day,
+			Amount:      -(res.IngredientCosts - res.InventoryCosts),
+			Account:     ledger.AccountInventory,
+			Reason:      ledger.ReasonSupplies,
+			Description: "Ingredientes comprados durante el día",
+		},
+		{
+			GameDay:     res.Day,
+			Amount:      -res.LoanRepayment,
+			Account:     ledger.AccountLoans,
+			Reason:      ledger.ReasonLoanRepayment,
+			Description: "Pago del préstamo",
+		},
+	}
+}
+
+// updateDishStats accumulates sales and satisfaction on the lab dishes sold
+func updateDishStats(ctx context.Context, tx pgx.Tx, sales []Sale) error {
+	type dishStats struct {
+		sold, satisfaction int
+		revenue            int64
+	}
+	stats := map[string]*dishStats{}
+	for _, sale := range sales {
+		id, ok := strings.CutPrefix(sale.ItemCode, "dish:")
+		if !ok {
+			continue
+		}
+		st, ok := stats[id]
+		if !ok {
+			st = &dishStats{}
+			stats[id] = st
+		}
+		st.sold++
+		st.satisfaction += sale.Satisfaction
+		st.revenue += int64(sale.Price)
+	}
+
+	for id, st := range stats {
+		_, err := tx.Exec(ctx, `
+			UPDATE player_dishes
+			SET avg_satisfaction = (avg_satisfaction * times_sold + $3) / (times_sold + $2),
+			    times_sold = times_sold + $2,
+			    total_revenue = total_revenue + $4,
+			    updated_at = NOW()
+			WHERE id = $1
+		`, id, st.sold, st.satisfaction, st.revenue)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
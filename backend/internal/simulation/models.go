@@ -0,0 +1,227 @@
+package simulation
+
+import (
+	"encoding/json"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/customers"
+	"github.com/alonsoalpizar/calleviva/backend/internal/inventory"
+	"github.com/alonsoalpizar/calleviva/backend/internal/reputation"
+	"github.com/alonsoalpizar/calleviva/backend/internal/weather"
+)
+
+// Item kinds sold from the truck
+const (
+	KindProduct = "product" // base product configured in menu_items
+	KindDish    = "dish"    // player dish from the Laboratorio
+)
+
+// Reasons a customer leaves without buying
+const (
+	LostTooExpensive = "too_expensive"
+	LostQueue        = "queue_too_long"
+	LostSoldOut      = "sold_out"
+	LostNoMatch      = "no_match"
+)
+
+// MenuItem is something the truck sells during the day
+type MenuItem struct {
+	Code          string `json:"code"` // product code, or "dish:<uuid>" for lab dishes
+	Name          string `json:"name"`
+	Kind          string `json:"kind"`
+	Price         int    `json:"price"`
+	Cost          int    `json:"cost"`               // cost per unit sold
+	ExpectedPrice int    `json:"expected_price"`     // what customers think it's worth
+	Popularity    int    `json:"popularity"`         // 1-100
+	Flavor        string `json:"flavor,omitempty"`   // sweet, savory, fresh
+	Category      string `json:"category,omitempty"` // food, frozen, cold_drink, hot_drink
+	// Ingredients are taken from the inventory, one unit each per sale
+	Ingredients []string `json:"ingredients,omitempty"`
+}
+
+// DayInput is everything the engine needs to simulate one day
+type DayInput struct {
+	Seed            int64
+	Day             int
+	Location        customers.Location
+	CustomerTypes   []customers.Type // nil uses customers.DefaultTypes
+	Weather         string
+	WeatherModifier float64 // traffic multiplier from the weather parameter
+	WeatherMood     string
+	// CategoryModifiers scales demand per product category for today's weather
+	CategoryModifiers map[string]float64
+	EventModifier     float64 // traffic multiplier of today's events, 0 or 1.0 when there are none
+	Reputation        int
+	Menu              []MenuItem
+	Capacity          int            // servings the truck can carry for the day
+	SpeedMultiplier   float64        // truck service speed
+	QueueTolerance    float64        // truck upgrades that make the line bearable, 0 = 1.0
+	CheckoutErrorRate float64        // chance a sale is charged wrong and its money is lost
+	Stock             map[string]int // ingredient units in the inventory
+}
+
+// Sale is a single completed purchase
+type Sale struct {
+	Hour         int    `json:"hour"`
+	ItemCode     string `json:"item_code"`
+	Price        int    `json:"price"`
+	Cost         int    `json:"cost"`
+	CustomerType string `json:"customer_type"`
+	Satisfaction int    `json:"satisfaction"` // 1-10
+}
+
+// HourStats summarizes one simulated hour
+type HourStats struct {
+	Hour      int   `json:"hour"`
+	Customers int   `json:"customers"`
+	Served    int   `json:"served"`
+	Lost      int   `json:"lost"`
+	Revenue   int64 `json:"revenue"`
+}
+
+// DayResult is the outcome of a simulated day
+type DayResult struct {
+	Day              int                 `json:"game_day"`
+	Sales            []Sale              `json:"-"`
+	Hours            []HourStats         `json:"hours"`
+	CustomersServed  int                 `json:"customers_served"`
+	CustomersLost    int                 `json:"customers_lost"`
+	LostReasons      map[string]int      `json:"lost_reasons"`
+	Complaints       int                 `json:"complaints"` // customers who found the price abusive
+	CheckoutErrors   int                 `json:"checkout_errors"`
+	CheckoutLosses   int64               `json:"checkout_losses"` // revenue lost to wrong change
+	Revenue          int64               `json:"total_revenue"`
+	IngredientCosts  int64               `json:"ingredient_costs"`
+	InventoryCosts   int64               `json:"-"` // part of IngredientCosts already paid at the market
+	IngredientsUsed  map[string]int      `json:"ingredients_used,omitempty"`
+	Spoiled          []inventory.Spoiled `json:"spoiled,omitempty"`
+	SpoilageCost     int64               `json:"spoilage_cost,omitempty"`
+	LocationCost     int64               `json:"location_cost"`
+	Costs            int64               `json:"total_costs"`
+	Profit           int64               `json:"total_profit"`
+	LoanRepayment    int64               `json:"loan_repayment,omitempty"` // taken from the profit
+	ReputationChange int                 `json:"reputation_change"`
+	ReputationEarned int                 `json:"reputation_earned"` // before clamping, drives the streak
+	ReputationTally  reputation.Tally    `json:"reputation_breakdown"`
+	StreakBonus      float64             `json:"streak_bonus,omitempty"`
+	Streak           int                 `json:"positive_streak"`
+	TopProduct       string              `json:"top_product,omitempty"`
+	Events           []ActiveEvent       `json:"events"`
+}
+
+// ActiveEvent is an event logged for the game that is still running
+type ActiveEvent struct {
+	Code          string  `json:"code"`
+	Name          string  `json:"name"`
+	Icon          string  `json:"icon,omitempty"`
+	CustomerBoost float64 `json:"customer_boost"` // traffic multiplier, 1.0 = none
+	DaysLeft      int     `json:"days_left"`      // counting today
+}
+
+// StartDayResponse is the response for POST /day/start
+type StartDayResponse struct {
+	Success   bool       `json:"success"`
+	Location  string     `json:"location"`
+	Weather   string     `json:"weather"`
+	Summary   *DayResult `json:"summary"`
+	NewTotals NewTotals  `json:"new_totals"`
+}
+
+// NewTotals is the session state after the day closes
+type NewTotals struct {
+	Money      int64            `json:"money"`
+	Reputation int              `json:"reputation"`
+	Tier       reputation.Tier  `json:"tier"`
+	GameDay    int              `json:"game_day"`
+	Weather    string           `json:"weather,omitempty"`  // weather of the new day
+	Forecast   weather.Forecast `json:"forecast,omitempty"` // forecast for the day after
+	// Bankruptcy: three days in a row with negative money end in 'bankrupt'
+	Status       string `json:"status"`
+	Bankrupt     bool   `json:"bankrupt"`
+	NegativeDays int    `json:"negative_days"`
+	LoanBalance  int64  `json:"loan_balance"`
+}
+
+// DaySummary is a stored day_summaries row
+type DaySummary struct {
+	TotalRevenue     int64   `json:"total_revenue"`
+	TotalCosts       int64   `json:"total_costs"`
+	TotalProfit      int64   `json:"total_profit"`
+	CustomersServed  int     `json:"customers_served"`
+	CustomersLost    int     `json:"customers_lost"`
+	ReputationChange int     `json:"reputation_change"`
+	TopProduct       *string `json:"top_product,omitempty"`
+}
+
+// HourBreakdown aggregates the sales of one hour from sales_log
+type HourBreakdown struct {
+	Hour            int     `json:"hour"`
+	Sales           int     `json:"sales"`
+	Revenue         int64   `json:"revenue"`
+	Cost            int64   `json:"cost"`
+	AvgSatisfaction float64 `json:"avg_satisfaction"`
+}
+
+// ProductBreakdown aggregates the sales of one product or dish from sales_log
+type ProductBreakdown struct {
+	Code            string  `json:"code"`
+	Name            string  `json:"name"`
+	Quantity        int     `json:"quantity"`
+	Revenue         int64   `json:"revenue"`
+	Cost            int64   `json:"cost"`
+	Profit          int64   `json:"profit"`
+	AvgSatisfaction float64 `json:"avg_satisfaction"`
+}
+
+// DayResultsResponse is the response for GET /day/results
+type DayResultsResponse struct {
+	GameDay   int                `json:"game_day"`
+	Location  *string            `json:"location,omitempty"`
+	Weather   *string            `json:"weather,omitempty"`
+	Summary   DaySummary         `json:"summary"`
+	Hours     []HourBreakdown    `json:"hours"`
+	Products  []ProductBreakdown `json:"products"`
+	Events    json.RawMessage    `json:"events"`
+	AITip     *string            `json:"ai_tip,omitempty"`
+	NewTotals NewTotals          `json:"new_totals"`
+}
+
+// WeatherEffects describes how today's weather affects business
+type WeatherEffects struct {
+	Name              string             `json:"name"`
+	Icon              string             `json:"icon,omitempty"`
+	TrafficModifier   float64            `json:"traffic_modifier"`
+	CategoryModifiers map[string]float64 `json:"category_modifiers,omitempty"`
+	Message           string             `json:"message,omitempty"`
+}
+
+// ForecastInfo is tomorrow's weather forecast
+type ForecastInfo struct {
+	Tomorrow      []weather.Chance `json:"tomorrow"`
+	Probabilities weather.Forecast `json:"probabilities"`
+}
+
+// AvailableLocation is a location the player can choose today
+type AvailableLocation struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Icon          string  `json:"icon,omitempty"`
+	Cost          float64 `json:"cost"`
+	TrafficLevel  string  `json:"traffic_level"`
+	MinReputation int     `json:"min_reputation"`
+	Requirement   string  `json:"requirement,omitempty"`
+	Unlocked      bool    `json:"unlocked"`
+}
+
+// DayInfoResponse is the response for GET /day
+type DayInfoResponse struct {
+	GameDay            int                 `json:"game_day"`
+	Reputation         int                 `json:"reputation"`
+	Tier               reputation.Tier     `json:"tier"`
+	Weather            string              `json:"weather"`
+	WeatherEffects     WeatherEffects      `json:"weather_effects"`
+	Forecast           ForecastInfo        `json:"forecast"`
+	Events             []ActiveEvent       `json:"events"`
+	Location           *string             `json:"location,omitempty"`
+	LocationPaid       bool                `json:"location_paid"`
+	AvailableLocations []AvailableLocation `json:"available_locations"`
+}
@@ -0,0 +1,165 @@
+package simulation
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// GET /api/v1/games/{gameID}/day/results
+// Returns the latest closed day, or ?day=N
+func (h *Handler) GetResults(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	day := 0
+	if dayParam := r.URL.Query().Get("day"); dayParam != "" {
+		day, err = strconv.Atoi(dayParam)
+		if err != nil || day < 1 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Día inválido"})
+			return
+		}
+	}
+
+	var resp DayResultsResponse
+	var worldType string
+	err = h.db.QueryRow(ctx, `
+		SELECT world_type, money, reputation, game_day
+		FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+	`, gameID, playerID).Scan(&worldType, &resp.NewTotals.Money, &resp.NewTotals.Reputation, &resp.NewTotals.GameDay)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	// day = 0 picks the latest summary
+	var events []byte
+	err = h.db.QueryRow(ctx, `
+		SELECT game_day, location, weather, total_revenue, total_costs, total_profit,
+		       customers_served, customers_lost, reputation_change, top_product,
+		       COALESCE(events, '[]'), ai_tip
+		FROM day_summaries
+		WHERE session_id = $1 AND ($2 = 0 OR game_day = $2)
+		ORDER BY game_day DESC
+		LIMIT 1
+	`, gameID, day).Scan(
+		&resp.GameDay, &resp.Location, &resp.Weather,
+		&resp.Summary.TotalRevenue, &resp.Summary.TotalCosts, &resp.Summary.TotalProfit,
+		&resp.Summary.CustomersServed, &resp.Summary.CustomersLost, &resp.Summary.ReputationChange,
+		&resp.Summary.TopProduct, &events, &resp.AITip,
+	)
+	if err == pgx.ErrNoRows {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "No hay resultados para ese día"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading day summary: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar resultados"})
+		return
+	}
+	resp.Events = events
+
+	resp.Hours, err = h.hourBreakdown(ctx, gameID, resp.GameDay)
+	if err != nil {
+		log.Printf("Error loading hour breakdown: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar resultados"})
+		return
+	}
+
+	resp.Products, err = h.productBreakdown(ctx, gameID, resp.GameDay, models.WorldSuffix(worldType))
+	if err != nil {
+		log.Printf("Error loading product breakdown: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar resultados"})
+		return
+	}
+
+	render.JSON(w, r, resp)
+}
+
+// hourBreakdown groups a day's sales by hour
+func (h *Handler) hourBreakdown(ctx context.Context, gameID uuid.UUID, day int) ([]HourBreakdown, error) {
+	rows, err := h.db.Query(ctx, `
+		SELECT game_hour, COUNT(*), SUM(price_sold), SUM(cost),
+		       COALESCE(AVG(customer_satisfaction), 0)::float8
+		FROM sales_log
+		WHERE session_id = $1 AND game_day = $2
+		GROUP BY game_hour
+		ORDER BY game_hour
+	`, gameID, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := []HourBreakdown{}
+	for rows.Next() {
+		var hb HourBreakdown
+		if err := rows.Scan(&hb.Hour, &hb.Sales, &hb.Revenue, &hb.Cost, &hb.AvgSatisfaction); err != nil {
+			return nil, err
+		}
+		hours = append(hours, hb)
+	}
+	return hours, rows.Err()
+}
+
+// productBreakdown groups a day's sales by product or lab dish, best sellers first
+func (h *Handler) productBreakdown(ctx context.Context, gameID uuid.UUID, day int, suffix string) ([]ProductBreakdown, error) {
+	rows, err := h.db.Query(ctx, `
+		SELECT s.product_type, COALESCE(p.name, d.name, s.product_type),
+		       COUNT(*), SUM(s.price_sold), SUM(s.cost),
+		       COALESCE(AVG(s.customer_satisfaction), 0)::float8
+		FROM sales_log s
+		LEFT JOIN parameters p ON p.category = $3 AND p.code = s.product_type
+		LEFT JOIN player_dishes d ON d.session_id = s.session_id
+		     AND d.id = CASE WHEN starts_with(s.product_type, 'dish:') THEN substring(s.product_type FROM 6)::uuid END
+		WHERE s.session_id = $1 AND s.game_day = $2
+		GROUP BY s.product_type, p.name, d.name
+		ORDER BY COUNT(*) DESC, s.product_type
+	`, gameID, day, "products_"+suffix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []ProductBreakdown{}
+	for rows.Next() {
+		var pb ProductBreakdown
+		if err := rows.Scan(&pb.Code, &pb.Name, &pb.Quantity, &pb.Revenue, &pb.Cost, &pb.AvgSatisfaction); err != nil {
+			return nil, err
+		}
+		pb.Profit = pb.Revenue - pb.Cost
+		products = append(products, pb)
+	}
+	return products, rows.Err()
+}
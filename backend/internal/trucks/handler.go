@@ -0,0 +1,314 @@
+package trucks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ledger"
+	"github.com/alonsoalpizar/calleviva/backend/internal/upgrades"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pgLockNotAvailable is returned by FOR UPDATE NOWAIT when a running day
+// holds the session row
+const pgLockNotAvailable = "55P03"
+
+type Handler struct {
+	db *pgxpool.Pool
+}
+
+func NewHandler(db *pgxpool.Pool) *Handler {
+	return &Handler{db: db}
+}
+
+// SetupRoutes mounts truck routes (requires auth)
+func (h *Handler) SetupRoutes(r chi.Router) {
+	r.Route("/trucks", func(r chi.Router) {
+		r.Get("/", h.GetTruck)
+		r.Patch("/", h.RenameTruck)
+		r.Post("/upgrade", h.UpgradeTruck)
+	})
+}
+
+// GET /api/v1/games/{gameID}/trucks
+// The game's truck, the vehicle ladder and what the next step needs
+func (h *Handler) GetTruck(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	var gameDay, reputation int
+	var money int64
+	err = h.db.QueryRow(ctx, `
+		SELECT game_day, reputation, money
+		FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+	`, gameID, playerID).Scan(&gameDay, &reputation, &money)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	truck, err := loadTruck(ctx, h.db, gameID, false)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "La partida no tiene vehículo"})
+		return
+	}
+
+	path, err := loadPath(ctx, h.db)
+	if err != nil {
+		log.Printf("Error loading truck types: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar los vehículos"})
+		return
+	}
+	truck.TypeName = typeName(path, truck.Type)
+
+	resp := TruckResponse{Truck: truck, Path: path, Money: money}
+	if resp.Path == nil {
+		resp.Path = []Type{}
+	}
+	if next, ok := Next(path, truck.Type); ok {
+		available, reason := Availability(next, gameDay, reputation, money)
+		resp.Next = &NextType{Type: next, Available: available, Reason: reason}
+	}
+
+	render.JSON(w, r, resp)
+}
+
+// PATCH /api/v1/games/{gameID}/trucks
+// Renames the truck
+func (h *Handler) RenameTruck(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	var req RenameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+	name, err := CheckName(req.Name)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+		return
+	}
+
+	result, err := h.db.Exec(ctx, `
+		UPDATE trucks SET name = $3, updated_at = NOW()
+		WHERE id = (
+			SELECT t.id FROM trucks t
+			JOIN game_sessions g ON g.id = t.session_id
+			WHERE t.session_id = $1 AND g.player_id = $2 AND g.deleted_at IS NULL
+			ORDER BY t.created_at
+			LIMIT 1
+		)
+	`, gameID, playerID, name)
+	if err != nil {
+		log.Printf("Error renaming truck of %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cambiar el nombre"})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	truck, err := loadTruck(ctx, h.db, gameID, false)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar el vehículo"})
+		return
+	}
+	if path, err := loadPath(ctx, h.db); err == nil {
+		truck.TypeName = typeName(path, truck.Type)
+	}
+
+	render.JSON(w, r, truck)
+}
+
+// POST /api/v1/games/{gameID}/trucks/upgrade
+// Moves the truck one step up the ladder and rebuilds its stats
+func (h *Handler) UpgradeTruck(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error de transacción"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// NOWAIT: the truck can't change while its day is being simulated
+	var gameDay, reputation int
+	var money int64
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT game_day, reputation, money, COALESCE(status, 'active')
+		FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+		FOR UPDATE NOWAIT
+	`, gameID, playerID).Scan(&gameDay, &reputation, &money, &status)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgLockNotAvailable {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, map[string]string{"error": "El día está en curso, comprá cuando termine"})
+			return
+		}
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	if status != "active" {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "La partida no está activa"})
+		return
+	}
+
+	truck, err := loadTruck(ctx, tx, gameID, true)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "La partida no tiene vehículo"})
+		return
+	}
+
+	path, err := loadPath(ctx, tx)
+	if err != nil {
+		log.Printf("Error loading truck types: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar los vehículos"})
+		return
+	}
+
+	next, ok := Next(path, truck.Type)
+	if !ok {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Ya tenés el vehículo más grande"})
+		return
+	}
+	if ok, reason := Availability(next, gameDay, reputation, money); !ok {
+		code := http.StatusForbidden
+		if money < next.Cost {
+			code = http.StatusBadRequest
+		}
+		render.Status(r, code)
+		render.JSON(w, r, map[string]string{"error": reason})
+		return
+	}
+
+	newMoney, err := ledger.Post(ctx, tx, gameID, ledger.Entry{
+		GameDay:     gameDay,
+		Amount:      -next.Cost,
+		Account:     ledger.AccountUpgrades,
+		Reason:      ledger.ReasonUpgrade,
+		Reference:   "truck_type:" + next.Code,
+		Description: fmt.Sprintf("%s → %s", typeName(path, truck.Type), next.Name),
+	})
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al actualizar dinero"})
+		return
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE trucks SET truck_type = $2, level = $3, updated_at = NOW()
+		WHERE id = $1
+	`, truck.ID, next.Code, next.Rank)
+	if err != nil {
+		log.Printf("Error upgrading truck of %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cambiar el vehículo"})
+		return
+	}
+
+	// New base stats, with the upgrades already bought on top
+	stats, err := upgrades.RecalculateTruck(ctx, tx, gameID)
+	if err != nil {
+		log.Printf("Error recalculating truck of %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cambiar el vehículo"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al confirmar compra"})
+		return
+	}
+
+	truck.Type = next.Code
+	truck.TypeName = next.Name
+	truck.Level = next.Rank
+	truck.Stats = stats
+
+	render.JSON(w, r, UpgradeResponse{
+		Success:  true,
+		Message:  fmt.Sprintf("¡Ahora tenés un %s!", next.Name),
+		Truck:    truck,
+		CostPaid: next.Cost,
+		NewMoney: newMoney,
+	})
+}
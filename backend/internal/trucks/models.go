@@ -0,0 +1,64 @@
+package trucks
+
+import "github.com/alonsoalpizar/calleviva/backend/internal/upgrades"
+
+// TypeConfig is the config of a truck_types parameter
+type TypeConfig struct {
+	Capacity       int     `json:"capacity"`
+	Speed          float64 `json:"speed"`
+	QueueTolerance float64 `json:"queue_tolerance"`
+	CheckoutErrors float64 `json:"checkout_errors"`
+	Cost           int64   `json:"cost"`
+	MaxEquipment   int     `json:"max_equipment"`
+	AppealBase     int     `json:"appeal_base,omitempty"`
+	MinDay         int     `json:"min_day,omitempty"`
+	MinReputation  int     `json:"min_reputation,omitempty"`
+}
+
+// Type is a step of the vehicle ladder
+type Type struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Icon string `json:"icon,omitempty"`
+	Rank int    `json:"rank"` // position in the ladder, from truck_types sort_order
+	TypeConfig
+}
+
+// Truck is the game's vehicle with its current stats (type plus upgrades)
+type Truck struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	Type     string         `json:"truck_type"`
+	TypeName string         `json:"type_name"`
+	Level    int            `json:"level"`
+	Stats    upgrades.Stats `json:"stats"`
+}
+
+// NextType is the type the truck can move up to
+type NextType struct {
+	Type
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"` // why it can't be bought now
+}
+
+// TruckResponse is the response for GET /trucks
+type TruckResponse struct {
+	Truck Truck     `json:"truck"`
+	Next  *NextType `json:"next,omitempty"` // nil at the top of the ladder
+	Path  []Type    `json:"path"`
+	Money int64     `json:"money"`
+}
+
+// RenameRequest is the body for PATCH /trucks
+type RenameRequest struct {
+	Name string `json:"name"`
+}
+
+// UpgradeResponse is the response for POST /trucks/upgrade
+type UpgradeResponse struct {
+	Success  bool   `json:"success"`
+	Message  string `json:"message"`
+	Truck    Truck  `json:"truck"`
+	CostPaid int64  `json:"cost_paid"`
+	NewMoney int64  `json:"new_money"`
+}
@@ -0,0 +1,142 @@
+package trucks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/upgrades"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxNameLength matches trucks.name
+const maxNameLength = 100
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// ParseType builds a ladder step from a truck_types row. Missing stats take
+// the basic cart's.
+func ParseType(code, name, icon string, rank int, config []byte) (Type, error) {
+	t := Type{Code: code, Name: name, Icon: icon, Rank: rank, TypeConfig: TypeConfig{
+		Capacity:       upgrades.DefaultStats.Capacity,
+		Speed:          upgrades.DefaultStats.Speed,
+		QueueTolerance: upgrades.DefaultStats.QueueTolerance,
+		CheckoutErrors: upgrades.DefaultStats.CheckoutErrors,
+		MaxEquipment:   3,
+	}}
+	err := json.Unmarshal(config, &t.TypeConfig)
+	return t, err
+}
+
+// Next returns the step after current in the ladder, or false at the top
+func Next(path []Type, current string) (Type, bool) {
+	rank := -1
+	for _, t := range path {
+		if t.Code == current {
+			rank = t.Rank
+		}
+	}
+	if rank < 0 {
+		return Type{}, false
+	}
+	for _, t := range path {
+		if t.Rank > rank {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// Availability tells whether the truck can move up to next now
+func Availability(next Type, gameDay, reputation int, money int64) (bool, string) {
+	if gameDay < next.MinDay {
+		return false, fmt.Sprintf("Disponible desde el día %d", next.MinDay)
+	}
+	if reputation < next.MinReputation {
+		return false, fmt.Sprintf("Necesitás %d de reputación", next.MinReputation)
+	}
+	if money < next.Cost {
+		return false, "No tenés suficiente dinero"
+	}
+	return true, ""
+}
+
+// CheckName trims a truck name and checks it fits
+func CheckName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxNameLength {
+		return "", errors.New("El nombre tiene que tener entre 1 y 100 caracteres")
+	}
+	return name, nil
+}
+
+// loadPath reads the active truck types in ladder order
+func loadPath(ctx context.Context, db querier) ([]Type, error) {
+	rows, err := db.Query(ctx, `
+		SELECT code, name, COALESCE(icon, ''), sort_order, COALESCE(config, '{}')
+		FROM parameters
+		WHERE category = 'truck_types' AND is_active = true
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var path []Type
+	for rows.Next() {
+		var code, name, icon string
+		var rank int
+		var config []byte
+		if err := rows.Scan(&code, &name, &icon, &rank, &config); err != nil {
+			return nil, err
+		}
+		t, err := ParseType(code, name, icon, rank, config)
+		if err != nil {
+			log.Printf("Skipping truck type %s: invalid config: %v", code, err)
+			continue
+		}
+		path = append(path, t)
+	}
+	sort.SliceStable(path, func(i, j int) bool { return path[i].Rank < path[j].Rank })
+	return path, rows.Err()
+}
+
+// loadTruck reads the game's truck. The simulation drives the first one.
+func loadTruck(ctx context.Context, db querier, gameID uuid.UUID, lock bool) (Truck, error) {
+	var truck Truck
+	query := `
+		SELECT id, name, COALESCE(truck_type, 'cart'), COALESCE(level, 1),
+		       COALESCE(capacity, 20), COALESCE(speed_multiplier, 1.0)::float8,
+		       COALESCE(queue_tolerance, 1.0)::float8, COALESCE(checkout_error_rate, 0.05)::float8
+		FROM trucks
+		WHERE session_id = $1
+		ORDER BY created_at
+		LIMIT 1`
+	if lock {
+		query += " FOR UPDATE"
+	}
+	err := db.QueryRow(ctx, query, gameID).Scan(
+		&truck.ID, &truck.Name, &truck.Type, &truck.Level,
+		&truck.Stats.Capacity, &truck.Stats.Speed, &truck.Stats.QueueTolerance, &truck.Stats.CheckoutErrors,
+	)
+	return truck, err
+}
+
+// typeName is the display name of a type, or its code if it's not in the path
+func typeName(path []Type, code string) string {
+	for _, t := range path {
+		if t.Code == code {
+			return t.Name
+		}
+	}
+	return code
+}
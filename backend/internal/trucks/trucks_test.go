@@ -0,0 +1,91 @@
+package trucks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/upgrades"
+)
+
+func ladder(t *testing.T) []Type {
+	t.Helper()
+	rows := []struct {
+		code, config string
+		rank         int
+	}{
+		{"cart", `{"capacity": 20, "speed": 1.0, "cost": 0, "max_equipment": 3}`, 1},
+		{"stand", `{"capacity": 40, "speed": 1.15, "cost": 25000, "max_equipment": 5, "min_day": 3, "min_reputation": 20}`, 2},
+		{"truck", `{"capacity": 80, "speed": 1.3, "cost": 100000, "max_equipment": 8, "min_day": 7, "min_reputation": 40}`, 3},
+	}
+	var path []Type
+	for _, r := range rows {
+		typ, err := ParseType(r.code, r.code, "", r.rank, []byte(r.config))
+		if err != nil {
+			t.Fatalf("ParseType(%s): %v", r.code, err)
+		}
+		path = append(path, typ)
+	}
+	return path
+}
+
+func TestNext(t *testing.T) {
+	path := ladder(t)
+
+	if next, ok := Next(path, "cart"); !ok || next.Code != "stand" {
+		t.Errorf("after cart = %s, %v; want stand", next.Code, ok)
+	}
+	if next, ok := Next(path, "stand"); !ok || next.Code != "truck" || next.Capacity != 80 {
+		t.Errorf("after stand = %+v, %v; want truck with 80 servings", next, ok)
+	}
+	if _, ok := Next(path, "truck"); ok {
+		t.Error("truck is the top of this ladder")
+	}
+	if _, ok := Next(path, "spaceship"); ok {
+		t.Error("unknown type has no next step")
+	}
+}
+
+func TestParseTypeDefaults(t *testing.T) {
+	typ, err := ParseType("cart", "Carrito", "🛒", 1, []byte(`{"capacity": 20}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ.QueueTolerance != upgrades.DefaultStats.QueueTolerance || typ.CheckoutErrors != upgrades.DefaultStats.CheckoutErrors {
+		t.Errorf("missing stats should take the basic cart's, got %+v", typ.TypeConfig)
+	}
+}
+
+func TestAvailability(t *testing.T) {
+	stand := ladder(t)[1]
+
+	cases := []struct {
+		name       string
+		day, rep   int
+		money      int64
+		wantOK     bool
+		wantReason string
+	}{
+		{"too early", 2, 50, 50000, false, "Disponible desde el día 3"},
+		{"low reputation", 5, 10, 50000, false, "Necesitás 20 de reputación"},
+		{"no money", 5, 50, 1000, false, "No tenés suficiente dinero"},
+		{"ok", 5, 50, 25000, true, ""},
+	}
+	for _, c := range cases {
+		ok, reason := Availability(stand, c.day, c.rep, c.money)
+		if ok != c.wantOK || reason != c.wantReason {
+			t.Errorf("%s: got %v %q, want %v %q", c.name, ok, reason, c.wantOK, c.wantReason)
+		}
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	if name, err := CheckName("  La Chinamita  "); err != nil || name != "La Chinamita" {
+		t.Errorf("CheckName trimmed = %q, %v", name, err)
+	}
+	if _, err := CheckName("   "); err == nil {
+		t.Error("blank name should fail")
+	}
+	if _, err := CheckName(strings.Repeat("ñ", 101)); err == nil {
+		t.Error("101 characters should fail")
+	}
+}
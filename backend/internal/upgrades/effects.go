@@ -0,0 +1,62 @@
+package upgrades
+
+import "math"
+
+// Effects is what one level of an upgrade adds to the truck. Values are
+// fractions: Capacity 0.5 = +50% servings, CheckoutErrors 0.3 = 30% fewer errors.
+type Effects struct {
+	Capacity       float64 `json:"capacity,omitempty"`
+	Speed          float64 `json:"speed,omitempty"`
+	QueueTolerance float64 `json:"queue_tolerance,omitempty"`
+	CheckoutErrors float64 `json:"checkout_errors,omitempty"`
+}
+
+// Add accumulates level times the effects of e
+func (e Effects) Add(other Effects, level int) Effects {
+	l := float64(level)
+	return Effects{
+		Capacity:       e.Capacity + other.Capacity*l,
+		Speed:          e.Speed + other.Speed*l,
+		QueueTolerance: e.QueueTolerance + other.QueueTolerance*l,
+		CheckoutErrors: e.CheckoutErrors + other.CheckoutErrors*l,
+	}
+}
+
+// IsZero reports whether the upgrade does nothing the simulation uses
+func (e Effects) IsZero() bool {
+	return e == Effects{}
+}
+
+// Stats are the truck numbers the simulation reads
+type Stats struct {
+	Capacity       int     `json:"capacity"`        // servings per day
+	Speed          float64 `json:"speed"`           // service speed multiplier
+	QueueTolerance float64 `json:"queue_tolerance"` // how long customers put up with the line
+	CheckoutErrors float64 `json:"checkout_errors"` // share of sales charged wrong
+}
+
+// DefaultStats is a basic cart without upgrades
+var DefaultStats = Stats{Capacity: 20, Speed: 1.0, QueueTolerance: 1.0, CheckoutErrors: 0.05}
+
+// Apply returns the truck's base stats improved by the accumulated effects.
+// Checkout error reductions stack but never go below zero errors.
+func Apply(base Stats, e Effects) Stats {
+	return Stats{
+		Capacity:       int(math.Round(float64(base.Capacity) * (1 + e.Capacity))),
+		Speed:          round2(base.Speed * (1 + e.Speed)),
+		QueueTolerance: round2(base.QueueTolerance * (1 + e.QueueTolerance)),
+		CheckoutErrors: round3(base.CheckoutErrors * math.Max(0, 1-e.CheckoutErrors)),
+	}
+}
+
+// LevelCost is the price of buying the given level: every level costs
+// `growth` times the previous one
+func LevelCost(base int64, growth float64, level int) int64 {
+	if growth <= 0 {
+		growth = 1
+	}
+	return int64(math.Round(float64(base) * math.Pow(growth, float64(level-1))))
+}
+
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
+func round3(v float64) float64 { return math.Round(v*1000) / 1000 }
@@ -0,0 +1,343 @@
+package upgrades
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/auth"
+	"github.com/alonsoalpizar/calleviva/backend/internal/ledger"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pgLockNotAvailable is returned by FOR UPDATE NOWAIT when a running day
+// holds the session row
+const pgLockNotAvailable = "55P03"
+
+type Handler struct {
+	db *pgxpool.Pool
+}
+
+func NewHandler(db *pgxpool.Pool) *Handler {
+	return &Handler{db: db}
+}
+
+// SetupRoutes mounts upgrade shop routes (requires auth)
+func (h *Handler) SetupRoutes(r chi.Router) {
+	r.Get("/upgrades", h.GetUpgrades)
+	r.Post("/upgrades/buy", h.BuyUpgrade)
+}
+
+// progress is what the requirements of an upgrade are checked against
+type progress struct {
+	GameDay        int
+	Reputation     int
+	Money          int64
+	Vehicle        vehicle
+	EquipmentCount int // equipment items already installed
+}
+
+// availability tells whether the next level of def can be bought
+func availability(def Definition, level int, p progress, vehicles map[string]vehicle) (int64, bool, string) {
+	if level >= def.MaxLevel {
+		return 0, false, "Nivel máximo"
+	}
+	cost := LevelCost(def.Cost, def.CostGrowth, level+1)
+
+	if p.GameDay < def.MinDay {
+		return cost, false, fmt.Sprintf("Disponible desde el día %d", def.MinDay)
+	}
+	if p.Reputation < def.MinReputation {
+		return cost, false, fmt.Sprintf("Necesitás %d de reputación", def.MinReputation)
+	}
+	if def.RequiresVehicle != "" {
+		if required, ok := vehicles[def.RequiresVehicle]; ok && p.Vehicle.Rank < required.Rank {
+			return cost, false, fmt.Sprintf("Necesitás un %s o algo más grande", required.Name)
+		}
+	}
+	if def.Category == CategoryEquipment && level == 0 && p.EquipmentCount >= p.Vehicle.MaxEquipment {
+		return cost, false, "No te cabe más equipo en el vehículo"
+	}
+	if p.Money < cost {
+		return cost, false, "No tenés suficiente dinero"
+	}
+	return cost, true, ""
+}
+
+// equipmentCount counts the equipment items bought
+func equipmentCount(levels map[string]int) int {
+	n := 0
+	for id, level := range levels {
+		if IsEquipment(id) && level > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// GET /api/v1/games/{gameID}/upgrades
+// Shop catalog with the game's levels, requirements and the truck's stats
+func (h *Handler) GetUpgrades(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	var p progress
+	err = h.db.QueryRow(ctx, `
+		SELECT game_day, reputation, money
+		FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+	`, gameID, playerID).Scan(&p.GameDay, &p.Reputation, &p.Money)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	truckType := "cart"
+	truck := DefaultStats
+	h.db.QueryRow(ctx, `
+		SELECT COALESCE(truck_type, 'cart'), COALESCE(capacity, 20), COALESCE(speed_multiplier, 1.0)::float8,
+		       COALESCE(queue_tolerance, 1.0)::float8, COALESCE(checkout_error_rate, 0.05)::float8
+		FROM trucks
+		WHERE session_id = $1
+		ORDER BY created_at
+		LIMIT 1
+	`, gameID).Scan(&truckType, &truck.Capacity, &truck.Speed, &truck.QueueTolerance, &truck.CheckoutErrors)
+
+	catalog, err := loadCatalog(ctx, h.db)
+	if err != nil {
+		log.Printf("Error loading upgrades catalog: %v", err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar las mejoras"})
+		return
+	}
+	levels, err := loadLevels(ctx, h.db, gameID)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar las mejoras"})
+		return
+	}
+
+	vehicles := loadVehicles(ctx, h.db)
+	p.Vehicle = vehicles[truckType]
+	p.EquipmentCount = equipmentCount(levels)
+
+	list := make([]Upgrade, 0, len(catalog))
+	for _, def := range catalog {
+		level := levels[def.ID]
+		cost, ok, reason := availability(def, level, p, vehicles)
+		list = append(list, Upgrade{
+			ID:              def.ID,
+			Category:        def.Category,
+			Name:            def.Name,
+			Icon:            def.Icon,
+			Description:     def.Description,
+			Level:           level,
+			MaxLevel:        def.MaxLevel,
+			NextCost:        cost,
+			Effects:         def.Effects,
+			MinDay:          def.MinDay,
+			MinReputation:   def.MinReputation,
+			RequiresVehicle: def.RequiresVehicle,
+			Available:       ok,
+			Reason:          reason,
+		})
+	}
+
+	render.JSON(w, r, UpgradesResponse{
+		Upgrades:  list,
+		Truck:     truck,
+		TruckType: truckType,
+		Money:     p.Money,
+	})
+}
+
+// POST /api/v1/games/{gameID}/upgrades/buy
+// Buys the next level of an upgrade and applies it to the truck
+func (h *Handler) BuyUpgrade(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameID"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid game ID"})
+		return
+	}
+
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	playerID, _ := uuid.Parse(claims.PlayerID)
+
+	var req BuyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UpgradeID == "" {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "upgrade_id requerido"})
+		return
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error de transacción"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// NOWAIT: the truck can't change while its day is being simulated
+	var p progress
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT game_day, reputation, money, COALESCE(status, 'active')
+		FROM game_sessions
+		WHERE id = $1 AND player_id = $2 AND deleted_at IS NULL
+		FOR UPDATE NOWAIT
+	`, gameID, playerID).Scan(&p.GameDay, &p.Reputation, &p.Money, &status)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgLockNotAvailable {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, map[string]string{"error": "El día está en curso, comprá cuando termine"})
+			return
+		}
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Partida no encontrada"})
+		return
+	}
+
+	if status != "active" {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "La partida no está activa"})
+		return
+	}
+
+	catalog, err := loadCatalog(ctx, tx)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar las mejoras"})
+		return
+	}
+	var def Definition
+	found := false
+	for _, d := range catalog {
+		if d.ID == req.UpgradeID {
+			def, found = d, true
+			break
+		}
+	}
+	if !found {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Mejora no válida"})
+		return
+	}
+
+	levels, err := loadLevels(ctx, tx, gameID)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al cargar las mejoras"})
+		return
+	}
+
+	truckType := "cart"
+	tx.QueryRow(ctx, `
+		SELECT COALESCE(truck_type, 'cart') FROM trucks
+		WHERE session_id = $1
+		ORDER BY created_at
+		LIMIT 1
+	`, gameID).Scan(&truckType)
+
+	vehicles := loadVehicles(ctx, tx)
+	p.Vehicle = vehicles[truckType]
+	p.EquipmentCount = equipmentCount(levels)
+
+	level := levels[def.ID]
+	cost, ok, reason := availability(def, level, p, vehicles)
+	if !ok {
+		code := http.StatusForbidden
+		if p.Money < cost && level < def.MaxLevel {
+			code = http.StatusBadRequest
+		}
+		render.Status(r, code)
+		render.JSON(w, r, map[string]string{"error": reason})
+		return
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO upgrades (session_id, upgrade_type, upgrade_level, purchased_day, cost_paid)
+		VALUES ($1, $2, 1, $3, $4)
+		ON CONFLICT (session_id, upgrade_type) DO UPDATE SET
+			upgrade_level = upgrades.upgrade_level + 1,
+			purchased_day = EXCLUDED.purchased_day,
+			cost_paid = upgrades.cost_paid + EXCLUDED.cost_paid
+	`, gameID, def.ID, p.GameDay, cost)
+	if err != nil {
+		log.Printf("Error buying upgrade %s for %s: %v", def.ID, gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al comprar la mejora"})
+		return
+	}
+
+	newMoney, err := ledger.Post(ctx, tx, gameID, ledger.Entry{
+		GameDay:     p.GameDay,
+		Amount:      -cost,
+		Account:     ledger.AccountUpgrades,
+		Reason:      ledger.ReasonUpgrade,
+		Reference:   def.ID,
+		Description: fmt.Sprintf("%s nivel %d", def.Name, level+1),
+	})
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al actualizar dinero"})
+		return
+	}
+
+	stats, err := RecalculateTruck(ctx, tx, gameID)
+	if err != nil {
+		log.Printf("Error applying upgrades to truck of %s: %v", gameID, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al aplicar la mejora"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error al confirmar compra"})
+		return
+	}
+
+	render.JSON(w, r, BuyResponse{
+		Success:  true,
+		Upgrade:  def.ID,
+		Level:    level + 1,
+		CostPaid: cost,
+		NewMoney: newMoney,
+		Truck:    stats,
+	})
+}
@@ -0,0 +1,107 @@
+package upgrades
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Parameter categories sold in the shop. Equipment IDs are prefixed so they
+// don't clash with upgrades of the same code (generator, pos_system).
+const (
+	CategoryUpgrades  = "upgrades"
+	CategoryEquipment = "equipment"
+	equipmentPrefix   = "equipment:"
+)
+
+// Config is the shop part of an upgrades/equipment parameter config
+type Config struct {
+	Cost            int64   `json:"cost"`
+	CostGrowth      float64 `json:"cost_growth"` // price multiplier per level
+	MaxLevel        int     `json:"max_level"`
+	MinDay          int     `json:"min_day"`
+	MinReputation   int     `json:"min_reputation"`
+	RequiresVehicle string  `json:"requires_vehicle"`
+	Description     string  `json:"description"`
+	Included        bool    `json:"included"` // comes with every truck, not sold
+	Effects         Effects `json:"effects"`
+}
+
+// Definition is an item of the shop catalog
+type Definition struct {
+	ID       string `json:"id"`
+	Category string `json:"category"`
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Icon     string `json:"icon"`
+	Config
+}
+
+// ParseDefinition builds a shop item from a parameters row. Items without
+// effects, or included for free, are not sold.
+func ParseDefinition(category, code, name, icon string, config []byte) (Definition, bool) {
+	def := Definition{ID: UpgradeID(category, code), Category: category, Code: code, Name: name, Icon: icon}
+	if err := json.Unmarshal(config, &def.Config); err != nil {
+		return def, false
+	}
+	if def.Included || def.Effects.IsZero() || def.Cost <= 0 {
+		return def, false
+	}
+	if def.MaxLevel < 1 {
+		def.MaxLevel = 1
+	}
+	return def, true
+}
+
+// UpgradeID is the upgrade_type stored in the upgrades table
+func UpgradeID(category, code string) string {
+	if category == CategoryEquipment {
+		return equipmentPrefix + code
+	}
+	return code
+}
+
+// IsEquipment reports whether an upgrade_type is an equipment item
+func IsEquipment(id string) bool {
+	return strings.HasPrefix(id, equipmentPrefix)
+}
+
+// Upgrade is a shop item as seen by a game
+type Upgrade struct {
+	ID              string  `json:"id"`
+	Category        string  `json:"category"`
+	Name            string  `json:"name"`
+	Icon            string  `json:"icon"`
+	Description     string  `json:"description"`
+	Level           int     `json:"level"`
+	MaxLevel        int     `json:"max_level"`
+	NextCost        int64   `json:"next_cost,omitempty"`
+	Effects         Effects `json:"effects"` // per level
+	MinDay          int     `json:"min_day,omitempty"`
+	MinReputation   int     `json:"min_reputation,omitempty"`
+	RequiresVehicle string  `json:"requires_vehicle,omitempty"`
+	Available       bool    `json:"available"`
+	Reason          string  `json:"reason,omitempty"` // why it can't be bought now
+}
+
+// UpgradesResponse is the response for GET /upgrades
+type UpgradesResponse struct {
+	Upgrades  []Upgrade `json:"upgrades"`
+	Truck     Stats     `json:"truck"`
+	TruckType string    `json:"truck_type"`
+	Money     int64     `json:"money"`
+}
+
+// BuyRequest is the body for POST /upgrades/buy
+type BuyRequest struct {
+	UpgradeID string `json:"upgrade_id"`
+}
+
+// BuyResponse is the response for POST /upgrades/buy
+type BuyResponse struct {
+	Success  bool   `json:"success"`
+	Upgrade  string `json:"upgrade_id"`
+	Level    int    `json:"level"`
+	CostPaid int64  `json:"cost_paid"`
+	NewMoney int64  `json:"new_money"`
+	Truck    Stats  `json:"truck"`
+}
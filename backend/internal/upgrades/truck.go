@@ -0,0 +1,167 @@
+package upgrades
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// loadCatalog reads every upgrade and equipment item that can be bought
+func loadCatalog(ctx context.Context, db querier) ([]Definition, error) {
+	rows, err := db.Query(ctx, `
+		SELECT category, code, name, COALESCE(icon, ''), config
+		FROM parameters
+		WHERE category IN ($1, $2) AND is_active = true
+		ORDER BY category DESC, sort_order, code
+	`, CategoryUpgrades, CategoryEquipment)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var defs []Definition
+	for rows.Next() {
+		var category, code, name, icon string
+		var config []byte
+		if err := rows.Scan(&category, &code, &name, &icon, &config); err != nil {
+			return nil, err
+		}
+		if def, ok := ParseDefinition(category, code, name, icon, config); ok {
+			defs = append(defs, def)
+		}
+	}
+	return defs, rows.Err()
+}
+
+// loadLevels returns the level of every upgrade bought in a game
+func loadLevels(ctx context.Context, db querier, gameID uuid.UUID) (map[string]int, error) {
+	rows, err := db.Query(ctx, `
+		SELECT upgrade_type, COALESCE(upgrade_level, 1) FROM upgrades WHERE session_id = $1
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := map[string]int{}
+	for rows.Next() {
+		var id string
+		var level int
+		if err := rows.Scan(&id, &level); err != nil {
+			return nil, err
+		}
+		levels[id] = level
+	}
+	return levels, rows.Err()
+}
+
+// vehicle is a truck type as far as the shop cares
+type vehicle struct {
+	Name         string
+	Rank         int // cart < stand < truck < restaurant
+	MaxEquipment int
+}
+
+// loadVehicles reads the truck types to check requires_vehicle and how much
+// equipment fits in each one
+func loadVehicles(ctx context.Context, db querier) map[string]vehicle {
+	vehicles := map[string]vehicle{}
+	rows, err := db.Query(ctx, `
+		SELECT code, name, sort_order, COALESCE((config->>'max_equipment')::int, 3)
+		FROM parameters
+		WHERE category = 'truck_types' AND is_active = true
+	`)
+	if err != nil {
+		return vehicles
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var code string
+		var v vehicle
+		if rows.Scan(&code, &v.Name, &v.Rank, &v.MaxEquipment) == nil {
+			vehicles[code] = v
+		}
+	}
+	return vehicles
+}
+
+// BaseStats reads a truck type's stats before upgrades from truck_types
+func BaseStats(ctx context.Context, db querier, truckType string) Stats {
+	stats := DefaultStats
+	err := db.QueryRow(ctx, `
+		SELECT COALESCE((config->>'capacity')::int, $2),
+		       COALESCE((config->>'speed')::float8, $3),
+		       COALESCE((config->>'queue_tolerance')::float8, $4),
+		       COALESCE((config->>'checkout_errors')::float8, $5)
+		FROM parameters
+		WHERE category = 'truck_types' AND code = $1 AND is_active = true
+	`, truckType, stats.Capacity, stats.Speed, stats.QueueTolerance, stats.CheckoutErrors).Scan(
+		&stats.Capacity, &stats.Speed, &stats.QueueTolerance, &stats.CheckoutErrors,
+	)
+	if err != nil && err != pgx.ErrNoRows {
+		log.Printf("Error loading truck type %s: %v", truckType, err)
+	}
+	return stats
+}
+
+// ownedUpgrade is an entry of trucks.upgrades
+type ownedUpgrade struct {
+	ID    string `json:"id"`
+	Level int    `json:"level"`
+}
+
+// RecalculateTruck rebuilds the stats of the game's truck from its type and
+// every upgrade bought, and stores them on the trucks row
+func RecalculateTruck(ctx context.Context, tx pgx.Tx, gameID uuid.UUID) (Stats, error) {
+	var truckID uuid.UUID
+	var truckType string
+	err := tx.QueryRow(ctx, `
+		SELECT id, COALESCE(truck_type, 'cart') FROM trucks
+		WHERE session_id = $1
+		ORDER BY created_at
+		LIMIT 1
+	`, gameID).Scan(&truckID, &truckType)
+	if err != nil {
+		return DefaultStats, err
+	}
+
+	catalog, err := loadCatalog(ctx, tx)
+	if err != nil {
+		return DefaultStats, err
+	}
+	levels, err := loadLevels(ctx, tx, gameID)
+	if err != nil {
+		return DefaultStats, err
+	}
+
+	var effects Effects
+	owned := []ownedUpgrade{}
+	for _, def := range catalog {
+		level := min(levels[def.ID], def.MaxLevel)
+		if level <= 0 {
+			continue
+		}
+		effects = effects.Add(def.Effects, level)
+		owned = append(owned, ownedUpgrade{ID: def.ID, Level: level})
+	}
+
+	stats := Apply(BaseStats(ctx, tx, truckType), effects)
+	ownedJSON, _ := json.Marshal(owned)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE trucks
+		SET capacity = $2, speed_multiplier = $3, queue_tolerance = $4,
+		    checkout_error_rate = $5, upgrades = $6, updated_at = NOW()
+		WHERE id = $1
+	`, truckID, stats.Capacity, stats.Speed, stats.QueueTolerance, stats.CheckoutErrors, ownedJSON)
+	return stats, err
+}
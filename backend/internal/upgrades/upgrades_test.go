@@ -0,0 +1,77 @@
+package upgrades
+
+import "testing"
+
+func TestApplyEffects(t *testing.T) {
+	var e Effects
+	e = e.Add(Effects{Capacity: 0.5}, 2)       // bigger fridge level 2
+	e = e.Add(Effects{Speed: 0.2}, 1)          // better grill
+	e = e.Add(Effects{CheckoutErrors: 0.6}, 1) // POS
+	e = e.Add(Effects{CheckoutErrors: 0.6}, 1) // stacked past 100%
+	e = e.Add(Effects{QueueTolerance: 0.1}, 1) // umbrella
+
+	got := Apply(DefaultStats, e)
+	want := Stats{Capacity: 40, Speed: 1.2, QueueTolerance: 1.1, CheckoutErrors: 0}
+	if got != want {
+		t.Errorf("Apply = %+v, want %+v", got, want)
+	}
+}
+
+func TestLevelCost(t *testing.T) {
+	if got := LevelCost(8000, 1.5, 1); got != 8000 {
+		t.Errorf("level 1 = %d, want 8000", got)
+	}
+	if got := LevelCost(8000, 1.5, 3); got != 18000 {
+		t.Errorf("level 3 = %d, want 18000", got)
+	}
+	if got := LevelCost(8000, 0, 2); got != 8000 {
+		t.Errorf("no growth = %d, want 8000", got)
+	}
+}
+
+func TestParseDefinition(t *testing.T) {
+	if _, ok := ParseDefinition(CategoryEquipment, "basic_stove", "Estufa", "", []byte(`{"cost": 0, "included": true}`)); ok {
+		t.Error("included equipment should not be sold")
+	}
+	if _, ok := ParseDefinition(CategoryUpgrades, "neon_sign", "Neón", "", []byte(`{"cost": 4000}`)); ok {
+		t.Error("upgrades without effects should not be sold")
+	}
+
+	def, ok := ParseDefinition(CategoryEquipment, "cooler", "Hielera", "", []byte(`{"cost": 5000, "effects": {"capacity": 0.25}}`))
+	if !ok || def.ID != "equipment:cooler" || def.MaxLevel != 1 {
+		t.Errorf("unexpected cooler definition: %+v (ok=%v)", def, ok)
+	}
+}
+
+func TestAvailability(t *testing.T) {
+	vehicles := map[string]vehicle{
+		"cart":  {Name: "Carrito", Rank: 1, MaxEquipment: 1},
+		"stand": {Name: "Puesto", Rank: 2, MaxEquipment: 5},
+	}
+	def := Definition{ID: "equipment:warehouse", Category: CategoryEquipment, Config: Config{
+		Cost: 25000, MaxLevel: 1, MinDay: 7, MinReputation: 30, RequiresVehicle: "stand",
+	}}
+	p := progress{GameDay: 10, Reputation: 40, Money: 30000, Vehicle: vehicles["cart"]}
+
+	tests := []struct {
+		name   string
+		modify func(p *progress)
+		level  int
+		want   string
+	}{
+		{"vehicle too small", func(p *progress) {}, 0, "Necesitás un Puesto o algo más grande"},
+		{"too early", func(p *progress) { p.GameDay = 3; p.Vehicle = vehicles["stand"] }, 0, "Disponible desde el día 7"},
+		{"low reputation", func(p *progress) { p.Reputation = 10; p.Vehicle = vehicles["stand"] }, 0, "Necesitás 30 de reputación"},
+		{"no money", func(p *progress) { p.Money = 100; p.Vehicle = vehicles["stand"] }, 0, "No tenés suficiente dinero"},
+		{"maxed out", func(p *progress) {}, 1, "Nivel máximo"},
+		{"ok", func(p *progress) { p.Vehicle = vehicles["stand"] }, 0, ""},
+	}
+	for _, tt := range tests {
+		pp := p
+		tt.modify(&pp)
+		_, ok, reason := availability(def, tt.level, pp, vehicles)
+		if reason != tt.want || ok != (tt.want == "") {
+			t.Errorf("%s: got (%v, %q), want %q", tt.name, ok, reason, tt.want)
+		}
+	}
+}
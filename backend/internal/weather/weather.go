@@ -0,0 +1,170 @@
+package weather
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+)
+
+// persistence is how much tomorrow's weather leans towards today's
+const persistence = 0.4
+
+// Type is a weather type from the weather parameters
+type Type struct {
+	Code              string             `json:"code"`
+	Name              string             `json:"name"`
+	Icon              string             `json:"icon,omitempty"`
+	Probability       float64            `json:"probability"`
+	TrafficModifier   float64            `json:"traffic_modifier"`
+	Mood              string             `json:"mood,omitempty"`
+	Message           string             `json:"message,omitempty"`
+	CategoryModifiers map[string]float64 `json:"category_modifiers,omitempty"`
+}
+
+// DefaultTypes follows the GDD 4.4 table, used when no parameters are configured
+var DefaultTypes = []Type{
+	{Code: "sunny", Name: "Soleado", Icon: "☀️", Probability: 0.50, TrafficModifier: 1.2, Mood: "happy",
+		CategoryModifiers: map[string]float64{"frozen": 1.3, "cold_drink": 1.2, "hot_drink": 0.8}},
+	{Code: "cloudy", Name: "Nublado", Icon: "☁️", Probability: 0.25, TrafficModifier: 1.0, Mood: "neutral"},
+	{Code: "rainy", Name: "Lluvioso", Icon: "🌧️", Probability: 0.20, TrafficModifier: 0.75, Mood: "sad",
+		CategoryModifiers: map[string]float64{"frozen": 0.7, "cold_drink": 0.8, "hot_drink": 1.3}},
+	{Code: "stormy", Name: "Tormenta", Icon: "⛈️", Probability: 0.05, TrafficModifier: 0.5, Mood: "scared",
+		CategoryModifiers: map[string]float64{"frozen": 0.5, "cold_drink": 0.7, "hot_drink": 1.2}},
+}
+
+// ParseType builds a Type from a weather parameter row. Older configs only
+// have customer_modifier, which is used as the traffic modifier.
+func ParseType(code, name, icon string, config []byte) (Type, error) {
+	var raw struct {
+		Type
+		CustomerModifier float64 `json:"customer_modifier"`
+	}
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &raw); err != nil {
+			return Type{}, err
+		}
+	}
+
+	t := raw.Type
+	t.Code, t.Name, t.Icon = code, name, icon
+	if t.TrafficModifier <= 0 {
+		t.TrafficModifier = raw.CustomerModifier
+	}
+	if t.TrafficModifier <= 0 {
+		t.TrafficModifier = 1.0
+	}
+	return t, nil
+}
+
+// Find returns the type with the given code
+func Find(types []Type, code string) (Type, bool) {
+	for _, t := range types {
+		if t.Code == code {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// CategoryModifier returns the demand multiplier for a product category
+func (t Type) CategoryModifier(category string) float64 {
+	if m, ok := t.CategoryModifiers[category]; ok && m > 0 {
+		return m
+	}
+	return 1.0
+}
+
+// Forecast maps weather codes to the probability of seeing them
+type Forecast map[string]float64
+
+// Base returns the normalized base probabilities of the weather types
+func Base(types []Type) Forecast {
+	f := Forecast{}
+	total := 0.0
+	for _, t := range types {
+		if t.Probability > 0 {
+			f[t.Code] = t.Probability
+			total += t.Probability
+		}
+	}
+	if total == 0 {
+		for _, t := range types {
+			f[t.Code] = 1
+			total++
+		}
+	}
+	for code := range f {
+		f[code] /= total
+	}
+	return f
+}
+
+// Tomorrow forecasts the next day: the base probabilities pulled towards
+// today's weather, since weather tends to last
+func Tomorrow(types []Type, today string) Forecast {
+	f := Base(types)
+	if _, ok := f[today]; !ok {
+		return f
+	}
+	for code := range f {
+		f[code] *= 1 - persistence
+	}
+	f[today] += persistence
+	return f
+}
+
+// Roll draws a weather code from a forecast
+func Roll(rng *rand.Rand, f Forecast) string {
+	codes := f.codes()
+	if len(codes) == 0 {
+		return ""
+	}
+
+	total := 0.0
+	for _, c := range codes {
+		total += f[c]
+	}
+	n := rng.Float64() * total
+	for _, c := range codes {
+		if n < f[c] {
+			return c
+		}
+		n -= f[c]
+	}
+	return codes[len(codes)-1]
+}
+
+// codes returns the forecast codes in a stable order so rolls are deterministic
+func (f Forecast) codes() []string {
+	codes := make([]string, 0, len(f))
+	for c := range f {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Chance is one entry of a forecast for display ("70% ☀️")
+type Chance struct {
+	Weather string `json:"weather"`
+	Icon    string `json:"icon,omitempty"`
+	Percent int    `json:"percent"`
+}
+
+// Chances lists the forecast from most to least likely, skipping entries under 1%
+func (f Forecast) Chances(types []Type) []Chance {
+	out := []Chance{}
+	for _, code := range f.codes() {
+		pct := int(f[code]*100 + 0.5)
+		if pct < 1 {
+			continue
+		}
+		c := Chance{Weather: code, Percent: pct}
+		if t, ok := Find(types, code); ok {
+			c.Icon = t.Icon
+		}
+		out = append(out, c)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Percent > out[j].Percent })
+	return out
+}
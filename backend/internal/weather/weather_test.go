@@ -0,0 +1,61 @@
+package weather
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTomorrowLeansTowardsToday(t *testing.T) {
+	f := Tomorrow(DefaultTypes, "rainy")
+
+	total := 0.0
+	for _, p := range f {
+		total += p
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("forecast should add up to 1, got %v", total)
+	}
+
+	// 0.20 × 0.6 + 0.4
+	if math.Abs(f["rainy"]-0.52) > 1e-9 {
+		t.Errorf("expected rainy 0.52, got %v", f["rainy"])
+	}
+	if f["rainy"] <= Base(DefaultTypes)["rainy"] {
+		t.Error("today's weather should be more likely tomorrow")
+	}
+}
+
+func TestRollFollowsProbabilities(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		counts[Roll(rng, Base(DefaultTypes))]++
+	}
+
+	if counts["sunny"] < 4700 || counts["sunny"] > 5300 {
+		t.Errorf("expected ~50%% sunny, got %d/10000", counts["sunny"])
+	}
+	if counts["stormy"] < 350 || counts["stormy"] > 650 {
+		t.Errorf("expected ~5%% stormy, got %d/10000", counts["stormy"])
+	}
+
+	a := Roll(rand.New(rand.NewSource(7)), Base(DefaultTypes))
+	b := Roll(rand.New(rand.NewSource(7)), Base(DefaultTypes))
+	if a != b {
+		t.Error("rolls with the same seed should match")
+	}
+}
+
+func TestParseTypeLegacyModifier(t *testing.T) {
+	typ, err := ParseType("rainy", "Lluvioso", "🌧️", []byte(`{"customer_modifier": 0.6, "mood": "sad"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ.TrafficModifier != 0.6 || typ.Mood != "sad" {
+		t.Errorf("unexpected type: %+v", typ)
+	}
+	if typ.CategoryModifier("frozen") != 1.0 {
+		t.Error("missing category modifiers should be neutral")
+	}
+}
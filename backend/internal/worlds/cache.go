@@ -0,0 +1,60 @@
+package worlds
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+)
+
+// entry is a rendered catalog and the parameters version it was built from
+type entry struct {
+	version string
+	body    []byte
+	etag    string
+}
+
+// cache keeps the rendered catalogs in memory. An entry is served while the
+// parameters it came from haven't changed, so admin edits show up right away.
+type cache struct {
+	mu      sync.RWMutex
+	entries map[string]entry
+}
+
+func newCache() *cache {
+	return &cache{entries: map[string]entry{}}
+}
+
+func (c *cache) get(key, version string) (entry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[key]
+	if !ok || e.version != version {
+		return entry{}, false
+	}
+	return e, true
+}
+
+func (c *cache) put(key string, e entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = e
+}
+
+// ETag is a strong validator for a response body
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// Matches reports whether an If-None-Match header lists the etag. Weak
+// validators (W/"...") match too, as GET allows.
+func Matches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package worlds
+
+import "testing"
+
+func TestCacheServesOnlyCurrentVersion(t *testing.T) {
+	c := newCache()
+	c.put("products:mexico", entry{version: "12-1", body: []byte(`{}`), etag: ETag([]byte(`{}`))})
+
+	if _, ok := c.get("products:mexico", "12-1"); !ok {
+		t.Error("same version should hit")
+	}
+	if _, ok := c.get("products:mexico", "13-2"); ok {
+		t.Error("changed parameters should miss")
+	}
+	if _, ok := c.get("products:usa", "12-1"); ok {
+		t.Error("other key should miss")
+	}
+}
+
+func TestETagChangesWithBody(t *testing.T) {
+	a := ETag([]byte(`{"total":1}`))
+	if a != ETag([]byte(`{"total":1}`)) {
+		t.Error("ETag should be stable for the same body")
+	}
+	if a == ETag([]byte(`{"total":2}`)) {
+		t.Error("ETag should change with the body")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	etag := `"abc123"`
+	cases := []struct {
+		header string
+		want   bool
+	}{
+		{``, false},
+		{`"abc123"`, true},
+		{`W/"abc123"`, true},
+		{`"old", "abc123"`, true},
+		{`"old"`, false},
+		{`*`, true},
+	}
+	for _, c := range cases {
+		if got := Matches(c.header, etag); got != c.want {
+			t.Errorf("Matches(%q) = %v, want %v", c.header, got, c.want)
+		}
+	}
+}
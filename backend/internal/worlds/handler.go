@@ -0,0 +1,306 @@
+package worlds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/alonsoalpizar/calleviva/backend/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxAge is how long clients may reuse a catalog before revalidating
+const maxAge = 5 * time.Minute
+
+var errWorldNotFound = errors.New("world not found")
+
+type Handler struct {
+	db    *pgxpool.Pool
+	cache *cache
+}
+
+func NewHandler(db *pgxpool.Pool) *Handler {
+	return &Handler{db: db, cache: newCache()}
+}
+
+// SetupRoutes mounts world catalog routes (public)
+func (h *Handler) SetupRoutes(r chi.Router) {
+	r.Route("/worlds", func(r chi.Router) {
+		r.Get("/", h.ListWorlds)
+		r.Get("/{worldType}/products", h.ListProducts)
+		r.Get("/{worldType}/locations", h.ListLocations)
+		r.Get("/{worldType}/events", h.ListEvents)
+	})
+}
+
+// GET /api/v1/worlds
+// Lists the countries with their currency, starting money and availability
+func (h *Handler) ListWorlds(w http.ResponseWriter, r *http.Request) {
+	patterns := []string{"countries", `products\_%`, `locations\_%`, `ingredients\_%`}
+	h.serve(w, r, "worlds", patterns, h.loadWorlds)
+}
+
+// GET /api/v1/worlds/{worldType}/products
+func (h *Handler) ListProducts(w http.ResponseWriter, r *http.Request) {
+	worldType := chi.URLParam(r, "worldType")
+	category := "products_" + models.WorldSuffix(worldType)
+	h.serve(w, r, "products:"+worldType, []string{"countries", category}, func(ctx context.Context) (any, error) {
+		if err := h.checkWorld(ctx, worldType); err != nil {
+			return nil, err
+		}
+		rows, err := loadCatalog(ctx, h.db, category)
+		if err != nil {
+			return nil, err
+		}
+		products := []Product{}
+		for _, row := range rows {
+			p := Product{Code: row.Code, Name: row.Name, Icon: row.Icon, Description: row.Description}
+			if err := json.Unmarshal(row.Config, &p.ProductConfig); err != nil {
+				log.Printf("Skipping %s/%s: invalid config: %v", category, row.Code, err)
+				continue
+			}
+			products = append(products, p)
+		}
+		return ProductListResponse{World: worldType, Products: products, Total: len(products)}, nil
+	})
+}
+
+// GET /api/v1/worlds/{worldType}/locations
+func (h *Handler) ListLocations(w http.ResponseWriter, r *http.Request) {
+	worldType := chi.URLParam(r, "worldType")
+	category := "locations_" + models.WorldSuffix(worldType)
+	h.serve(w, r, "locations:"+worldType, []string{"countries", category}, func(ctx context.Context) (any, error) {
+		if err := h.checkWorld(ctx, worldType); err != nil {
+			return nil, err
+		}
+		rows, err := loadCatalog(ctx, h.db, category)
+		if err != nil {
+			return nil, err
+		}
+		locations := []Location{}
+		for _, row := range rows {
+			l := Location{Code: row.Code, Name: row.Name, Icon: row.Icon, Description: row.Description}
+			if err := json.Unmarshal(row.Config, &l.LocationConfig); err != nil {
+				log.Printf("Skipping %s/%s: invalid config: %v", category, row.Code, err)
+				continue
+			}
+			locations = append(locations, l)
+		}
+		return LocationListResponse{World: worldType, Locations: locations, Total: len(locations)}, nil
+	})
+}
+
+// GET /api/v1/worlds/{worldType}/events
+// Events are the same in every world
+func (h *Handler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	worldType := chi.URLParam(r, "worldType")
+	h.serve(w, r, "events:"+worldType, []string{"countries", "events"}, func(ctx context.Context) (any, error) {
+		if err := h.checkWorld(ctx, worldType); err != nil {
+			return nil, err
+		}
+		rows, err := loadCatalog(ctx, h.db, "events")
+		if err != nil {
+			return nil, err
+		}
+		events := []Event{}
+		for _, row := range rows {
+			e := Event{Code: row.Code, Name: row.Name, Icon: row.Icon, Description: row.Description}
+			if err := json.Unmarshal(row.Config, &e.EventConfig); err != nil {
+				log.Printf("Skipping events/%s: invalid config: %v", row.Code, err)
+				continue
+			}
+			events = append(events, e)
+		}
+		return EventListResponse{World: worldType, Events: events, Total: len(events)}, nil
+	})
+}
+
+// serve answers with the cached catalog while the parameters matching
+// patterns are unchanged, building it otherwise, and honors If-None-Match
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, key string, patterns []string, build func(ctx context.Context) (any, error)) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	version, err := h.version(ctx, patterns)
+	if err != nil {
+		log.Printf("Error reading %s version: %v", key, err)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch catalog"})
+		return
+	}
+
+	e, ok := h.cache.get(key, version)
+	if !ok {
+		data, err := build(ctx)
+		if errors.Is(err, errWorldNotFound) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, map[string]string{"error": "World not found"})
+			return
+		}
+		if err != nil {
+			log.Printf("Error building %s: %v", key, err)
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to fetch catalog"})
+			return
+		}
+
+		body, err := json.Marshal(data)
+		if err != nil {
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to fetch catalog"})
+			return
+		}
+		e = entry{version: version, body: body, etag: ETag(body)}
+		h.cache.put(key, e)
+	}
+
+	w.Header().Set("ETag", e.etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	if Matches(r.Header.Get("If-None-Match"), e.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(e.body)
+}
+
+// version identifies the state of the parameters a catalog is built from:
+// any insert, edit or delete changes it
+func (h *Handler) version(ctx context.Context, patterns []string) (string, error) {
+	var count int
+	var updated time.Time
+	err := h.db.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(MAX(updated_at), 'epoch')
+		FROM parameters
+		WHERE category LIKE ANY($1)
+	`, patterns).Scan(&count, &updated)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d", count, updated.UnixNano()), nil
+}
+
+// checkWorld fails with errWorldNotFound unless worldType is an active country
+func (h *Handler) checkWorld(ctx context.Context, worldType string) error {
+	var exists bool
+	err := h.db.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM parameters WHERE category = 'countries' AND code = $1 AND is_active = true)
+	`, worldType).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errWorldNotFound
+	}
+	return nil
+}
+
+// row is a catalog entry as stored in parameters
+type row struct {
+	Code        string
+	Name        string
+	Icon        string
+	Description string
+	Config      []byte
+}
+
+// loadCatalog reads the active entries of a category in display order
+func loadCatalog(ctx context.Context, db *pgxpool.Pool, category string) ([]row, error) {
+	rows, err := db.Query(ctx, `
+		SELECT code, name, COALESCE(icon, ''), COALESCE(description, ''), COALESCE(config, '{}')
+		FROM parameters
+		WHERE category = $1 AND is_active = true
+		ORDER BY sort_order, name
+	`, category)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[row])
+}
+
+// loadWorlds lists every country, active or not, with its catalog sizes
+func (h *Handler) loadWorlds(ctx context.Context) (any, error) {
+	counts := map[string]int{}
+	rows, err := h.db.Query(ctx, `
+		SELECT category, COUNT(*)
+		FROM parameters
+		WHERE is_active = true AND category LIKE ANY($1)
+		GROUP BY category
+	`, []string{`products\_%`, `locations\_%`, `ingredients\_%`})
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var category string
+		var n int
+		if err := rows.Scan(&category, &n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		counts[category] = n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = h.db.Query(ctx, `
+		SELECT code, name, COALESCE(icon, ''), COALESCE(config, '{}'), is_active
+		FROM parameters
+		WHERE category = 'countries'
+		ORDER BY sort_order, name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	worlds, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (World, error) {
+		var world World
+		var config []byte
+		var active bool
+		if err := row.Scan(&world.Code, &world.Name, &world.Icon, &config, &active); err != nil {
+			return world, err
+		}
+
+		var cfg WorldConfig
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			log.Printf("Invalid config for country %s: %v", world.Code, err)
+		}
+		world.Currency = cfg.Currency
+		world.CurrencyName = cfg.CurrencyName
+		world.StartingMoney = cfg.StartingMoney
+		world.EmergencyLoan = cfg.EmergencyLoan
+
+		suffix := models.WorldSuffix(world.Code)
+		world.Catalogs = CatalogCounts{
+			Products:    counts["products_"+suffix],
+			Locations:   counts["locations_"+suffix],
+			Ingredients: counts["ingredients_"+suffix],
+		}
+		world.Available = Available(active, world.Catalogs)
+		return world, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if worlds == nil {
+		worlds = []World{}
+	}
+
+	return WorldListResponse{Worlds: worlds, Total: len(worlds)}, nil
+}
+
+// Available reports whether a new game can be started in a world: it has to
+// be active and have something to sell and somewhere to park. Worlds without
+// ingredients use the Costa Rica market.
+func Available(active bool, catalogs CatalogCounts) bool {
+	return active && catalogs.Products > 0 && catalogs.Locations > 0
+}
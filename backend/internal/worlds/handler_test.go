@@ -0,0 +1,53 @@
+package worlds
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAvailable(t *testing.T) {
+	full := CatalogCounts{Products: 10, Locations: 5, Ingredients: 30}
+	if !Available(true, full) {
+		t.Error("active world with catalogs should be available")
+	}
+	if Available(false, full) {
+		t.Error("inactive world shouldn't be available")
+	}
+	if Available(true, CatalogCounts{Products: 10}) {
+		t.Error("world without locations shouldn't be available")
+	}
+	if !Available(true, CatalogCounts{Products: 10, Locations: 5}) {
+		t.Error("world without ingredients uses the Costa Rica market")
+	}
+}
+
+func TestConfigSchemas(t *testing.T) {
+	var product ProductConfig
+	err := json.Unmarshal([]byte(`{"base_price": 25, "base_cost": 1.5, "popularity": "very_high", "flavor": "savory", "category": "food"}`), &product)
+	if err != nil || product.BasePrice != 25 || product.BaseCost != 1.5 || product.Category != "food" {
+		t.Errorf("product config = %+v, %v", product, err)
+	}
+
+	var location LocationConfig
+	err = json.Unmarshal([]byte(`{"foot_traffic": "high", "competition": "medium", "rent": 5000, "customer_mix": {"family": 3, "student": 1}}`), &location)
+	if err != nil || location.Rent != 5000 || location.CustomerMix["family"] != 3 {
+		t.Errorf("location config = %+v, %v", location, err)
+	}
+
+	var event EventConfig
+	err = json.Unmarshal([]byte(`{"duration_days": 2, "customer_boost": 1.3, "drinks_boost": 2.0, "market_modifiers": {"fruit": 1.25}}`), &event)
+	if err != nil || event.DurationDays != 2 || event.MarketModifiers["fruit"] != 1.25 {
+		t.Errorf("event config = %+v, %v", event, err)
+	}
+
+	var world WorldConfig
+	err = json.Unmarshal([]byte(`{"currency": "$", "currency_name": "pesos", "starting_money": 500, "emergency_loan": {"amount": 350, "interest": 0.10, "repayment_rate": 0.30}}`), &world)
+	if err != nil || world.StartingMoney != 500 || world.EmergencyLoan == nil || world.EmergencyLoan.Amount != 350 {
+		t.Errorf("world config = %+v, %v", world, err)
+	}
+
+	// A price written as text doesn't fit the schema
+	if err := json.Unmarshal([]byte(`{"base_price": "25"}`), &product); err == nil {
+		t.Error("text price should be rejected")
+	}
+}
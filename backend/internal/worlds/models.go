@@ -0,0 +1,119 @@
+package worlds
+
+// World is a playable country from the countries parameters
+type World struct {
+	Code          string         `json:"code"`
+	Name          string         `json:"name"`
+	Icon          string         `json:"icon,omitempty"`
+	Currency      string         `json:"currency"`      // symbol: ₡, $
+	CurrencyName  string         `json:"currency_name"` // colones, pesos, dollars
+	StartingMoney int64          `json:"starting_money"`
+	EmergencyLoan *EmergencyLoan `json:"emergency_loan,omitempty"`
+	Available     bool           `json:"available"` // active and with products and locations
+	Catalogs      CatalogCounts  `json:"catalogs"`
+}
+
+// WorldConfig is the config of a countries parameter
+type WorldConfig struct {
+	Currency      string         `json:"currency"`
+	CurrencyName  string         `json:"currency_name"`
+	StartingMoney int64          `json:"starting_money"`
+	EmergencyLoan *EmergencyLoan `json:"emergency_loan,omitempty"`
+}
+
+// EmergencyLoan is what the world lends a bankrupt player
+type EmergencyLoan struct {
+	Amount        int64   `json:"amount"`
+	Interest      float64 `json:"interest"`
+	RepaymentRate float64 `json:"repayment_rate"`
+}
+
+// CatalogCounts is how many active entries each of the world's catalogs has
+type CatalogCounts struct {
+	Products    int `json:"products"`
+	Locations   int `json:"locations"`
+	Ingredients int `json:"ingredients"`
+}
+
+// Product is a dish of the world's products_* catalog
+type Product struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Icon        string `json:"icon,omitempty"`
+	Description string `json:"description,omitempty"`
+	ProductConfig
+}
+
+// ProductConfig is the config of a products_* parameter
+type ProductConfig struct {
+	BasePrice  float64 `json:"base_price"`
+	BaseCost   float64 `json:"base_cost"`            // US costs have cents
+	Popularity string  `json:"popularity,omitempty"` // low, medium, high, very_high
+	Flavor     string  `json:"flavor,omitempty"`     // sweet, savory, fresh
+	Category   string  `json:"category,omitempty"`   // food, frozen, hot_drink, cold_drink
+}
+
+// Location is a spot of the world's locations_* catalog
+type Location struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Icon        string `json:"icon,omitempty"`
+	Description string `json:"description,omitempty"`
+	LocationConfig
+}
+
+// LocationConfig is the config of a locations_* parameter
+type LocationConfig struct {
+	FootTraffic   string         `json:"foot_traffic"` // low, medium, high, very_high
+	Competition   string         `json:"competition"`
+	Rent          int            `json:"rent"`
+	MinReputation int            `json:"min_reputation,omitempty"`
+	Requirement   string         `json:"requirement,omitempty"`
+	CustomerMix   map[string]int `json:"customer_mix,omitempty"` // customer type → weight
+}
+
+// Event is a game event from the events parameters
+type Event struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Icon        string `json:"icon,omitempty"`
+	Description string `json:"description,omitempty"`
+	EventConfig
+}
+
+// EventConfig is the config of an events parameter
+type EventConfig struct {
+	DurationDays     int                `json:"duration_days"`
+	CustomerBoost    float64            `json:"customer_boost"`
+	PriceBoost       float64            `json:"price_boost,omitempty"`
+	DrinksBoost      float64            `json:"drinks_boost,omitempty"`
+	LocationSpecific bool               `json:"location_specific,omitempty"`
+	MarketModifiers  map[string]float64 `json:"market_modifiers,omitempty"` // ingredient type or tag → price multiplier
+}
+
+// WorldListResponse is the response for GET /worlds
+type WorldListResponse struct {
+	Worlds []World `json:"worlds"`
+	Total  int     `json:"total"`
+}
+
+// ProductListResponse is the response for GET /worlds/{worldType}/products
+type ProductListResponse struct {
+	World    string    `json:"world_type"`
+	Products []Product `json:"products"`
+	Total    int       `json:"total"`
+}
+
+// LocationListResponse is the response for GET /worlds/{worldType}/locations
+type LocationListResponse struct {
+	World     string     `json:"world_type"`
+	Locations []Location `json:"locations"`
+	Total     int        `json:"total"`
+}
+
+// EventListResponse is the response for GET /worlds/{worldType}/events
+type EventListResponse struct {
+	World  string  `json:"world_type"`
+	Events []Event `json:"events"`
+	Total  int     `json:"total"`
+}
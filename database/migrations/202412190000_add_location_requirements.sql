@@ -0,0 +1,25 @@
+-- ============================================
+-- CalleViva - Location Requirements Migration
+-- ============================================
+-- 202412190000_add_location_requirements.sql
+-- Requisitos de reputación por ubicación (GDD 4.3) y control de alquiler diario
+
+-- ============================================
+-- REQUISITOS DE UBICACIÓN
+-- ============================================
+-- min_reputation: reputación mínima para instalarse
+-- requirement: etiqueta para mostrar en el cliente
+UPDATE parameters
+SET config = config || '{"min_reputation": 40, "requirement": "Rep 40+"}'
+WHERE (category, code) IN (('locations_cr', 'playa'), ('locations_us', 'beach'));
+
+UPDATE parameters
+SET config = config || '{"min_reputation": 60, "requirement": "Rep 60+"}'
+WHERE (category, code) IN (('locations_mx', 'estadio'), ('locations_us', 'stadium'));
+
+-- ============================================
+-- ALQUILER DIARIO
+-- ============================================
+-- Día de juego en el que se pagó la ubicación actual.
+-- El día solo puede abrir si la ubicación está pagada para ese día.
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS location_day INT;
@@ -0,0 +1,57 @@
+-- ============================================
+-- CalleViva - Customer Profiles Migration
+-- ============================================
+-- 202412190001_add_customer_profiles.sql
+-- Sabor de cada producto (preferencia del cliente, GDD 4.2)
+-- y mezcla de tipos de cliente por ubicación
+
+-- ============================================
+-- SABOR DE PRODUCTOS (sweet, savory, fresh)
+-- ============================================
+UPDATE parameters SET config = config || '{"flavor": "savory"}'
+WHERE (category, code) IN (
+    ('products_cr', 'gallo_pinto'), ('products_cr', 'casado'), ('products_cr', 'empanadas'), ('products_cr', 'tacos'),
+    ('products_mx', 'tacos_pastor'), ('products_mx', 'quesadillas'), ('products_mx', 'elotes'), ('products_mx', 'tamales'),
+    ('products_mx', 'tortas'), ('products_mx', 'esquites'), ('products_mx', 'tostadas'),
+    ('products_us', 'hot_dog'), ('products_us', 'burger'), ('products_us', 'fries'), ('products_us', 'nachos'),
+    ('products_us', 'pizza_slice'), ('products_us', 'pretzel'), ('products_us', 'corn_dog')
+);
+
+UPDATE parameters SET config = config || '{"flavor": "sweet"}'
+WHERE (category, code) IN (
+    ('products_cr', 'churchill'), ('products_cr', 'agua_dulce'),
+    ('products_mx', 'churros'),
+    ('products_us', 'ice_cream')
+);
+
+UPDATE parameters SET config = config || '{"flavor": "fresh"}'
+WHERE (category, code) IN (
+    ('products_mx', 'horchata'), ('products_mx', 'aguas_frescas'),
+    ('products_us', 'lemonade'), ('products_us', 'soda')
+);
+
+-- ============================================
+-- MEZCLA DE CLIENTES POR UBICACIÓN
+-- ============================================
+-- Pesos relativos por customer_type. Tipos sin peso no aparecen.
+-- Ubicaciones sin customer_mix usan todos los tipos por igual.
+UPDATE parameters SET config = config || '{"customer_mix": {"family": 3, "student": 1, "worker": 1, "tourist": 1, "regular": 1}}'
+WHERE category = 'locations_cr' AND code = 'parque_central';
+
+UPDATE parameters SET config = config || '{"customer_mix": {"student": 5, "foodie": 1, "influencer": 1, "regular": 1}}'
+WHERE (category, code) IN (('locations_cr', 'universidad'), ('locations_mx', 'universidad'), ('locations_us', 'college'));
+
+UPDATE parameters SET config = config || '{"customer_mix": {"tourist": 4, "family": 2, "influencer": 1, "foodie": 1}}'
+WHERE (category, code) IN (('locations_cr', 'playa'), ('locations_us', 'beach'));
+
+UPDATE parameters SET config = config || '{"customer_mix": {"worker": 2, "family": 2, "regular": 2, "student": 1}}'
+WHERE (category, code) IN (('locations_cr', 'mercado'), ('locations_mx', 'mercado'));
+
+UPDATE parameters SET config = config || '{"customer_mix": {"worker": 5, "foodie": 1, "critic": 1, "regular": 1}}'
+WHERE (category, code) IN (('locations_cr', 'oficinas'), ('locations_us', 'downtown'));
+
+UPDATE parameters SET config = config || '{"customer_mix": {"tourist": 3, "family": 2, "worker": 1, "influencer": 1}}'
+WHERE (category, code) IN (('locations_mx', 'zocalo'), ('locations_mx', 'chapultepec'), ('locations_us', 'park'));
+
+UPDATE parameters SET config = config || '{"customer_mix": {"family": 3, "student": 2, "influencer": 1}}'
+WHERE (category, code) IN (('locations_mx', 'estadio'), ('locations_us', 'stadium'));
@@ -0,0 +1,59 @@
+-- ============================================
+-- CalleViva - Weather System Migration
+-- ============================================
+-- 202412190002_add_weather_forecast.sql
+-- Probabilidades, efectos por categoría de producto (GDD 4.4) y pronóstico
+
+-- ============================================
+-- CLIMA: probabilidad, tráfico y categorías
+-- ============================================
+-- probability: probabilidad base de que toque ese clima
+-- traffic_modifier: multiplicador de clientes por hora
+-- category_modifiers: multiplicador de demanda por categoría de producto
+UPDATE parameters SET config = config || '{
+    "probability": 0.50, "traffic_modifier": 1.2, "message": "¡Buen día para helados!",
+    "category_modifiers": {"frozen": 1.3, "cold_drink": 1.2, "hot_drink": 0.8}
+}'
+WHERE category = 'weather' AND code = 'sunny';
+
+UPDATE parameters SET config = config || '{
+    "probability": 0.25, "traffic_modifier": 1.0, "message": "Día tranquilo, sin sorpresas.",
+    "category_modifiers": {}
+}'
+WHERE category = 'weather' AND code = 'cloudy';
+
+UPDATE parameters SET config = config || '{
+    "probability": 0.20, "traffic_modifier": 0.75, "message": "Llueve: la gente busca algo calientito.",
+    "category_modifiers": {"frozen": 0.7, "cold_drink": 0.8, "hot_drink": 1.3}
+}'
+WHERE category = 'weather' AND code = 'rainy';
+
+UPDATE parameters SET config = config || '{
+    "probability": 0.05, "traffic_modifier": 0.5, "message": "Tormenta: casi nadie sale, considerá cerrar.",
+    "category_modifiers": {"frozen": 0.5, "cold_drink": 0.7, "hot_drink": 1.2}
+}'
+WHERE category = 'weather' AND code = 'stormy';
+
+-- ============================================
+-- CATEGORÍA DE PRODUCTOS (food, frozen, cold_drink, hot_drink)
+-- ============================================
+UPDATE parameters SET config = config || '{"category": "food"}'
+WHERE category IN ('products_cr', 'products_mx', 'products_us');
+
+UPDATE parameters SET config = config || '{"category": "frozen"}'
+WHERE (category, code) IN (('products_cr', 'churchill'), ('products_us', 'ice_cream'));
+
+UPDATE parameters SET config = config || '{"category": "hot_drink"}'
+WHERE (category, code) IN (('products_cr', 'agua_dulce'));
+
+UPDATE parameters SET config = config || '{"category": "cold_drink"}'
+WHERE (category, code) IN (
+    ('products_mx', 'horchata'), ('products_mx', 'aguas_frescas'),
+    ('products_us', 'lemonade'), ('products_us', 'soda')
+);
+
+-- ============================================
+-- PRONÓSTICO PARA MAÑANA
+-- ============================================
+-- {"sunny": 0.7, "cloudy": 0.2, "rainy": 0.1}
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS weather_forecast JSONB;
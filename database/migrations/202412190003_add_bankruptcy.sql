@@ -0,0 +1,73 @@
+-- ============================================
+-- CalleViva - Bankruptcy Migration
+-- ============================================
+-- 202412190003_add_bankruptcy.sql
+-- Quiebra tras 3 días seguidos con dinero negativo (GDD):
+-- préstamo de emergencia, reiniciar el día o empezar de nuevo
+
+-- ============================================
+-- ESTADO 'bankrupt'
+-- ============================================
+INSERT INTO parameters (category, code, name, icon, sort_order) VALUES
+('game_status', 'bankrupt', 'En quiebra', '💸', 4)
+ON CONFLICT (category, code) DO NOTHING;
+
+-- ============================================
+-- PRÉSTAMO DE EMERGENCIA POR MUNDO
+-- ============================================
+-- amount: lo que recibe el jugador
+-- interest: recargo sobre el monto (0.10 = debe 110%)
+-- repayment_rate: porción de la ganancia diaria que se descuenta hasta pagarlo
+UPDATE parameters SET config = config || '{"emergency_loan": {"amount": 10000, "interest": 0.10, "repayment_rate": 0.30}}'
+WHERE category = 'countries' AND code = 'costa_rica';
+
+UPDATE parameters SET config = config || '{"emergency_loan": {"amount": 350, "interest": 0.10, "repayment_rate": 0.30}}'
+WHERE category = 'countries' AND code = 'mexico';
+
+UPDATE parameters SET config = config || '{"emergency_loan": {"amount": 70, "interest": 0.10, "repayment_rate": 0.30}}'
+WHERE category = 'countries' AND code = 'usa';
+
+-- ============================================
+-- SEGUIMIENTO EN LA PARTIDA
+-- ============================================
+-- negative_days: días seguidos cerrados con dinero negativo
+-- loan_balance: lo que falta pagar del préstamo
+-- loan_repayment_rate: porción de la ganancia que se descuenta cada día
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS negative_days INT DEFAULT 0;
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS loan_balance BIGINT DEFAULT 0;
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS loan_repayment_rate DECIMAL(4,2) DEFAULT 0;
+
+-- ============================================
+-- PRÉSTAMOS
+-- ============================================
+CREATE TABLE IF NOT EXISTS loans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID REFERENCES game_sessions(id) ON DELETE CASCADE,
+    game_day INT NOT NULL,
+    amount BIGINT NOT NULL,
+    total_due BIGINT NOT NULL,
+    repayment_rate DECIMAL(4,2) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_loans_session ON loans(session_id);
+
+-- ============================================
+-- SNAPSHOTS DIARIOS (para reiniciar el día)
+-- ============================================
+-- Estado de la partida justo antes de simular cada día
+CREATE TABLE IF NOT EXISTS day_snapshots (
+    session_id UUID REFERENCES game_sessions(id) ON DELETE CASCADE,
+    game_day INT NOT NULL,
+    money BIGINT NOT NULL,
+    reputation INT NOT NULL,
+    weather VARCHAR(20),
+    weather_forecast JSONB,
+    current_location VARCHAR(50),
+    location_day INT,
+    negative_days INT DEFAULT 0,
+    loan_balance BIGINT DEFAULT 0,
+    loan_repayment_rate DECIMAL(4,2) DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (session_id, game_day)
+);
@@ -0,0 +1,108 @@
+-- ============================================
+-- CalleViva - Upgrade Shop Migration
+-- ============================================
+-- 202412190004_add_upgrade_effects.sql
+-- Efectos de mejoras y equipo sobre el truck, requisitos y niveles
+
+-- ============================================
+-- STATS DEL TRUCK
+-- ============================================
+-- queue_tolerance: cuánto aguanta la gente la fila (1.0 = normal)
+-- checkout_error_rate: porción de ventas cobradas mal (vuelto equivocado)
+ALTER TABLE trucks ADD COLUMN IF NOT EXISTS queue_tolerance DECIMAL(3,2) DEFAULT 1.0;
+ALTER TABLE trucks ADD COLUMN IF NOT EXISTS checkout_error_rate DECIMAL(4,3) DEFAULT 0.05;
+
+UPDATE parameters SET config = config || '{"queue_tolerance": 1.0, "checkout_errors": 0.05}'
+WHERE category = 'truck_types' AND code = 'cart';
+
+UPDATE parameters SET config = config || '{"queue_tolerance": 1.1, "checkout_errors": 0.04}'
+WHERE category = 'truck_types' AND code = 'stand';
+
+UPDATE parameters SET config = config || '{"queue_tolerance": 1.2, "checkout_errors": 0.03}'
+WHERE category = 'truck_types' AND code = 'truck';
+
+UPDATE parameters SET config = config || '{"queue_tolerance": 1.3, "checkout_errors": 0.02}'
+WHERE category = 'truck_types' AND code = 'restaurant';
+
+-- ============================================
+-- MEJORAS (con niveles)
+-- ============================================
+-- effects: lo que suma CADA nivel (capacity 0.5 = +50% porciones,
+--          checkout_errors 0.3 = 30% menos errores de cobro)
+-- cost_growth: cada nivel cuesta cost × cost_growth^(nivel-1)
+-- Las mejoras sin effects (neon_sign, generator) no se venden todavía
+UPDATE parameters SET config = config || '{
+    "max_level": 3, "cost_growth": 1.5, "min_day": 1, "min_reputation": 0,
+    "effects": {"speed": 0.20}
+}'
+WHERE category = 'upgrades' AND code = 'better_grill';
+
+UPDATE parameters SET config = config || '{
+    "max_level": 3, "cost_growth": 1.5, "min_day": 3, "min_reputation": 0,
+    "effects": {"capacity": 0.50}
+}'
+WHERE category = 'upgrades' AND code = 'bigger_fridge';
+
+UPDATE parameters SET config = config || '{
+    "max_level": 2, "cost_growth": 1.5, "min_day": 5, "min_reputation": 20,
+    "effects": {"queue_tolerance": 0.15},
+    "description": "Los clientes esperan 15% más en la fila"
+}'
+WHERE category = 'upgrades' AND code = 'sound_system';
+
+UPDATE parameters SET config = config || '{
+    "max_level": 2, "cost_growth": 1.5, "min_day": 5, "min_reputation": 30,
+    "effects": {"speed": 0.15, "checkout_errors": 0.20},
+    "description": "Atiende 15% más rápido, 20% menos errores al cobrar"
+}'
+WHERE category = 'upgrades' AND code = 'menu_board';
+
+UPDATE parameters SET config = config || '{
+    "max_level": 1, "min_day": 2, "min_reputation": 0,
+    "effects": {"queue_tolerance": 0.10},
+    "description": "Los clientes esperan 10% más en la fila"
+}'
+WHERE category = 'upgrades' AND code = 'umbrella';
+
+UPDATE parameters SET config = config || '{
+    "max_level": 1, "min_day": 7, "min_reputation": 40,
+    "effects": {"speed": 0.10, "checkout_errors": 0.60},
+    "description": "Atiende 10% más rápido, 60% menos errores al cobrar"
+}'
+WHERE category = 'upgrades' AND code = 'pos_system';
+
+-- ============================================
+-- EQUIPO (un solo nivel, limitado por max_equipment del vehículo)
+-- ============================================
+UPDATE parameters SET config = config || '{"min_day": 3, "effects": {"speed": 0.20}}'
+WHERE category = 'equipment' AND code = 'improved_stove';
+
+UPDATE parameters SET config = config || '{"min_day": 10, "min_reputation": 50, "effects": {"speed": 0.40, "capacity": 0.10}, "description": "Cocina 40% más rápido, +10% capacidad"}'
+WHERE category = 'equipment' AND code = 'pro_stove';
+
+UPDATE parameters SET config = config || '{"min_day": 1, "effects": {"capacity": 0.25}, "description": "+25% capacidad de ingredientes"}'
+WHERE category = 'equipment' AND code = 'cooler';
+
+UPDATE parameters SET config = config || '{"min_day": 5, "min_reputation": 25, "effects": {"capacity": 0.50}, "description": "+50% capacidad de ingredientes"}'
+WHERE category = 'equipment' AND code = 'fridge';
+
+UPDATE parameters SET config = config || '{"min_day": 10, "min_reputation": 50, "effects": {"capacity": 0.75}, "description": "+75% capacidad de ingredientes"}'
+WHERE category = 'equipment' AND code = 'freezer';
+
+UPDATE parameters SET config = config || '{"min_day": 3, "effects": {"speed": 0.15, "checkout_errors": 0.40}, "description": "+15% velocidad, 40% menos errores al cobrar"}'
+WHERE category = 'equipment' AND code = 'cash_register';
+
+UPDATE parameters SET config = config || '{"min_day": 7, "min_reputation": 40, "effects": {"speed": 0.25, "checkout_errors": 0.70}, "description": "+25% velocidad, 70% menos errores al cobrar"}'
+WHERE category = 'equipment' AND code = 'pos_system';
+
+UPDATE parameters SET config = config || '{"min_day": 3, "effects": {"capacity": 0.30}}'
+WHERE category = 'equipment' AND code = 'shelves';
+
+UPDATE parameters SET config = config || '{"min_day": 7, "min_reputation": 30, "effects": {"capacity": 0.75}}'
+WHERE category = 'equipment' AND code = 'warehouse';
+
+UPDATE parameters SET config = config || '{"min_day": 2, "effects": {"queue_tolerance": 0.20}, "description": "Los clientes esperan 20% más en la fila"}'
+WHERE category = 'equipment' AND code = 'awning';
+
+UPDATE parameters SET config = config || '{"min_day": 7, "min_reputation": 30, "effects": {"queue_tolerance": 0.40}, "description": "Los clientes esperan 40% más en la fila"}'
+WHERE category = 'equipment' AND code = 'enclosure';
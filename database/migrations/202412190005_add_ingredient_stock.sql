@@ -0,0 +1,54 @@
+-- ============================================
+-- CalleViva - Ingredient Stock Migration
+-- ============================================
+-- 202412190005_add_ingredient_stock.sql
+-- Inventario por cantidad: lotes con fecha de compra, consumo por
+-- platillo vendido y pérdida de perecederos
+
+-- ============================================
+-- PLAYER INGREDIENTS (ingredientes conocidos + stock total)
+-- ============================================
+CREATE TABLE IF NOT EXISTS player_ingredients (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    player_id UUID NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    ingredient_code VARCHAR(50) NOT NULL,
+    quantity INT NOT NULL DEFAULT 0,
+    source VARCHAR(20) DEFAULT 'purchase',  -- starter, purchase, event, reward
+    acquired_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE(session_id, player_id, ingredient_code)
+);
+
+ALTER TABLE player_ingredients ADD COLUMN IF NOT EXISTS quantity INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_player_ingredients_session ON player_ingredients(session_id);
+
+-- ============================================
+-- INVENTORY (lotes de compra, se consumen FIFO)
+-- ============================================
+-- Cada compra es un lote: item_type = código del ingrediente
+-- expires_day: día en que el lote ya no sirve (NULL = no perecedero)
+ALTER TABLE inventory DROP CONSTRAINT IF EXISTS inventory_session_id_item_type_key;
+ALTER TABLE inventory ADD COLUMN IF NOT EXISTS acquired_day INT NOT NULL DEFAULT 1;
+ALTER TABLE inventory ADD COLUMN IF NOT EXISTS expires_day INT;
+
+CREATE INDEX IF NOT EXISTS idx_inventory_item ON inventory(session_id, item_type);
+
+-- El reinicio de día también restaura el inventario
+ALTER TABLE day_snapshots ADD COLUMN IF NOT EXISTS inventory JSONB DEFAULT '[]';
+
+-- ============================================
+-- VIDA ÚTIL (días que dura un ingrediente)
+-- ============================================
+-- Sin shelf_life_days = no se echa a perder (granos, condimentos, azúcar)
+UPDATE parameters SET config = config || '{"shelf_life_days": 3}'
+WHERE category = 'ingredients_cr' AND config->>'type' IN ('protein', 'herb', 'bread', 'dough');
+
+UPDATE parameters SET config = config || '{"shelf_life_days": 4}'
+WHERE category = 'ingredients_cr' AND config->>'type' IN ('dairy', 'fruit');
+
+UPDATE parameters SET config = config || '{"shelf_life_days": 5}'
+WHERE category = 'ingredients_cr' AND config->>'type' IN ('vegetable', 'citrus');
+
+UPDATE parameters SET config = config || '{"shelf_life_days": 2}'
+WHERE category = 'ingredients_cr' AND code IN ('pescado', 'camarones');
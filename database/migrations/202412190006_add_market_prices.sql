@@ -0,0 +1,65 @@
+-- ============================================
+-- CalleViva - Dynamic Market Prices Migration
+-- ============================================
+-- 202412190006_add_market_prices.sql
+-- Precios diarios por partida: varían dentro de la banda de cada tier y
+-- reaccionan al clima, a los eventos y a lo que compra el jugador
+
+-- ============================================
+-- HOJA DE PRECIOS DEL DÍA
+-- ============================================
+-- Se genera la primera vez que se consulta el mercado en un día y no cambia:
+-- catálogo, compras y simulación usan los mismos precios.
+-- purchased: unidades compradas ese día (suben el precio los días siguientes)
+CREATE TABLE IF NOT EXISTS market_prices (
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    game_day INT NOT NULL,
+    ingredient_code VARCHAR(50) NOT NULL,
+    price INT NOT NULL,
+    base_price INT NOT NULL,
+    modifiers JSONB DEFAULT '{}',  -- {"fluctuation": 1.04, "weather": 1.3, "event": 1.0, "volume": 1.05}
+    purchased INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (session_id, game_day, ingredient_code)
+);
+
+-- ============================================
+-- TIERS (banda de variación diaria)
+-- ============================================
+-- basic ±10%, common ±15%, premium ±25%, special ±35%
+UPDATE parameters SET config = config || '{"tier": "basic"}'
+WHERE category = 'ingredients_cr' AND NOT config ? 'tier'
+  AND (config->'tags' ? 'base' OR config->'tags' ? 'essential');
+
+UPDATE parameters SET config = config || '{"tier": "premium"}'
+WHERE category = 'ingredients_cr' AND NOT config ? 'tier'
+  AND config->'tags' ? 'premium';
+
+-- ============================================
+-- CLIMA EN EL MERCADO
+-- ============================================
+-- ingredient_modifiers: por tipo o tag de ingrediente ("*" = todos)
+UPDATE parameters SET config = config || '{"ingredient_modifiers": {"fruit": 0.95, "vegetable": 0.95}}'
+WHERE category = 'weather' AND code = 'sunny';
+
+UPDATE parameters SET config = config || '{"ingredient_modifiers": {"seafood": 1.15, "vegetable": 1.05}}'
+WHERE category = 'weather' AND code = 'rainy';
+
+-- Con tormenta no salen los pescadores
+UPDATE parameters SET config = config || '{"ingredient_modifiers": {"seafood": 1.40, "fruit": 1.15, "vegetable": 1.10, "*": 1.05}}'
+WHERE category = 'weather' AND code = 'stormy';
+
+-- ============================================
+-- EVENTOS EN EL MERCADO
+-- ============================================
+UPDATE parameters SET config = config || '{"market_modifiers": {"*": 1.10}}'
+WHERE category = 'events' AND code = 'festival';
+
+UPDATE parameters SET config = config || '{"market_modifiers": {"*": 1.05}}'
+WHERE category = 'events' AND code = 'holiday';
+
+UPDATE parameters SET config = config || '{"market_modifiers": {"fruit": 1.25, "beverage": 1.20}}'
+WHERE category = 'events' AND code = 'heat_wave';
+
+UPDATE parameters SET config = config || '{"market_modifiers": {"protein": 1.15}}'
+WHERE category = 'events' AND code = 'sports';
@@ -0,0 +1,29 @@
+-- ============================================
+-- CalleViva - Money Ledger Migration
+-- ============================================
+-- 202412190007_add_money_ledger.sql
+-- Libro de movimientos: cada cambio al dinero de la partida queda registrado
+-- con su motivo, su referencia y el día de juego
+
+-- ============================================
+-- MOVIMIENTOS DE DINERO
+-- ============================================
+-- Partida doble: cada movimiento carga una cuenta y abona otra.
+-- La caja del jugador es la cuenta 'cash'; amount es el efecto sobre la caja
+-- (positivo = entra dinero, negativo = sale).
+CREATE TABLE IF NOT EXISTS money_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    game_day INT NOT NULL,
+    amount BIGINT NOT NULL,
+    balance_after BIGINT NOT NULL,
+    debit_account VARCHAR(30) NOT NULL,   -- cuenta que recibe
+    credit_account VARCHAR(30) NOT NULL,  -- cuenta que entrega
+    reason VARCHAR(30) NOT NULL,          -- purchase, sell_back, rent, sales, ...
+    reference VARCHAR(100),               -- código de ingrediente, ubicación, mejora...
+    description TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_money_transactions_session ON money_transactions(session_id, game_day);
+CREATE INDEX IF NOT EXISTS idx_money_transactions_reason ON money_transactions(session_id, reason);
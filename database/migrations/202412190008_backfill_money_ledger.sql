@@ -0,0 +1,21 @@
+-- ============================================
+-- CalleViva - Money Ledger Backfill Migration
+-- ============================================
+-- 202412190008_backfill_money_ledger.sql
+-- Las partidas creadas antes del libro de movimientos reciben un saldo de
+-- apertura, para que la suma de sus movimientos dé su dinero actual
+
+INSERT INTO money_transactions
+(session_id, game_day, amount, balance_after, debit_account, credit_account, reason, description, created_at)
+SELECT g.id, 1, opening, opening,
+       CASE WHEN opening >= 0 THEN 'cash' ELSE 'capital' END,
+       CASE WHEN opening >= 0 THEN 'capital' ELSE 'cash' END,
+       'opening', 'Saldo al activar el libro de movimientos', g.created_at
+FROM (
+    SELECT g.id, g.created_at,
+           g.money - COALESCE((SELECT SUM(t.amount) FROM money_transactions t WHERE t.session_id = g.id), 0) AS opening
+    FROM game_sessions g
+    WHERE NOT EXISTS (
+        SELECT 1 FROM money_transactions t WHERE t.session_id = g.id AND t.reason = 'opening'
+    )
+) g;
@@ -0,0 +1,66 @@
+-- ============================================
+-- CalleViva - Game State Audit Migration
+-- ============================================
+-- 202412190009_add_game_state_audit.sql
+-- El estado económico de la partida solo lo cambia el servidor. Los intentos
+-- de editarlo desde el cliente (y las ediciones que ya se hicieron) quedan
+-- registrados para revisarlos antes de armar el leaderboard.
+
+CREATE TABLE IF NOT EXISTS game_state_audit (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    player_id UUID REFERENCES players(id) ON DELETE SET NULL,
+    field VARCHAR(50) NOT NULL,       -- money, game_day, status, stats.xxx...
+    attempted_value JSONB,            -- lo que mandó el cliente
+    current_value JSONB,              -- lo que tenía la partida
+    source VARCHAR(30) NOT NULL,      -- patch (intento rechazado), history (edición ya aplicada)
+    reason TEXT,
+    reviewed BOOLEAN DEFAULT false,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_state_audit_session ON game_state_audit(session_id);
+CREATE INDEX IF NOT EXISTS idx_game_state_audit_pending ON game_state_audit(reviewed) WHERE reviewed = false;
+
+-- ============================================
+-- EDICIONES HISTÓRICAS SOSPECHOSAS
+-- ============================================
+
+-- Saldo editado con PATCH antes del libro de movimientos: la migración
+-- 202412190008 lo dejó como saldo de apertura, que debería ser el capital
+-- inicial del mundo
+INSERT INTO game_state_audit (session_id, player_id, field, attempted_value, current_value, source, reason, created_at)
+SELECT t.session_id, g.player_id, 'money',
+       to_jsonb(t.amount), to_jsonb(COALESCE((c.config->>'starting_money')::bigint, 15000)),
+       'history', 'Saldo de apertura distinto al capital inicial', t.created_at
+FROM money_transactions t
+JOIN game_sessions g ON g.id = t.session_id
+LEFT JOIN parameters c ON c.category = 'countries' AND c.code = g.world_type
+WHERE t.reason = 'opening'
+  AND t.amount <> COALESCE((c.config->>'starting_money')::bigint, 15000);
+
+-- Saldo editado con PATCH después (quedó en el libro como ajuste)
+INSERT INTO game_state_audit (session_id, player_id, field, attempted_value, current_value, source, reason, created_at)
+SELECT t.session_id, g.player_id, 'money',
+       to_jsonb(t.balance_after), to_jsonb(t.balance_after - t.amount),
+       'history', 'Saldo cambiado desde el cliente', t.created_at
+FROM money_transactions t
+JOIN game_sessions g ON g.id = t.session_id
+WHERE t.reason = 'adjustment';
+
+-- Día de juego más adelante que los días jugados
+INSERT INTO game_state_audit (session_id, player_id, field, attempted_value, current_value, source, reason)
+SELECT g.id, g.player_id, 'game_day',
+       to_jsonb(g.game_day), to_jsonb(COALESCE(d.last_day, 0) + 1),
+       'history', 'Día de juego sin días simulados que lo respalden'
+FROM game_sessions g
+LEFT JOIN (
+    SELECT session_id, MAX(game_day) AS last_day FROM day_summaries GROUP BY session_id
+) d ON d.session_id = g.id
+WHERE g.game_day > COALESCE(d.last_day, 0) + 1;
+
+-- Estados que el servidor nunca asigna
+INSERT INTO game_state_audit (session_id, player_id, field, attempted_value, source, reason)
+SELECT g.id, g.player_id, 'status', to_jsonb(g.status), 'history', 'Estado desconocido'
+FROM game_sessions g
+WHERE g.status NOT IN ('active', 'bankrupt', 'finished');
@@ -0,0 +1,105 @@
+-- ============================================
+-- CalleViva - Ingredients for México and USA
+-- ============================================
+-- 202412190010_add_ingredients_mx_us.sql
+-- Catálogo de ingredientes por mundo: cada partida compra en el mercado de su
+-- world_type (ingredients_cr, ingredients_mx, ingredients_us). Un mundo sin
+-- catálogo usa el de Costa Rica.
+-- Costos en la moneda de cada mundo, en la misma escala que su capital inicial
+-- (CR ₡15000, MX $500, US $100).
+
+-- ============================================
+-- INGREDIENTES (México)
+-- ============================================
+INSERT INTO parameters (category, code, name, icon, sort_order, config) VALUES
+-- Granos y Masas
+('ingredients_mx', 'tortilla_maiz', 'Tortilla de Maíz', '🫓', 1, '{"type": "bread", "cost": 3, "tier": "basic", "shelf_life_days": 3, "tags": ["base", "traditional"]}'),
+('ingredients_mx', 'masa', 'Masa de Maíz', '🌽', 2, '{"type": "dough", "cost": 4, "tier": "basic", "shelf_life_days": 3, "tags": ["base", "traditional"]}'),
+('ingredients_mx', 'frijoles', 'Frijoles', '🫘', 3, '{"type": "legume", "cost": 5, "tier": "basic", "tags": ["base", "protein"]}'),
+('ingredients_mx', 'arroz', 'Arroz', '🍚', 4, '{"type": "grain", "cost": 5, "tier": "basic", "tags": ["base", "common"]}'),
+('ingredients_mx', 'bolillo', 'Bolillo', '🥖', 5, '{"type": "bread", "cost": 3, "tier": "basic", "shelf_life_days": 3, "tags": ["common", "sandwich"]}'),
+
+-- Proteínas
+('ingredients_mx', 'cerdo_pastor', 'Cerdo al Pastor', '🥩', 10, '{"type": "protein", "cost": 25, "shelf_life_days": 3, "tags": ["meat", "traditional"]}'),
+('ingredients_mx', 'carne_asada', 'Carne Asada', '🥩', 11, '{"type": "protein", "cost": 35, "tier": "premium", "shelf_life_days": 3, "tags": ["meat", "premium"]}'),
+('ingredients_mx', 'pollo', 'Pollo', '🍗', 12, '{"type": "protein", "cost": 20, "shelf_life_days": 3, "tags": ["meat", "common"]}'),
+('ingredients_mx', 'chorizo', 'Chorizo', '🌭', 13, '{"type": "protein", "cost": 18, "shelf_life_days": 3, "tags": ["meat", "traditional"]}'),
+('ingredients_mx', 'huevo', 'Huevo', '🥚', 14, '{"type": "protein", "cost": 4, "tier": "basic", "shelf_life_days": 3, "tags": ["breakfast", "common", "base"]}'),
+('ingredients_mx', 'camarones', 'Camarones', '🦐', 15, '{"type": "protein", "cost": 50, "tier": "premium", "shelf_life_days": 2, "tags": ["seafood", "premium"]}'),
+('ingredients_mx', 'pescado', 'Pescado', '🐟', 16, '{"type": "protein", "cost": 35, "shelf_life_days": 2, "tags": ["seafood", "coastal"]}'),
+
+-- Vegetales
+('ingredients_mx', 'cebolla', 'Cebolla', '🧅', 20, '{"type": "vegetable", "cost": 2, "tier": "basic", "shelf_life_days": 5, "tags": ["base", "aromatic"]}'),
+('ingredients_mx', 'tomate', 'Jitomate', '🍅', 21, '{"type": "vegetable", "cost": 3, "tier": "basic", "shelf_life_days": 5, "tags": ["fresh", "base"]}'),
+('ingredients_mx', 'chile_serrano', 'Chile Serrano', '🌶️', 22, '{"type": "vegetable", "cost": 2, "tier": "basic", "shelf_life_days": 5, "tags": ["spicy", "base"]}'),
+('ingredients_mx', 'aguacate', 'Aguacate', '🥑', 23, '{"type": "vegetable", "cost": 12, "tier": "premium", "shelf_life_days": 5, "tags": ["fresh", "premium"]}'),
+('ingredients_mx', 'nopal', 'Nopal', '🌵', 24, '{"type": "vegetable", "cost": 4, "shelf_life_days": 5, "tags": ["traditional", "fresh"]}'),
+('ingredients_mx', 'elote', 'Elote', '🌽', 25, '{"type": "vegetable", "cost": 5, "shelf_life_days": 5, "tags": ["traditional", "sweet"]}'),
+
+-- Lácteos
+('ingredients_mx', 'queso_oaxaca', 'Queso Oaxaca', '🧀', 30, '{"type": "dairy", "cost": 15, "shelf_life_days": 4, "tags": ["traditional", "melty"]}'),
+('ingredients_mx', 'crema', 'Crema', '🥛', 31, '{"type": "dairy", "cost": 8, "shelf_life_days": 4, "tags": ["common", "topping"]}'),
+
+-- Condimentos
+('ingredients_mx', 'cilantro', 'Cilantro', '🌿', 40, '{"type": "herb", "cost": 2, "tier": "basic", "shelf_life_days": 3, "tags": ["aromatic", "fresh", "base"]}'),
+('ingredients_mx', 'limon', 'Limón', '🍋', 41, '{"type": "citrus", "cost": 2, "tier": "basic", "shelf_life_days": 5, "tags": ["fresh", "acidic", "base"]}'),
+('ingredients_mx', 'salsa_verde', 'Salsa Verde', '🫙', 42, '{"type": "condiment", "cost": 5, "tags": ["spicy", "traditional"]}'),
+('ingredients_mx', 'mole', 'Mole', '🫙', 43, '{"type": "condiment", "cost": 20, "tier": "special", "tags": ["traditional", "iconic"]}'),
+
+-- Frutas y Bebidas
+('ingredients_mx', 'pina', 'Piña', '🍍', 50, '{"type": "fruit", "cost": 8, "shelf_life_days": 4, "tags": ["tropical", "sweet"]}'),
+('ingredients_mx', 'mango', 'Mango', '🥭', 51, '{"type": "fruit", "cost": 6, "shelf_life_days": 4, "tags": ["tropical", "sweet"]}'),
+('ingredients_mx', 'jamaica', 'Flor de Jamaica', '🌺', 52, '{"type": "beverage", "cost": 6, "tags": ["traditional", "drink"]}'),
+('ingredients_mx', 'canela', 'Canela', '🪵', 53, '{"type": "condiment", "cost": 3, "tags": ["aromatic", "sweet"]}'),
+('ingredients_mx', 'piloncillo', 'Piloncillo', '🍯', 54, '{"type": "sweetener", "cost": 4, "tags": ["traditional", "sweetener"]}')
+
+ON CONFLICT (category, code) DO NOTHING;
+
+-- ============================================
+-- INGREDIENTES (USA)
+-- ============================================
+INSERT INTO parameters (category, code, name, icon, sort_order, config) VALUES
+-- Breads
+('ingredients_us', 'burger_bun', 'Burger Bun', '🍞', 1, '{"type": "bread", "cost": 1, "tier": "basic", "shelf_life_days": 3, "tags": ["base", "sandwich"]}'),
+('ingredients_us', 'hot_dog_bun', 'Hot Dog Bun', '🌭', 2, '{"type": "bread", "cost": 1, "tier": "basic", "shelf_life_days": 3, "tags": ["base", "sandwich"]}'),
+('ingredients_us', 'flour_tortilla', 'Flour Tortilla', '🫓', 3, '{"type": "bread", "cost": 1, "tier": "basic", "shelf_life_days": 3, "tags": ["base", "versatile"]}'),
+('ingredients_us', 'pretzel_dough', 'Pretzel Dough', '🥨', 4, '{"type": "dough", "cost": 1, "shelf_life_days": 3, "tags": ["snack", "common"]}'),
+
+-- Proteins
+('ingredients_us', 'beef_patty', 'Beef Patty', '🍔', 10, '{"type": "protein", "cost": 3, "shelf_life_days": 3, "tags": ["meat", "common"]}'),
+('ingredients_us', 'hot_dog', 'Hot Dog', '🌭', 11, '{"type": "protein", "cost": 2, "tier": "basic", "shelf_life_days": 3, "tags": ["meat", "base"]}'),
+('ingredients_us', 'chicken', 'Chicken', '🍗', 12, '{"type": "protein", "cost": 3, "shelf_life_days": 3, "tags": ["meat", "common"]}'),
+('ingredients_us', 'bacon', 'Bacon', '🥓', 13, '{"type": "protein", "cost": 3, "shelf_life_days": 3, "tags": ["meat", "breakfast"]}'),
+('ingredients_us', 'brisket', 'Brisket', '🥩', 14, '{"type": "protein", "cost": 8, "tier": "premium", "shelf_life_days": 3, "tags": ["meat", "premium", "bbq"]}'),
+('ingredients_us', 'shrimp', 'Shrimp', '🦐', 15, '{"type": "protein", "cost": 7, "tier": "premium", "shelf_life_days": 2, "tags": ["seafood", "premium"]}'),
+('ingredients_us', 'lobster', 'Lobster', '🦞', 16, '{"type": "protein", "cost": 15, "tier": "special", "shelf_life_days": 2, "tags": ["seafood", "premium", "coastal"]}'),
+
+-- Vegetables
+('ingredients_us', 'lettuce', 'Lettuce', '🥬', 20, '{"type": "vegetable", "cost": 1, "tier": "basic", "shelf_life_days": 5, "tags": ["fresh", "base"]}'),
+('ingredients_us', 'tomato', 'Tomato', '🍅', 21, '{"type": "vegetable", "cost": 1, "tier": "basic", "shelf_life_days": 5, "tags": ["fresh", "base"]}'),
+('ingredients_us', 'onion', 'Onion', '🧅', 22, '{"type": "vegetable", "cost": 1, "tier": "basic", "shelf_life_days": 5, "tags": ["aromatic", "base"]}'),
+('ingredients_us', 'potato', 'Potato', '🥔', 23, '{"type": "vegetable", "cost": 1, "tier": "basic", "shelf_life_days": 5, "tags": ["starchy", "base"]}'),
+('ingredients_us', 'jalapeno', 'Jalapeño', '🌶️', 24, '{"type": "vegetable", "cost": 1, "shelf_life_days": 5, "tags": ["spicy"]}'),
+('ingredients_us', 'avocado', 'Avocado', '🥑', 25, '{"type": "vegetable", "cost": 2, "tier": "premium", "shelf_life_days": 5, "tags": ["fresh", "premium"]}'),
+
+-- Dairy
+('ingredients_us', 'cheddar', 'Cheddar', '🧀', 30, '{"type": "dairy", "cost": 2, "shelf_life_days": 4, "tags": ["common", "melty"]}'),
+('ingredients_us', 'ice_cream', 'Ice Cream', '🍦', 31, '{"type": "dairy", "cost": 2, "shelf_life_days": 4, "tags": ["cold", "dessert"]}'),
+
+-- Condiments
+('ingredients_us', 'ketchup', 'Ketchup', '🥫', 40, '{"type": "condiment", "cost": 1, "tier": "basic", "tags": ["base", "essential"]}'),
+('ingredients_us', 'mustard', 'Mustard', '🫙', 41, '{"type": "condiment", "cost": 1, "tier": "basic", "tags": ["base", "essential"]}'),
+('ingredients_us', 'bbq_sauce', 'BBQ Sauce', '🫙', 42, '{"type": "condiment", "cost": 1, "tags": ["bbq", "sweet"]}'),
+('ingredients_us', 'pickles', 'Pickles', '🥒', 43, '{"type": "condiment", "cost": 1, "tags": ["acidic", "common"]}'),
+('ingredients_us', 'lemon', 'Lemon', '🍋', 44, '{"type": "citrus", "cost": 1, "tier": "basic", "shelf_life_days": 5, "tags": ["fresh", "acidic", "drink"]}'),
+
+-- Fruit and Drinks
+('ingredients_us', 'strawberry', 'Strawberry', '🍓', 50, '{"type": "fruit", "cost": 2, "shelf_life_days": 4, "tags": ["sweet", "dessert"]}'),
+('ingredients_us', 'coffee', 'Coffee Beans', '☕', 51, '{"type": "beverage", "cost": 2, "tags": ["drink", "breakfast"]}'),
+('ingredients_us', 'maple_syrup', 'Maple Syrup', '🍁', 52, '{"type": "sweetener", "cost": 3, "tier": "premium", "tags": ["sweet", "breakfast"]}')
+
+ON CONFLICT (category, code) DO NOTHING;
+
+-- ============================================
+-- FIN DE MIGRATION
+-- ============================================
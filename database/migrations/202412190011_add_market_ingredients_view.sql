@@ -0,0 +1,90 @@
+-- ============================================
+-- CalleViva - Market Ingredients View
+-- ============================================
+-- 202412190011_add_market_ingredients_view.sql
+-- El mercado vende los ingredientes del sistema (parameters) y los que hacen
+-- los creadores (content_creations de tipo ingredientes, ya aprobados).
+-- La vista los junta con la misma forma de parameters, así el catálogo, las
+-- compras, los precios del día y la simulación los tratan igual.
+--
+-- Receta de un ingrediente de creador:
+--   cost             costo en la moneda del mundo (obligatorio para venderse)
+--   tier             basic, common, premium, special (default common)
+--   shelf_life_days  días que dura (default según su categoría)
+--   origin           local → Costa Rica, mexican → México, american → USA;
+--                    cualquier otro origen se vende en Costa Rica
+-- Código en el mercado: creator_<id>
+
+CREATE INDEX IF NOT EXISTS idx_content_ingredients_approved ON content_creations(content_type, status)
+WHERE content_type = 'ingredientes' AND status = 'approved' AND is_active = true;
+
+CREATE OR REPLACE VIEW market_ingredients AS
+SELECT category, code, name, icon, config, is_active, sort_order
+FROM parameters
+WHERE category LIKE 'ingredients\_%'
+
+UNION ALL
+
+SELECT
+    'ingredients_' || CASE c.recipe->>'origin'
+        WHEN 'mexican' THEN 'mx'
+        WHEN 'american' THEN 'us'
+        ELSE 'cr'
+    END,
+    'creator_' || c.id,
+    c.name,
+    c.icon,
+    jsonb_strip_nulls(jsonb_build_object(
+        'type', c.type,
+        'cost', c.cost,
+        'tier', c.tier,
+        'tags', to_jsonb(array_remove(ARRAY['creator', c.recipe->>'category', c.recipe->>'flavor'], NULL)),
+        'icon', c.icon,
+        'shelf_life_days', COALESCE(c.shelf_life,
+            CASE
+                WHEN c.recipe->>'category' IN ('seafood', 'fish') THEN 2
+                WHEN c.type IN ('protein', 'herb', 'bread') THEN 3
+                WHEN c.type IN ('dairy', 'fruit') THEN 4
+                WHEN c.type = 'vegetable' THEN 5
+            END),
+        'source', 'creator',
+        'creator_name', c.creator_name,
+        'creation_id', c.id
+    )),
+    true,
+    1000
+FROM (
+    SELECT cc.id, cc.name, cc.creator_name, cc.recipe,
+           cc.recipe->>'icon' AS icon,
+           -- El creador guarda la receta como texto: '1200' o 1200 valen igual
+           CASE WHEN cc.recipe->>'cost' ~ '^[0-9]{1,7}(\.[0-9]+)?$'
+                THEN round((cc.recipe->>'cost')::numeric)::int END AS cost,
+           CASE WHEN cc.recipe->>'tier' IN ('basic', 'common', 'premium', 'special')
+                THEN cc.recipe->>'tier' ELSE 'common' END AS tier,
+           CASE WHEN cc.recipe->>'shelf_life_days' ~ '^[0-9]{1,7}(\.[0-9]+)?$'
+                THEN round((cc.recipe->>'shelf_life_days')::numeric)::int END AS shelf_life,
+           -- Las categorías del creador, llevadas a los tipos del mercado
+           CASE cc.recipe->>'category'
+               WHEN 'chicken' THEN 'protein'
+               WHEN 'fish' THEN 'protein'
+               WHEN 'seafood' THEN 'protein'
+               WHEN 'egg' THEN 'protein'
+               WHEN 'rice' THEN 'grain'
+               WHEN 'beans' THEN 'legume'
+               WHEN 'tortilla' THEN 'bread'
+               WHEN 'spice' THEN 'condiment'
+               WHEN 'sauce' THEN 'condiment'
+               WHEN 'oil' THEN 'condiment'
+               WHEN 'drink' THEN 'beverage'
+               WHEN 'coffee' THEN 'beverage'
+               WHEN 'alcohol' THEN 'beverage'
+               WHEN 'ice' THEN 'beverage'
+               WHEN 'sweet' THEN 'sweetener'
+               WHEN 'nut' THEN 'topping'
+               WHEN 'mushroom' THEN 'vegetable'
+               ELSE cc.recipe->>'category'
+           END AS type
+    FROM content_creations cc
+    WHERE cc.content_type = 'ingredientes' AND cc.status = 'approved' AND cc.is_active = true
+) c
+WHERE c.cost > 0;
@@ -0,0 +1,33 @@
+-- ============================================
+-- CalleViva - Truck Progression Migration
+-- ============================================
+-- 202412190012_add_truck_progression.sql
+-- El vehículo sube un escalón a la vez, en el orden de truck_types
+-- (carrito → puesto → food truck → restaurante móvil). Cada tipo trae sus
+-- stats base y lo que pide para comprarlo.
+--
+-- cost: precio del cambio al tipo
+-- min_day / min_reputation: requisitos para comprarlo
+-- speed: multiplicador de atención (clientes por hora); sube con cada escalón
+
+-- Los trucks que ya tenían puesto, food truck o restaurante pasan a la nueva
+-- velocidad, con sus mejoras encima (solo mientras el tipo tenga la vieja)
+UPDATE trucks t SET speed_multiplier = ROUND(t.speed_multiplier * v.new_speed / v.old_speed, 2)
+FROM (VALUES ('stand', 0.8, 1.15), ('truck', 1.2, 1.3), ('restaurant', 0.5, 1.5)) AS v(code, old_speed, new_speed)
+JOIN parameters p ON p.category = 'truck_types' AND p.code = v.code
+WHERE t.truck_type = v.code AND (p.config->>'speed')::numeric = v.old_speed;
+
+UPDATE parameters SET config = config || '{"speed": 1.15, "min_day": 3, "min_reputation": 20}'
+WHERE category = 'truck_types' AND code = 'stand';
+
+UPDATE parameters SET config = config || '{"speed": 1.3, "min_day": 7, "min_reputation": 40}'
+WHERE category = 'truck_types' AND code = 'truck';
+
+-- requires_reputation venía en escala de estrellas; ahora es min_reputation (0-100)
+UPDATE parameters SET config = (config - 'requires_reputation') || '{"speed": 1.5, "min_day": 14, "min_reputation": 70}'
+WHERE category = 'truck_types' AND code = 'restaurant';
+
+-- Nivel del truck = posición de su tipo en la escalera
+UPDATE trucks t SET level = p.sort_order
+FROM parameters p
+WHERE p.category = 'truck_types' AND p.code = t.truck_type;
@@ -0,0 +1,29 @@
+-- ============================================
+-- CalleViva - AI Template Profiles Migration
+-- ============================================
+-- 202412190013_add_ai_template_profiles.sql
+-- Cada plantilla de prompt puede tener su propio perfil: el code es el nombre
+-- de la plantilla y el config sus ajustes. Lo que no se define toma la
+-- configuración global de ai_config.
+--
+-- system_prompt: instrucciones de sistema de la plantilla
+-- provider: nombre del proveedor de la cadena que se prueba primero
+-- model: modelo para ese proveedor
+-- max_tokens / temperature: ajustes de generación
+-- cache: "" (según cache_enabled) u "off"
+-- cache_ttl_minutes: cuánto dura una respuesta en caché
+
+INSERT INTO parameters (category, code, name, description, config, is_active, sort_order)
+VALUES (
+    'ai_templates',
+    'dish_generation',
+    'Generación de platillos',
+    'Laboratorio de Sabores: nombre, historia y precio de un platillo',
+    '{
+        "max_tokens": 800,
+        "temperature": 0.9
+    }'::jsonb,
+    true,
+    1
+)
+ON CONFLICT (category, code) DO NOTHING;
@@ -0,0 +1,15 @@
+-- ============================================
+-- CalleViva - AI Cache Hits Migration
+-- ============================================
+-- 202412190014_ai_cache_hits.sql
+-- ai_cache pasa a ser la capa persistente del caché de IA (la memoria va
+-- adelante). hits cuenta las veces que una respuesta se sirvió del caché, así
+-- hits * tokens_used es lo que se ahorró.
+
+ALTER TABLE ai_cache ALTER COLUMN hits SET DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_ai_cache_prompt_type ON ai_cache(prompt_type);
+
+COMMENT ON TABLE ai_cache IS 'Caché persistente de respuestas de IA, por plantilla';
+COMMENT ON COLUMN ai_cache.hits IS 'Veces que la respuesta se sirvió del caché';
+COMMENT ON COLUMN ai_cache.tokens_used IS 'Tokens que costó generarla; cada hit los ahorra';
@@ -0,0 +1,31 @@
+-- ============================================
+-- CalleViva - Dialogue Pools Migration
+-- ============================================
+-- 202412190015_add_dialogue_pools.sql
+-- Caché de diálogos por contexto (GDD 5.4). Las frases de clientes se guardan
+-- por cubeta, p.ej. "churchill_happy_sunny_noon": con 3 o más se sirve una al
+-- azar y el pool se rellena en segundo plano hasta pool_size.
+--
+-- Cada frase es una fila de ai_cache; context->>'bucket' dice su cubeta.
+
+CREATE INDEX IF NOT EXISTS idx_ai_cache_bucket ON ai_cache(prompt_type, (context->>'bucket'))
+WHERE context ? 'bucket';
+
+-- cache "pool": frases por cubeta en vez de una respuesta por prompt
+INSERT INTO parameters (category, code, name, description, config, is_active, sort_order)
+VALUES (
+    'ai_templates',
+    'customer_line',
+    'Frase de cliente',
+    'Lo que dice un cliente al comprar, según producto, satisfacción, clima y hora',
+    '{
+        "max_tokens": 40,
+        "temperature": 0.9,
+        "cache": "pool",
+        "pool_size": 10,
+        "cache_ttl_minutes": 10080
+    }'::jsonb,
+    true,
+    2
+)
+ON CONFLICT (category, code) DO NOTHING;
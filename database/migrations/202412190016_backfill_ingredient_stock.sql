@@ -0,0 +1,40 @@
+-- ============================================
+-- CalleViva - Ingredient Stock Backfill Migration
+-- ============================================
+-- 202412190016_backfill_ingredient_stock.sql
+-- Los ingredientes comprados cuando se desbloqueaban una sola vez quedaron
+-- con quantity 0 y sin lotes. Cada uno recibe un lote inicial de 20
+-- unidades (un día de carrito) al costo del mercado, fresco desde el día
+-- actual de la partida. El costo y la vida útil salen del catálogo del
+-- mundo de la partida (o del de Costa Rica, el catálogo por defecto).
+
+INSERT INTO inventory (session_id, item_type, quantity, cost_per_unit, acquired_day, expires_day)
+SELECT pi.session_id, pi.ingredient_code, 20,
+       COALESCE(m.cost, 0),
+       g.game_day,
+       g.game_day + m.shelf_life_days
+FROM player_ingredients pi
+JOIN game_sessions g ON g.id = pi.session_id
+LEFT JOIN LATERAL (
+    SELECT (config->>'cost')::int AS cost, (config->>'shelf_life_days')::int AS shelf_life_days
+    FROM market_ingredients
+    WHERE code = pi.ingredient_code
+      AND category IN (
+          'ingredients_' || CASE g.world_type WHEN 'mexico' THEN 'mx' WHEN 'usa' THEN 'us' ELSE 'cr' END,
+          'ingredients_cr'
+      )
+    ORDER BY category = 'ingredients_cr', is_active DESC
+    LIMIT 1
+) m ON true
+WHERE pi.quantity = 0
+  AND NOT EXISTS (
+      SELECT 1 FROM inventory i
+      WHERE i.session_id = pi.session_id AND i.item_type = pi.ingredient_code
+  );
+
+-- El stock total vuelve a ser la suma de los lotes
+UPDATE player_ingredients pi
+SET quantity = COALESCE((
+    SELECT SUM(i.quantity) FROM inventory i
+    WHERE i.session_id = pi.session_id AND i.item_type = pi.ingredient_code
+), 0);
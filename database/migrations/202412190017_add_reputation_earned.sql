@@ -0,0 +1,9 @@
+-- ============================================
+-- CalleViva - Reputation Earned Migration
+-- ============================================
+-- 202412190017_add_reputation_earned.sql
+-- reputation_change se guarda después de limitar a 0-100, así que un
+-- jugador en 100 registra 0 y pierde la racha. reputation_earned guarda lo
+-- ganado antes de limitar; los días viejos usan reputation_change.
+
+ALTER TABLE day_summaries ADD COLUMN IF NOT EXISTS reputation_earned INT;
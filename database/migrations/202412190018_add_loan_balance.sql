@@ -0,0 +1,24 @@
+-- ============================================
+-- CalleViva - Loan Balance Migration
+-- ============================================
+-- 202412190018_add_loan_balance.sql
+-- Cada préstamo lleva lo que falta pagar y el día en que se terminó de
+-- pagar, en lugar de quedar solo en game_sessions.loan_balance.
+-- balance: lo que falta pagar del préstamo
+-- paid_off_day: día en que el saldo llegó a 0 (NULL mientras se debe y en
+-- los préstamos pagados antes de esta migración)
+ALTER TABLE loans ADD COLUMN IF NOT EXISTS balance BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE loans ADD COLUMN IF NOT EXISTS paid_off_day INT;
+
+-- Solo se puede deber un préstamo a la vez: el último lleva el saldo de la
+-- partida y los anteriores quedan pagados
+UPDATE loans l
+SET balance = CASE WHEN l.id = last.id THEN COALESCE(g.loan_balance, 0) ELSE 0 END
+FROM game_sessions g,
+     LATERAL (
+         SELECT id FROM loans
+         WHERE session_id = g.id
+         ORDER BY game_day DESC, created_at DESC, id
+         LIMIT 1
+     ) last
+WHERE l.session_id = g.id;
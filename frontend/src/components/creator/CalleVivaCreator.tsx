@@ -221,6 +221,10 @@ const CATALOGS: Catalogs = {
       { id: 'fried', name: 'Frito' }, { id: 'grilled', name: 'A la Parrilla' }, { id: 'raw', name: 'Crudo' },
       { id: 'dried', name: 'Seco' }, { id: 'canned', name: 'Enlatado' }, { id: 'pickled', name: 'Encurtido' },
     ],
+    tier: [
+      { id: 'common', name: 'Común' }, { id: 'basic', name: 'Básico' },
+      { id: 'premium', name: 'Premium' }, { id: 'special', name: 'Especial' },
+    ],
   },
 
   artefactos: {
@@ -848,6 +852,7 @@ const Editor: React.FC<EditorProps> = ({ type, catalog, SVGComponent, onSave, on
       ['Tipo', ['category', 'origin']],
       ['Apariencia', ['shape', 'color']],
       ['Sabor', ['flavor', 'state']],
+      ['Mercado', ['tier']],
     ],
     artefactos: [
       ['Tipo', ['type', 'material']],
@@ -866,6 +871,10 @@ const Editor: React.FC<EditorProps> = ({ type, catalog, SVGComponent, onSave, on
           placeholder="Nombre..." className="w-full mt-2 px-3 py-2 rounded-lg border border-gray-200 text-sm" />
         <textarea value={data.description} onChange={e => update('description', e.target.value)}
           placeholder="Descripción..." className="w-full mt-2 px-3 py-2 rounded-lg border border-gray-200 text-sm resize-none" rows={2} />
+        {type === 'ingredientes' && (
+          <input type="number" min={1} value={data.cost || ''} onChange={e => update('cost', e.target.value)}
+            placeholder="Costo en el mercado (moneda del origen)..." className="w-full mt-2 px-3 py-2 rounded-lg border border-gray-200 text-sm" />
+        )}
         <div className="flex gap-2 mt-2">
           <button onClick={() => data.name && onSaveToGallery({ type, data })}
             className="flex-1 py-2 bg-blue-500 hover:bg-blue-600 text-white font-bold rounded-lg text-sm">
@@ -4,6 +4,7 @@
 import { useState, useEffect } from 'react'
 import { useNavigate, useParams } from 'react-router-dom'
 import { api, GameSession } from '../../services/api'
+import { truckApi } from '../../services/truckApi'
 
 interface TruckConfig {
   type: string
@@ -19,6 +20,7 @@ export function GamePlay() {
   const [game, setGame] = useState<GameSession | null>(null)
   const [loading, setLoading] = useState(true)
   const [truckConfig, setTruckConfig] = useState<TruckConfig | null>(null)
+  const [truckType, setTruckType] = useState('')
 
   useEffect(() => {
     if (gameId) {
@@ -39,6 +41,11 @@ export function GamePlay() {
         return
       }
       setTruckConfig(stats.truck)
+
+      // The vehicle is the server's, not the one picked at setup
+      truckApi.getTruck(gameId!)
+        .then(res => setTruckType(res.truck.truck_type))
+        .catch(() => setTruckType(stats?.truck?.type || ''))
     } catch (err) {
       console.error(err)
       navigate('/game')
@@ -158,10 +165,10 @@ export function GamePlay() {
         <div className="bg-white rounded-2xl shadow-lg p-6">
           <div className="flex items-center gap-6">
             <div className="text-6xl">
-              {truckConfig?.type === 'cart' && '🛒'}
-              {truckConfig?.type === 'stand' && '🏪'}
-              {truckConfig?.type === 'truck' && '🚚'}
-              {truckConfig?.type === 'restaurant' && '🍽️'}
+              {truckType === 'cart' && '🛒'}
+              {truckType === 'stand' && '🏪'}
+              {truckType === 'truck' && '🚚'}
+              {truckType === 'restaurant' && '🍽️'}
             </div>
             <div className="flex-1">
               <h2 className="text-xl font-bold text-carbon">{truckConfig?.name}</h2>
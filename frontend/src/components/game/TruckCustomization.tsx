@@ -5,27 +5,21 @@ import { useState, useEffect } from 'react'
 import { useNavigate, useParams } from 'react-router-dom'
 import { api, GameSession, Parameter } from '../../services/api'
 import { labApi, PlayerDish } from '../../services/labApi'
+import { truckApi, TruckResponse, UpgradesResponse } from '../../services/truckApi'
 import { TruckViewer3D, TruckCustomization as TruckConfig3D } from './CustomizableTruck3D'
 
 type TabType = 'vehicle' | 'equipment' | 'appearance' | 'menu'
 
+// The truck's look. The vehicle and its equipment live on the server
+// (/trucks and /upgrades); the look is free to change.
 interface TruckConfig {
-  type: string
   name: string
   products: string[]
   theme: string
   decorations: string[]
-  equipment: string[]
   style: string
 }
 
-interface TruckStats {
-  capacity: number
-  speed: number
-  appeal: number
-  weatherProtection: number
-}
-
 export function TruckCustomization() {
   const navigate = useNavigate()
   const { gameId } = useParams<{ gameId: string }>()
@@ -36,8 +30,6 @@ export function TruckCustomization() {
   const [activeTab, setActiveTab] = useState<TabType>('vehicle')
 
   // Parameters from DB
-  const [truckTypes, setTruckTypes] = useState<Parameter[]>([])
-  const [equipment, setEquipment] = useState<Parameter[]>([])
   const [decorations, setDecorations] = useState<Parameter[]>([])
   const [colors, setColors] = useState<Parameter[]>([])
   const [styles, setStyles] = useState<Parameter[]>([])
@@ -45,14 +37,17 @@ export function TruckCustomization() {
   const [, setRecipeUpgrades] = useState<Parameter[]>([])
   const [labDishes, setLabDishes] = useState<PlayerDish[]>([])
 
+  // Vehicle and equipment from the server
+  const [truck, setTruck] = useState<TruckResponse | null>(null)
+  const [shop, setShop] = useState<UpgradesResponse | null>(null)
+  const [purchaseError, setPurchaseError] = useState('')
+
   // Current config
   const [config, setConfig] = useState<TruckConfig>({
-    type: 'cart',
     name: 'Mi Food Truck',
     products: [],
     theme: 'coral',
     decorations: [],
-    equipment: [],
     style: 'classic',
   })
 
@@ -69,9 +64,10 @@ export function TruckCustomization() {
       const worldSuffix = gameData.world_type === 'costa_rica' ? 'cr' :
                           gameData.world_type === 'mexico' ? 'mx' : 'us'
 
-      const [typesRes, equipRes, decoRes, colorsRes, stylesRes, productsRes, recipesRes] = await Promise.all([
-        api.parameters.list('truck_types'),
-        api.parameters.list('equipment'),
+      const [truckRes, shopRes, decoRes, colorsRes, stylesRes, productsRes, recipesRes] = await Promise.all([
+        // Games from before trucks existed get theirs with the first menu
+        truckApi.getTruck(gameId!).catch(() => null),
+        truckApi.getUpgrades(gameId!).catch(() => null),
         api.parameters.list('decorations'),
         api.parameters.list('colors'),
         api.parameters.list('styles'),
@@ -79,8 +75,8 @@ export function TruckCustomization() {
         api.parameters.list('recipe_upgrades'),
       ])
 
-      setTruckTypes(typesRes.parameters)
-      setEquipment(equipRes.parameters)
+      setTruck(truckRes)
+      setShop(shopRes)
       setDecorations(decoRes.parameters.filter(d => {
         const cfg = d.config as { country?: string }
         return !cfg.country || cfg.country === gameData.world_type
@@ -97,12 +93,10 @@ export function TruckCustomization() {
       const stats = gameData.stats as { truck?: TruckConfig } | null
       if (stats?.truck) {
         setConfig({
-          type: stats.truck.type || 'cart',
           name: stats.truck.name || 'Mi Food Truck',
           products: stats.truck.products || [],
           theme: stats.truck.theme || 'coral',
           decorations: stats.truck.decorations || [],
-          equipment: stats.truck.equipment || [],
           style: stats.truck.style || 'classic',
         })
       }
@@ -121,97 +115,60 @@ export function TruckCustomization() {
     }
   }
 
-  const calculateStats = (): TruckStats => {
-    const vehicleType = truckTypes.find(t => t.code === config.type)
-    const vehicleConfig = vehicleType?.config as { capacity?: number; speed?: number; appeal_base?: number } || {}
-
-    let capacity = vehicleConfig.capacity || 20
-    let speed = (vehicleConfig.speed || 1) * 100
-    let appeal = vehicleConfig.appeal_base || 30
-    let weatherProtection = 0
-
-    // Add equipment bonuses
-    config.equipment.forEach(eqCode => {
-      const eq = equipment.find(e => e.code === eqCode)
-      const eqConfig = eq?.config as { capacity_bonus?: number; speed_bonus?: number; weather_protection?: number } || {}
-      capacity += (eqConfig.capacity_bonus || 0) * capacity / 100
-      speed += eqConfig.speed_bonus || 0
-      weatherProtection += eqConfig.weather_protection || 0
-    })
-
-    // Add decoration bonuses
-    config.decorations.forEach(decCode => {
-      const dec = decorations.find(d => d.code === decCode)
-      const decConfig = dec?.config as { appeal_bonus?: number } || {}
-      appeal += decConfig.appeal_bonus || 0
-    })
-
-    // Add style bonus
-    const styleParam = styles.find(s => s.code === config.style)
-    const styleConfig = styleParam?.config as { appeal_bonus?: number } || {}
-    appeal += styleConfig.appeal_bonus || 0
-
-    return {
-      capacity: Math.round(capacity),
-      speed: Math.min(Math.round(speed), 200),
-      appeal: Math.min(Math.round(appeal), 100),
-      weatherProtection: Math.min(Math.round(weatherProtection), 100),
-    }
+  const meetsReputation = (required?: number): boolean => {
+    if (!required) return true
+    return (game?.reputation || 0) >= required * 20
   }
 
-  const calculateValue = (): number => {
-    let value = 0
-
-    // Vehicle cost
-    const vehicleType = truckTypes.find(t => t.code === config.type)
-    value += (vehicleType?.config as { cost?: number })?.cost || 0
-
-    // Equipment costs
-    config.equipment.forEach(eqCode => {
-      const eq = equipment.find(e => e.code === eqCode)
-      value += (eq?.config as { cost?: number })?.cost || 0
-    })
-
-    // Decoration costs
-    config.decorations.forEach(decCode => {
-      const dec = decorations.find(d => d.code === decCode)
-      value += (dec?.config as { cost?: number })?.cost || 0
-    })
-
-    // Style cost
-    const styleParam = styles.find(s => s.code === config.style)
-    value += (styleParam?.config as { cost?: number })?.cost || 0
-
-    // Color cost
-    const colorParam = colors.find(c => c.code === config.theme)
-    value += (colorParam?.config as { cost?: number })?.cost || 0
+  // Moves the truck one step up the ladder; the server charges it
+  const handleUpgradeVehicle = async () => {
+    if (saving) return
 
-    return value
+    setSaving(true)
+    setPurchaseError('')
+    try {
+      const res = await truckApi.upgradeTruck(gameId!)
+      setGame(g => g && { ...g, money: res.new_money })
+      const [truckRes, shopRes] = await Promise.all([
+        truckApi.getTruck(gameId!),
+        truckApi.getUpgrades(gameId!),
+      ])
+      setTruck(truckRes)
+      setShop(shopRes)
+    } catch (err) {
+      setPurchaseError(err instanceof Error ? err.message : 'Error al comprar')
+    } finally {
+      setSaving(false)
+    }
   }
 
-  const canAfford = (cost: number): boolean => {
-    return (game?.money || 0) >= cost
-  }
+  // Buys the next level of an equipment item; the server charges it
+  const handleBuyEquipment = async (upgradeId: string) => {
+    if (saving) return
 
-  const meetsReputation = (required?: number): boolean => {
-    if (!required) return true
-    return (game?.reputation || 0) >= required * 20
+    setSaving(true)
+    setPurchaseError('')
+    try {
+      const res = await truckApi.buyUpgrade(gameId!, upgradeId)
+      setGame(g => g && { ...g, money: res.new_money })
+      setShop(await truckApi.getUpgrades(gameId!))
+      setTruck(t => t && { ...t, truck: { ...t.truck, stats: res.truck } })
+    } catch (err) {
+      setPurchaseError(err instanceof Error ? err.message : 'Error al comprar')
+    } finally {
+      setSaving(false)
+    }
   }
 
-  const handlePurchase = async (type: 'vehicle' | 'equipment' | 'decoration' | 'style' | 'color', code: string, cost: number) => {
-    if (!canAfford(cost) || saving) return
+  // Changes the look: cosmetic items are free
+  const handleLookChange = async (type: 'decoration' | 'style' | 'color', code: string) => {
+    if (saving) return
 
     setSaving(true)
     try {
-      let newConfig = { ...config }
+      const newConfig = { ...config }
 
-      if (type === 'vehicle') {
-        newConfig.type = code
-      } else if (type === 'equipment') {
-        if (!newConfig.equipment.includes(code)) {
-          newConfig.equipment = [...newConfig.equipment, code]
-        }
-      } else if (type === 'decoration') {
+      if (type === 'decoration') {
         if (newConfig.decorations.includes(code)) {
           newConfig.decorations = newConfig.decorations.filter(d => d !== code)
         } else {
@@ -223,24 +180,26 @@ export function TruckCustomization() {
         newConfig.theme = code
       }
 
-      // Update game
-      const currentStats = (game?.stats || {}) as Record<string, unknown>
-      const newMoney = (game?.money || 0) - cost
-
-      await api.games.update(gameId!, {
-        money: newMoney,
-        stats: { ...currentStats, truck: newConfig },
+      const updated = await api.games.update(gameId!, {
+        stats: { truck: lookStats(newConfig) },
       })
 
       setConfig(newConfig)
-      setGame(g => g ? { ...g, money: newMoney, stats: { ...currentStats, truck: newConfig } } : null)
+      setGame(updated)
     } catch (err) {
-      console.error('Error purchasing:', err)
+      console.error('Error saving look:', err)
     } finally {
       setSaving(false)
     }
   }
 
+  // stats.truck with the new look. type only marks that setup is done: the
+  // vehicle itself is the one /trucks returns.
+  const lookStats = (look: TruckConfig) => {
+    const current = (game?.stats as { truck?: { type?: string } } | null)?.truck
+    return { type: current?.type, ...look }
+  }
+
   const handleNameChange = async (name: string) => {
     setConfig(c => ({ ...c, name }))
   }
@@ -260,10 +219,10 @@ export function TruckCustomization() {
     if (saving) return
     setSaving(true)
     try {
-      const currentStats = (game?.stats || {}) as Record<string, unknown>
-      await api.games.update(gameId!, {
-        stats: { ...currentStats, truck: config },
+      const updated = await api.games.update(gameId!, {
+        stats: { truck: lookStats(config) },
       })
+      setGame(updated)
     } catch (err) {
       console.error('Error saving:', err)
     } finally {
@@ -271,8 +230,7 @@ export function TruckCustomization() {
     }
   }
 
-  const stats = calculateStats()
-  const truckValue = calculateValue()
+  const stats = truck?.truck.stats
 
   const getCurrency = () => game?.world_type === 'costa_rica' ? '₡' : '$'
   const formatMoney = (amount: number) => `${getCurrency()}${amount.toLocaleString()}`
@@ -383,16 +341,16 @@ export function TruckCustomization() {
               <div className="lg:w-72 p-4 flex flex-col justify-center">
                 {/* Live Stats - 2x2 grid */}
                 <div className="grid grid-cols-2 gap-3 mb-3">
-                  <StatBar label="Capacidad" value={stats.capacity} max={150} color="coral" />
-                  <StatBar label="Velocidad" value={stats.speed} max={200} color="agua" />
-                  <StatBar label="Atractivo" value={stats.appeal} max={100} color="mango" />
-                  <StatBar label="Protección" value={stats.weatherProtection} max={100} color="purple" />
+                  <StatBar label="Capacidad" value={stats?.capacity || 0} max={150} color="coral" />
+                  <StatBar label="Velocidad" value={Math.round((stats?.speed || 0) * 100)} max={200} color="agua" />
+                  <StatBar label="Paciencia" value={Math.round((stats?.queue_tolerance || 0) * 100)} max={200} color="mango" />
+                  <StatBar label="Precisión" value={Math.round((1 - (stats?.checkout_errors || 0)) * 100)} max={100} color="purple" />
                 </div>
 
-                {/* Value */}
+                {/* Vehicle */}
                 <div className="pt-3 border-t border-gray-100 text-center">
-                  <div className="text-xs text-gray-500 font-bold uppercase">Valor del Negocio</div>
-                  <div className="text-xl font-black text-green-600">{formatMoney(truckValue)}</div>
+                  <div className="text-xs text-gray-500 font-bold uppercase">Vehículo</div>
+                  <div className="text-xl font-black text-green-600">{truck?.truck.type_name || '—'}</div>
                 </div>
               </div>
             </div>
@@ -434,19 +392,17 @@ export function TruckCustomization() {
                     <p className="text-gray-500">La base de tu imperio móvil.</p>
                   </div>
 
+                  {purchaseError && (
+                    <div className="mb-4 bg-red-50 text-red-600 px-4 py-3 rounded-xl font-bold text-sm">{purchaseError}</div>
+                  )}
+
                   <div className="grid grid-cols-1 md:grid-cols-2 gap-4 lg:gap-6">
-                    {truckTypes.map(type => {
-                      const typeConfig = type.config as {
-                        capacity?: number
-                        speed?: number
-                        cost?: number
-                        requires_reputation?: number
-                        emoji?: string
-                      }
-                      const isOwned = config.type === type.code
-                      const cost = typeConfig.cost || 0
-                      const canBuy = canAfford(cost) && meetsReputation(typeConfig.requires_reputation)
-                      const isLocked = typeConfig.requires_reputation && !meetsReputation(typeConfig.requires_reputation)
+                    {(truck?.path || []).map(type => {
+                      const current = truck?.truck.level || 0
+                      const isOwned = type.rank <= current
+                      const isCurrent = type.code === truck?.truck.truck_type
+                      const next = truck?.next?.code === type.code ? truck?.next : undefined
+                      const isLocked = !isOwned && !next?.available
 
                       return (
                         <div
@@ -456,51 +412,47 @@ export function TruckCustomization() {
                               ? 'owned'
                               : isLocked
                               ? 'locked'
-                              : canBuy
-                              ? 'hover:shadow-xl cursor-pointer'
-                              : ''
+                              : 'hover:shadow-xl cursor-pointer'
                           }`}
                         >
                           {/* Locked Overlay */}
                           {isLocked && (
                             <div className="absolute inset-0 bg-white/60 backdrop-blur-sm z-10 rounded-2xl flex items-center justify-center">
                               <div className="bg-gray-900 text-white px-4 py-2 rounded-lg font-bold text-sm shadow-xl">
-                                🔒 Rep. {typeConfig.requires_reputation} Requerida
+                                🔒 {next?.reason || 'Primero el vehículo anterior'}
                               </div>
                             </div>
                           )}
 
                           {/* Status Badge */}
-                          {isOwned && (
+                          {isCurrent && (
                             <span className="absolute top-4 right-4 bg-agua/20 text-agua px-3 py-1 rounded-full text-xs font-bold">
-                              EN PROPIEDAD
+                              EN USO
                             </span>
                           )}
-                          {!isOwned && !isLocked && cost === 0 && (
-                            <span className="absolute top-4 right-4 bg-green-100 text-green-600 px-3 py-1 rounded-full text-xs font-bold">
-                              ¡GRATIS!
+                          {isOwned && !isCurrent && (
+                            <span className="absolute top-4 right-4 bg-gray-100 text-gray-500 px-3 py-1 rounded-full text-xs font-bold">
+                              SUPERADO
                             </span>
                           )}
 
-                          <div className="text-6xl mb-4">{typeConfig.emoji || type.icon}</div>
+                          <div className="text-6xl mb-4">{type.icon}</div>
                           <h3 className="font-bold text-xl mb-1">{type.name}</h3>
-                          <p className="text-sm text-gray-500 mb-4">{type.description}</p>
 
                           <div className="flex items-center gap-2 text-xs font-bold text-gray-400 mb-4">
-                            <span className="bg-gray-100 px-2 py-1 rounded">Cap: {typeConfig.capacity}</span>
-                            <span className="bg-gray-100 px-2 py-1 rounded">Vel: {(typeConfig.speed || 1) * 100}%</span>
+                            <span className="bg-gray-100 px-2 py-1 rounded">Cap: {type.capacity}</span>
+                            <span className="bg-gray-100 px-2 py-1 rounded">Vel: {Math.round(type.speed * 100)}%</span>
+                            <span className="bg-gray-100 px-2 py-1 rounded">Equipo: {type.max_equipment}</span>
                           </div>
 
-                          {!isOwned && !isLocked && (
+                          {next && (
                             <div className="flex justify-between items-center pt-4 border-t border-gray-100">
-                              <div className="text-xl font-black text-coral">
-                                {cost === 0 ? '¡Gratis!' : formatMoney(cost)}
-                              </div>
+                              <div className="text-xl font-black text-coral">{formatMoney(type.cost)}</div>
                               <button
-                                onClick={() => handlePurchase('vehicle', type.code, cost)}
-                                disabled={!canBuy || saving}
+                                onClick={handleUpgradeVehicle}
+                                disabled={!next.available || saving}
                                 className={`px-4 py-2 rounded-lg font-bold transition-colors ${
-                                  canBuy
+                                  next.available
                                     ? 'bg-coral text-white hover:bg-red-500'
                                     : 'bg-gray-200 text-gray-500 cursor-not-allowed'
                                 }`}
@@ -524,41 +476,46 @@ export function TruckCustomization() {
                     <p className="text-gray-500">Mejora tu cocina y capacidad operativa.</p>
                   </div>
 
+                  {purchaseError && (
+                    <div className="mb-4 bg-red-50 text-red-600 px-4 py-3 rounded-xl font-bold text-sm">{purchaseError}</div>
+                  )}
+
                   <div className="grid grid-cols-2 lg:grid-cols-3 gap-4">
-                    {equipment.filter(e => !(e.config as { included?: boolean })?.included).map(eq => {
-                      const eqConfig = eq.config as { cost?: number; description?: string }
-                      const isOwned = config.equipment.includes(eq.code)
-                      const cost = eqConfig.cost || 0
-                      const canBuy = canAfford(cost) && !isOwned
+                    {(shop?.upgrades || []).filter(u => u.category === 'equipment').map(eq => {
+                      const isMaxed = eq.level >= eq.max_level
 
                       return (
                         <div
-                          key={eq.code}
+                          key={eq.id}
                           className={`upgrade-card rounded-2xl p-5 text-center transition-all ${
-                            isOwned ? 'owned' : canBuy ? '' : 'opacity-60'
+                            eq.level > 0 ? 'owned' : eq.available ? '' : 'opacity-60'
                           }`}
                         >
                           <div className="text-5xl mb-3">{eq.icon}</div>
                           <h3 className="font-bold text-lg">{eq.name}</h3>
+                          {eq.max_level > 1 && (
+                            <p className="text-xs font-bold text-gray-400">Nivel {eq.level}/{eq.max_level}</p>
+                          )}
 
-                          {isOwned ? (
+                          {isMaxed ? (
                             <span className="inline-block mt-2 bg-agua/20 text-agua px-3 py-1 rounded-full text-xs font-bold">
                               COMPRADO
                             </span>
                           ) : (
                             <>
-                              <p className="text-xs text-gray-500 mt-2 mb-3">{eqConfig.description}</p>
-                              <div className="text-xl font-black text-coral mb-2">{formatMoney(cost)}</div>
+                              <p className="text-xs text-gray-500 mt-2 mb-3">{eq.description}</p>
+                              <div className="text-xl font-black text-coral mb-2">{formatMoney(eq.next_cost || 0)}</div>
                               <button
-                                onClick={() => canBuy && handlePurchase('equipment', eq.code, cost)}
-                                disabled={!canBuy || saving}
+                                onClick={() => eq.available && handleBuyEquipment(eq.id)}
+                                disabled={!eq.available || saving}
+                                title={eq.reason}
                                 className={`w-full py-2 rounded-lg font-bold text-sm transition-colors ${
-                                  canBuy
+                                  eq.available
                                     ? 'bg-coral text-white hover:bg-red-500'
                                     : 'bg-gray-200 text-gray-500 cursor-not-allowed'
                                 }`}
                               >
-                                Comprar
+                                {eq.available ? 'Comprar' : eq.reason}
                               </button>
                             </>
                           )}
@@ -602,21 +559,20 @@ export function TruckCustomization() {
                       </label>
                       <div className="flex gap-3 flex-wrap">
                         {colors.map(color => {
-                          const colorConfig = color.config as { hex?: string; cost?: number }
+                          const colorConfig = color.config as { hex?: string }
                           const isSelected = config.theme === color.code
-                          const cost = colorConfig.cost || 0
 
                           return (
                             <button
                               key={color.code}
-                              onClick={() => !isSelected && handlePurchase('color', color.code, cost)}
+                              onClick={() => !isSelected && handleLookChange('color', color.code)}
                               className={`w-11 h-11 rounded-full transition-all hover:scale-110 border-2 ${
                                 isSelected
                                   ? 'ring-2 ring-offset-2 ring-gray-800 scale-110 border-white'
                                   : 'border-transparent hover:border-white'
                               }`}
                               style={{ backgroundColor: colorConfig.hex }}
-                              title={`${color.name} - ${cost === 0 ? 'Gratis' : formatMoney(cost)}`}
+                              title={color.name}
                             />
                           )
                         })}
@@ -629,14 +585,12 @@ export function TruckCustomization() {
                     <h3 className="font-bold text-lg mb-4">Accesorios y Decoración</h3>
                     <div className="grid grid-cols-4 sm:grid-cols-6 lg:grid-cols-8 gap-3">
                       {decorations.map(dec => {
-                        const decConfig = dec.config as { cost?: number }
                         const isSelected = config.decorations.includes(dec.code)
-                        const cost = isSelected ? 0 : (decConfig.cost || 0)
 
                         return (
                           <button
                             key={dec.code}
-                            onClick={() => handlePurchase('decoration', dec.code, cost)}
+                            onClick={() => handleLookChange('decoration', dec.code)}
                             className={`accessory-btn p-4 rounded-xl border-2 transition-all flex flex-col items-center gap-1 group ${
                               isSelected
                                 ? 'border-coral bg-coral/10 shadow-md'
@@ -660,14 +614,14 @@ export function TruckCustomization() {
                     <h3 className="font-bold text-lg mb-4">Estilo Visual</h3>
                     <div className="grid grid-cols-2 md:grid-cols-4 gap-3">
                       {styles.map(style => {
-                        const styleConfig = style.config as { cost?: number; requires_reputation?: number }
+                        const styleConfig = style.config as { requires_reputation?: number }
                         const isSelected = config.style === style.code
                         const canBuy = meetsReputation(styleConfig.requires_reputation)
 
                         return (
                           <button
                             key={style.code}
-                            onClick={() => canBuy && !isSelected && handlePurchase('style', style.code, styleConfig.cost || 0)}
+                            onClick={() => canBuy && !isSelected && handleLookChange('style', style.code)}
                             disabled={!canBuy}
                             className={`p-4 rounded-xl border-2 text-sm font-bold transition-all ${
                               isSelected
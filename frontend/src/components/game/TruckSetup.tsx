@@ -104,13 +104,9 @@ export function TruckSetup() {
     setError('')
 
     try {
-      const currentStats = (game?.stats || {}) as Record<string, unknown>
       await api.games.update(gameId, {
         name: config.name || game?.name,
-        stats: {
-          ...currentStats,
-          truck: config,
-        },
+        stats: { truck: config },
       })
       navigate(`/game/${gameId}/play`)
     } catch (err) {
@@ -213,7 +209,8 @@ export function TruckSetup() {
                 {truckTypes.map(type => {
                   const typeConfig = type.config as { capacity?: number; cost?: number }
                   const isSelected = config.type === type.code
-                  const canAfford = (typeConfig?.cost || 0) <= (game?.money || 0)
+                  // Every game starts with the free vehicle; the rest are bought in the Taller
+                  const canAfford = (typeConfig?.cost || 0) === 0
 
                   return (
                     <button
@@ -237,8 +234,8 @@ export function TruckSetup() {
                       </div>
                       <div className="text-right">
                         {(typeConfig?.cost || 0) > 0 ? (
-                          <div className={`font-bold ${canAfford ? 'text-hoja' : 'text-red-500'}`}>
-                            ${(typeConfig?.cost || 0).toLocaleString()}
+                          <div className="text-sm font-bold text-gray-400">
+                            🔒 En el Taller
                           </div>
                         ) : (
                           <div className="text-hoja font-bold">¡Gratis!</div>
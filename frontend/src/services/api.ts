@@ -178,15 +178,10 @@ interface CreateGame {
   name?: string
 }
 
+// Only cosmetic fields: money, day and the rest change through server actions
 interface UpdateGame {
   name?: string
-  game_day?: number
-  money?: number
-  reputation?: number
-  current_location?: string
-  weather?: string
-  status?: string
-  stats?: Record<string, unknown>
+  stats?: { truck?: unknown }
 }
 
 export type { Parameter, ParametersResponse, CreateParameter, UpdateParameter, GameSession, GamesResponse, CreateGame, UpdateGame }
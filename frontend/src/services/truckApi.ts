@@ -0,0 +1,130 @@
+// Vehículo y Mejoras API Service
+
+const API_BASE = import.meta.env.VITE_API_URL || '/api/v1'
+
+// Types
+export interface TruckStats {
+  capacity: number
+  speed: number
+  queue_tolerance: number
+  checkout_errors: number
+}
+
+export interface Truck {
+  id: string
+  name: string
+  truck_type: string
+  type_name: string
+  level: number
+  stats: TruckStats
+}
+
+export interface TruckType {
+  code: string
+  name: string
+  icon?: string
+  rank: number
+  capacity: number
+  speed: number
+  cost: number
+  max_equipment: number
+  min_day?: number
+  min_reputation?: number
+}
+
+export interface NextTruckType extends TruckType {
+  available: boolean
+  reason?: string
+}
+
+export interface TruckResponse {
+  truck: Truck
+  next?: NextTruckType
+  path: TruckType[]
+  money: number
+}
+
+export interface TruckUpgradeResponse {
+  success: boolean
+  message: string
+  truck: Truck
+  cost_paid: number
+  new_money: number
+}
+
+export interface ShopUpgrade {
+  id: string
+  category: 'upgrades' | 'equipment'
+  name: string
+  icon: string
+  description: string
+  level: number
+  max_level: number
+  next_cost?: number
+  available: boolean
+  reason?: string
+}
+
+export interface UpgradesResponse {
+  upgrades: ShopUpgrade[]
+  truck: TruckStats
+  truck_type: string
+  money: number
+}
+
+export interface BuyUpgradeResponse {
+  success: boolean
+  upgrade_id: string
+  level: number
+  cost_paid: number
+  new_money: number
+  truck: TruckStats
+}
+
+// Helper for authenticated requests
+async function request<T>(endpoint: string, options?: RequestInit): Promise<T> {
+  const token = localStorage.getItem('token')
+
+  const headers: HeadersInit = {
+    'Content-Type': 'application/json',
+    ...(token ? { Authorization: `Bearer ${token}` } : {}),
+    ...options?.headers,
+  }
+
+  const response = await fetch(`${API_BASE}${endpoint}`, {
+    ...options,
+    headers,
+  })
+
+  const data = await response.json()
+
+  if (!response.ok) {
+    throw new Error(data.error || 'Request failed')
+  }
+
+  return data as T
+}
+
+// Truck API: the vehicle and its equipment are owned by the server
+export const truckApi = {
+  // Current vehicle, the ladder and the next step
+  getTruck: (gameId: string) =>
+    request<TruckResponse>(`/games/${gameId}/trucks`),
+
+  // Move one step up the ladder (charged through the ledger)
+  upgradeTruck: (gameId: string) =>
+    request<TruckUpgradeResponse>(`/games/${gameId}/trucks/upgrade`, {
+      method: 'POST',
+    }),
+
+  // Upgrade and equipment shop
+  getUpgrades: (gameId: string) =>
+    request<UpgradesResponse>(`/games/${gameId}/upgrades`),
+
+  // Buy the next level of an upgrade or an equipment item
+  buyUpgrade: (gameId: string, upgradeId: string) =>
+    request<BuyUpgradeResponse>(`/games/${gameId}/upgrades/buy`, {
+      method: 'POST',
+      body: JSON.stringify({ upgrade_id: upgradeId }),
+    }),
+}